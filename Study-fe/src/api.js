@@ -30,8 +30,9 @@ api.interceptors.response.use(
       return Promise.reject(new Error(data.error))
     }
     return {
-      data: data.problems || data.stats || data,
+      data: data.questions || data.problems || data.stats || data,
       total: data.total,
+      nextCursor: data.next_cursor,
       page: data.page,
       limit: data.limit
     }
@@ -44,8 +45,20 @@ api.interceptors.response.use(
 
 // Question API
 export const questionAPI = {
-  // 获取所有问题
-  getAll: () => api.get('/question/'),
+  // 获取所有问题（按游标逐页拉取）
+  getAll: async () => {
+    const questions = []
+    let cursor
+    do {
+      const response = await api.get('/question/', { params: { limit: 200, cursor } })
+      questions.push(...(response.data || []))
+      cursor = response.nextCursor || undefined
+    } while (cursor)
+    return { data: questions, total: questions.length }
+  },
+
+  // 分页查询问题，参数：category、mastery、updated_since、q、sort、order、cursor、limit
+  list: (params) => api.get('/question/', { params }),
   
   // 获取所有分类
   getCategories: () => api.get('/question/categories'),
@@ -80,14 +93,39 @@ export const codingProblemAPI = {
   getRandom: () => api.get('/api/coding/random'),
   getStats: () => api.get('/api/coding/stats'),
   
-  // 学习状态管理
-  updateStudyStatus: (problemId, status) => api.put(`/api/coding/problems/${problemId}/study-status`, { study_status: status }),
+  // 做题记录，学习状态由最近一次做题结果决定
+  getAttempts: (problemId) => api.get(`/api/coding/problems/${problemId}/attempts`),
+  addAttempt: (problemId, attempt) => api.post(`/api/coding/problems/${problemId}/attempts`, attempt),
+  // 到期需要重做的题目
+  getDue: (limit) => api.get('/api/coding/review/due', { params: { limit } }),
+
+  // 题单
+  getLists: () => api.get('/api/coding/lists'),
+  getList: (id) => api.get(`/api/coding/lists/${id}`),
+  getListProgress: (id) => api.get(`/api/coding/lists/${id}/progress`),
+  createList: (list) => api.post('/api/coding/lists', list),
+  updateList: (id, list) => api.put(`/api/coding/lists/${id}`, list),
+  deleteList: (id) => api.delete(`/api/coding/lists/${id}`),
+  setListProblems: (id, problemIds) => api.put(`/api/coding/lists/${id}/problems`, { problem_ids: problemIds }),
+  addListProblems: (id, problemIds) => api.post(`/api/coding/lists/${id}/problems`, { problem_ids: problemIds }),
+  removeListProblem: (id, problemId) => api.delete(`/api/coding/lists/${id}/problems/${problemId}`),
+
+  // 题库同步，source 为 leetcode 或 leetcode_us
+  startSync: (source) => api.post(`/api/coding/sync/${source}`),
+  getSyncProgress: (source) => api.get(`/api/coding/sync/${source}`),
   
   // 管理功能
   refreshCache: () => api.post('/api/coding/refresh'),
   importProblems: (problems) => api.post('/api/coding/import', problems)
 }
 
+// 后台任务：daily_problem、trash_purge、problem_enrich
+export const jobAPI = {
+  list: () => api.get('/admin/jobs'),
+  getRuns: (name, limit) => api.get(`/admin/jobs/${name}/runs`, { params: { limit } }),
+  trigger: (name) => api.post(`/admin/jobs/${name}/run`)
+}
+
 // 移除了所有刷题记录相关API，现在专注于题目展示和跳转LeetCode
 
 
@@ -17,6 +17,9 @@ type Hot100Problem struct {
 	Difficulty string
 }
 
+// hot100Source Hot100 题目的来源，题号为 leetcode.cn 的题号
+const hot100Source = "leetcode"
+
 // Hot100Problems Hot100题目列表
 var Hot100Problems = []Hot100Problem{
 	{"1", "两数之和", "two-sum", "Easy"},
@@ -132,9 +135,9 @@ func InsertHot100Problems(ctx context.Context, app *ioc.Application) {
 	skipCount := 0
 
 	for i, hotProblem := range Hot100Problems {
-		// 检查是否已存在
-		existing, err := app.CodingProblemRepo.FindBySourceId(ctx, hotProblem.ID)
-		if err == nil && len(existing) > 0 {
+		// 检查是否已存在，已移入回收站的题目不再重新插入
+		exists, err := app.CodingProblemRepo.ExistsBySourceId(ctx, hot100Source, hotProblem.ID)
+		if err == nil && exists {
 			skipCount++
 			continue
 		}
@@ -144,11 +147,11 @@ func InsertHot100Problems(ctx context.Context, app *ioc.Application) {
 			Title:       hotProblem.Title,
 			Difficulty:  hotProblem.Difficulty,
 			Tags:        []string{"Hot 100", "算法"},
-			Source:      "leetcode",
+			Source:      hot100Source,
 			SourceId:    hotProblem.ID,
 			SourceUrl:   fmt.Sprintf("https://leetcode.cn/problems/%s/", hotProblem.TitleSlug),
+			TitleSlug:   hotProblem.TitleSlug,
 			StudyStatus: "not_started",
-			IsHot100:    true,
 			Ctime:       time.Now(),
 			Utime:       time.Now(),
 		}
@@ -168,4 +171,26 @@ func InsertHot100Problems(ctx context.Context, app *ioc.Application) {
 	}
 
 	log.Printf("✅ Hot100题目插入完成! 成功插入: %d, 跳过(已存在): %d", successCount, skipCount)
+
+	addHot100ToList(ctx, app)
+}
+
+// addHot100ToList 按 Hot100Problems 的顺序把题目加入内置的 Hot 100 题单，已在题单中的题目保持不变
+func addHot100ToList(ctx context.Context, app *ioc.Application) {
+	list, err := app.ProblemListRepo.FindByName(ctx, domain.Hot100ListName)
+	if err != nil {
+		log.Printf("查询 Hot 100 题单失败: %v", err)
+		return
+	}
+	ids := make([]int64, 0, len(Hot100Problems))
+	for _, hotProblem := range Hot100Problems {
+		problems, err := app.CodingProblemRepo.FindBySourceId(ctx, hot100Source, hotProblem.ID)
+		if err != nil || len(problems) == 0 {
+			continue
+		}
+		ids = append(ids, problems[0].Id)
+	}
+	if err := app.ProblemListRepo.AddProblems(ctx, list.Id, ids); err != nil {
+		log.Printf("加入 Hot 100 题单失败: %v", err)
+	}
 }
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const civilDateLayout = "2006-01-02"
+
+// CivilDate 不带时区的日历日期，例如每日一题的日期。JSON 和数据库中都是 2006-01-02 格式，
+// 数据库列类型为 DATE，读写时不受数据库连接时区的影响
+type CivilDate struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// CivilDateOf t 在其自身时区中的日期
+func CivilDateOf(t time.Time) CivilDate {
+	y, m, d := t.Date()
+	return CivilDate{Year: y, Month: m, Day: d}
+}
+
+func ParseCivilDate(s string) (CivilDate, error) {
+	t, err := time.Parse(civilDateLayout, s)
+	if err != nil {
+		return CivilDate{}, err
+	}
+	return CivilDateOf(t), nil
+}
+
+func (d CivilDate) IsZero() bool {
+	return d == CivilDate{}
+}
+
+func (d CivilDate) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// In 这一天在 loc 中的零点
+func (d CivilDate) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+func (d CivilDate) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+func (d *CivilDate) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*d = CivilDate{}
+		return nil
+	}
+	date, err := ParseCivilDate(s)
+	if err != nil {
+		return err
+	}
+	*d = date
+	return nil
+}
+
+func (d CivilDate) Value() (driver.Value, error) {
+	if d.IsZero() {
+		return nil, nil
+	}
+	return d.String(), nil
+}
+
+// Scan 连接参数 parseTime=True 时 DATE 列读出的是连接时区中零点的 time.Time，否则是字符串
+func (d *CivilDate) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*d = CivilDate{}
+		return nil
+	case time.Time:
+		*d = CivilDateOf(v)
+		return nil
+	case []byte:
+		return d.parse(string(v))
+	case string:
+		return d.parse(v)
+	default:
+		return fmt.Errorf("无法把 %T 转换为日期", value)
+	}
+}
+
+func (d *CivilDate) parse(s string) error {
+	// DATETIME 列也可以读取，只取日期部分
+	if len(s) > len(civilDateLayout) {
+		s = s[:len(civilDateLayout)]
+	}
+	date, err := ParseCivilDate(s)
+	if err != nil {
+		return err
+	}
+	*d = date
+	return nil
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// 提交结果
+const (
+	AttemptAccepted = "accepted"
+	AttemptWrong    = "wrong"
+	AttemptTimeout  = "timeout"
+	AttemptGaveUp   = "gave_up"
+)
+
+// 学习状态
+const (
+	StudyNotStarted = "not_started"
+	StudyInProgress = "in_progress"
+	StudyCompleted  = "completed"
+)
+
+// CodingAttempt 一次做题记录
+type CodingAttempt struct {
+	Id        int64     `json:"id"`
+	ProblemId int64     `json:"problem_id"`
+	Language  string    `json:"language"`
+	Code      string    `json:"code"`
+	Result    string    `json:"result"`     // accepted, wrong, timeout, gave_up
+	TimeSpent int64     `json:"time_spent"` // 用时(秒)
+	Notes     string    `json:"notes"`
+	Ctime     time.Time `json:"ctime"`
+}
+
+// StudyStatus 由这次做题结果推导出的学习状态，通过即为已完成，否则为学习中
+func (a CodingAttempt) StudyStatus() string {
+	if a.Result == AttemptAccepted {
+		return StudyCompleted
+	}
+	return StudyInProgress
+}
@@ -6,32 +6,39 @@ import "time"
 type CodingProblem struct {
 	Id             int64      `json:"id"`
 	Title          string     `json:"title"`
-	Difficulty     string     `json:"difficulty"` // Easy, Medium, Hard
+	Description    string     `json:"description,omitempty"` // 题目描述(Markdown)，列表接口不返回
+	Difficulty     string     `json:"difficulty"`            // Easy, Medium, Hard
 	Tags           []string   `json:"tags"`
-	Source         string     `json:"source"`                 // leetcode, nowcoder
-	SourceId       string     `json:"source_id"`              // 原网站的问题ID
-	SourceUrl      string     `json:"source_url"`             // 原网站的链接
-	StudyStatus    string     `json:"study_status"`           // 学习状态: not_started, in_progress, completed
-	LastStudied    *time.Time `json:"last_studied,omitempty"` // 最后学习时间
-	IsDailyProblem bool       `json:"is_daily_problem"`       // 是否为每日一题
-	DailyDate      *time.Time `json:"daily_date,omitempty"`   // 每日一题的日期
-	IsHot100       bool       `json:"is_hot100"`              // 是否为 Hot 100 题目
+	Source         string     `json:"source"`                   // 题目来源: leetcode(CN), leetcode_us, nowcoder
+	SourceId       string     `json:"source_id"`                // 原网站的问题ID
+	SourceUrl      string     `json:"source_url"`               // 原网站的链接
+	TitleSlug      string     `json:"title_slug,omitempty"`     // LeetCode 题目 slug
+	PaidOnly       bool       `json:"paid_only"`                // 是否会员题
+	AcRate         float64    `json:"ac_rate"`                  // 通过率(百分比)，未知时为 0
+	StudyStatus    string     `json:"study_status"`             // 学习状态: not_started, in_progress, completed
+	LastStudied    *time.Time `json:"last_studied,omitempty"`   // 最后学习时间
+	IsDailyProblem bool       `json:"is_daily_problem"`         // 是否为每日一题
+	DailyDate      *CivilDate `json:"daily_date,omitempty"`     // 每日一题的日期，按来源站点的时区划分
+	ReviewStage    int        `json:"review_stage"`             // 连续做对的次数，决定下次重做的间隔
+	NextReviewAt   *time.Time `json:"next_review_at,omitempty"` // 下次重做时间，从没做对过时为空
 	Ctime          time.Time  `json:"ctime"`
 	Utime          time.Time  `json:"utime"`
 }
 
 // DailyProblem 每日一题记录
 type DailyProblem struct {
-	Id         int64     `json:"id"`
-	Date       time.Time `json:"date"`       // 每日一题的日期
-	Title      string    `json:"title"`      // 题目标题
-	Difficulty string    `json:"difficulty"` // 难度
-	Tags       []string  `json:"tags"`       // 标签
-	Source     string    `json:"source"`     // 来源
-	SourceId   string    `json:"source_id"`  // 原网站的问题ID
-	SourceUrl  string    `json:"source_url"` // 原网站的链接
-	Ctime      time.Time `json:"ctime"`      // 创建时间
-	Utime      time.Time `json:"utime"`      // 更新时间
+	Id          int64     `json:"id"`
+	Date        CivilDate `json:"date"`       // 每日一题的日期，按来源站点的时区划分
+	Title       string    `json:"title"`      // 题目标题
+	Description string    `json:"-"`          // 题目描述(Markdown)，保存到题目上
+	Difficulty  string    `json:"difficulty"` // 难度
+	Tags        []string  `json:"tags"`       // 标签
+	Source      string    `json:"source"`     // 来源
+	SourceId    string    `json:"source_id"`  // 原网站的问题ID
+	SourceUrl   string    `json:"source_url"` // 原网站的链接
+	TitleSlug   string    `json:"-"`          // 题目 slug，保存到题目上
+	Ctime       time.Time `json:"ctime"`      // 创建时间
+	Utime       time.Time `json:"utime"`      // 更新时间
 }
 
 // CodingProblemRequest 创建/更新刷题问题的请求
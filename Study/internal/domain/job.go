@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// 后台任务运行状态
+const (
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// 后台任务的触发方式
+const (
+	JobTriggerSchedule = "schedule" // 到达 cron 表达式指定的时间
+	JobTriggerRetry    = "retry"    // 失败后重试，下次定时运行前有效
+	JobTriggerCatchUp  = "catch_up" // 启动时发现错过了上一次定时运行
+	JobTriggerManual   = "manual"
+)
+
+// JobRun 后台任务的一次运行记录
+type JobRun struct {
+	Id         int64      `json:"id"`
+	Job        string     `json:"job"`
+	Trigger    string     `json:"trigger"`
+	Attempt    int        `json:"attempt"` // 同一次定时运行中的第几次尝试
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Job 后台任务及其最近一次运行
+type Job struct {
+	Name      string    `json:"name"`
+	Schedule  string    `json:"schedule"` // cron 表达式
+	Timezone  string    `json:"timezone"` // cron 表达式使用的时区
+	Running   bool      `json:"running"`
+	NextRunAt time.Time `json:"next_run_at"`
+	LastRun   *JobRun   `json:"last_run,omitempty"`
+}
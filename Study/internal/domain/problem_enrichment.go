@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// 题目补全状态
+const (
+	EnrichDone   = "done"
+	EnrichFailed = "failed" // 到 NextAttemptAt 后重试
+)
+
+// ProblemEnrichment 题目补全记录。预置的 Hot 100 和题库同步的题目只有基本信息，
+// 后台任务按 slug 抓取详情补全标签、描述和会员题标记，每道题一条记录
+type ProblemEnrichment struct {
+	ProblemId     int64      `json:"problem_id"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"` // 连续失败的次数，成功后清零
+	Error         string     `json:"error,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	Utime         time.Time  `json:"utime"`
+}
@@ -0,0 +1,66 @@
+package domain
+
+import "time"
+
+// Hot100ListName 内置 Hot 100 题单的名称
+const Hot100ListName = "Hot 100"
+
+// ProblemList 题单，题目按 Position 排序
+type ProblemList struct {
+	Id           int64     `json:"id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	BuiltIn      bool      `json:"built_in"`              // 内置题单不能删除和改名
+	Source       string    `json:"source,omitempty"`      // 导入来源，例如 leetcode_study_plan
+	SourceSlug   string    `json:"source_slug,omitempty"` // 来源中的题单标识
+	ProblemCount int64     `json:"problem_count"`         // 题单中的题目数，不含回收站中的题目
+	Ctime        time.Time `json:"ctime"`
+	Utime        time.Time `json:"utime"`
+}
+
+// ProblemListDetail 题单及其中的题目
+type ProblemListDetail struct {
+	ProblemList
+	Problems []CodingProblem `json:"problems"`
+}
+
+// ProblemListImport 导入外部题单的结果
+type ProblemListImport struct {
+	List    ProblemList `json:"list"`
+	Total   int         `json:"total"`   // 外部题单中的题目数
+	Created int         `json:"created"` // 新增的题目数
+	Updated int         `json:"updated"` // 已存在并更新元数据的题目数
+	// Skipped 没有加入题单的题目及原因，例如在回收站中
+	Skipped []ProblemListImportSkip `json:"skipped"`
+}
+
+// 外部题单导入任务的状态
+const (
+	ListImportRunning = "running"
+	ListImportDone    = "done"
+	ListImportFailed  = "failed"
+)
+
+// ProblemListImportTask 在后台导入外部题单的任务，同一个题单同时只有一个任务在运行
+type ProblemListImportTask struct {
+	Type       string             `json:"type"`
+	Slug       string             `json:"slug"`
+	Status     string             `json:"status"`
+	Result     *ProblemListImport `json:"result,omitempty"` // 导入完成后才有
+	Error      string             `json:"error,omitempty"`
+	StartedAt  time.Time          `json:"started_at"`
+	FinishedAt *time.Time         `json:"finished_at,omitempty"`
+}
+
+type ProblemListImportSkip struct {
+	TitleSlug string `json:"title_slug"`
+	Reason    string `json:"reason"`
+}
+
+// ProblemListProgress 题单进度，按学习状态和难度分别计数
+type ProblemListProgress struct {
+	ListId       int64            `json:"list_id"`
+	Total        int64            `json:"total"`
+	ByStatus     map[string]int64 `json:"by_status"`
+	ByDifficulty map[string]int64 `json:"by_difficulty"`
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// 题库同步状态
+const (
+	SyncIdle    = "idle"    // 从未同步
+	SyncRunning = "running" // 同步中，服务重启后会从 Offset 继续
+	SyncDone    = "done"
+	SyncFailed  = "failed" // 出错停止，再次启动时从 Offset 继续
+)
+
+// ProblemSync 题库同步进度，每个题目来源一条，按页保存，Offset 之前的题目都已同步
+type ProblemSync struct {
+	Source     string     `json:"source"`
+	Status     string     `json:"status"`
+	Offset     int        `json:"offset"` // 已处理的题目数，也是下一页的起点
+	Total      int        `json:"total"`  // 题库中的题目总数，开始同步后才知道
+	Created    int        `json:"created"`
+	Updated    int        `json:"updated"`
+	Skipped    int        `json:"skipped"` // 回收站中的题目不会被同步
+	Error      string     `json:"error,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Utime      time.Time  `json:"utime"`
+}
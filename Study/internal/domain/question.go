@@ -2,12 +2,187 @@ package domain
 
 import "time"
 
+// 掌握度
+const (
+	MasteryUnlearned = 0 // 未学习
+	MasteryLearning  = 1 // 学习中
+	MasteryMastered  = 2 // 已掌握
+)
+
+// DefaultEaseFactor SM-2 初始难度因子
+const DefaultEaseFactor = 2.5
+
 type Question struct {
 	Id           int64     `json:"id"`
 	Category     string    `json:"category"`
+	Tags         []string  `json:"tags"` // 标签路径，包含分类本身
 	Content      string    `json:"content"`
 	Answer       string    `json:"answer"`
-	MasteryLevel int       `json:"mastery_level"` // 0: 未学习, 1: 学习中, 2: 已掌握
+	MasteryLevel int       `json:"mastery_level"`  // 0: 未学习, 1: 学习中, 2: 已掌握
+	EaseFactor   float64   `json:"ease_factor"`    // SM-2 难度因子
+	Interval     int       `json:"interval"`       // 复习间隔(天)
+	Repetitions  int       `json:"repetitions"`    // 连续答对次数
+	NextReviewAt time.Time `json:"next_review_at"` // 下次复习时间
+	ReviewCount  int64     `json:"review_count"`   // 累计复习次数
 	Ctime        time.Time `json:"ctime"`
 	Utime        time.Time `json:"utime"`
 }
+
+// 复习事件类型
+const (
+	QuestionReviewGrade   = "grade"   // SM-2 评分
+	QuestionReviewMastery = "mastery" // 手动修改掌握度
+)
+
+// QuestionReview 题目的一次复习事件
+type QuestionReview struct {
+	Id            int64     `json:"id"`
+	QuestionId    int64     `json:"question_id"`
+	Kind          string    `json:"kind"` // grade, mastery
+	Grade         *int      `json:"grade,omitempty"`
+	MasteryBefore int       `json:"mastery_before"`
+	MasteryAfter  int       `json:"mastery_after"`
+	DurationMs    int64     `json:"duration_ms"`
+	Ctime         time.Time `json:"ctime"`
+}
+
+// QuestionActivity 复习动态，附带题目信息
+type QuestionActivity struct {
+	QuestionReview
+	Category string `json:"category"`
+	Content  string `json:"content"`
+}
+
+// QuestionSearchResult 检索结果，Snippet 中命中的关键词用 <mark> 标记
+type QuestionSearchResult struct {
+	Question Question `json:"question"`
+	Score    float64  `json:"score"`
+	Snippet  string   `json:"snippet"`
+}
+
+// 批量导入单条结果状态
+const (
+	ImportCreated = "created"
+	ImportUpdated = "updated"
+	ImportSkipped = "skipped"
+	ImportTrashed = "trashed" // 相同的题目在回收站中，需要先恢复
+	ImportError   = "error"
+)
+
+// QuestionImportResult 批量导入中单条题目的结果，Index 对应请求中的下标
+type QuestionImportResult struct {
+	Index  int    `json:"index"`
+	Id     int64  `json:"id,omitempty"`
+	Status string `json:"status"` // created, updated, skipped, trashed, error
+	Error  string `json:"error,omitempty"`
+	// Warning 与已有题目相似时的提示，SimilarTo 为最相似的题目ID
+	Warning   string `json:"warning,omitempty"`
+	SimilarTo int64  `json:"similar_to,omitempty"`
+	Content   string `json:"content"`
+}
+
+// QuestionImportReport 批量导入报告
+type QuestionImportReport struct {
+	DryRun  bool                   `json:"dry_run"`
+	Total   int                    `json:"total"`
+	Created int                    `json:"created"`
+	Updated int                    `json:"updated"`
+	Skipped int                    `json:"skipped"`
+	Trashed int                    `json:"trashed"`
+	Failed  int                    `json:"failed"`
+	Items   []QuestionImportResult `json:"items"`
+}
+
+// Tag 题目标签，支持用 / 分隔的层级，例如 网络/TCP
+type Tag struct {
+	Id       int64  `json:"id"`
+	Name     string `json:"name"`
+	ParentId int64  `json:"parent_id"`
+	Path     string `json:"path"`
+	// QuestionCount 该标签及其子标签下的题目关联数
+	QuestionCount int64 `json:"question_count"`
+	Children      []Tag `json:"children"`
+}
+
+// QuestionRevision 题目的一个历史版本
+type QuestionRevision struct {
+	QuestionId   int64     `json:"question_id"`
+	Rev          int       `json:"rev"`
+	Category     string    `json:"category"`
+	Content      string    `json:"content"`
+	Answer       string    `json:"answer"`
+	Author       string    `json:"author"`
+	RestoredFrom int       `json:"restored_from"`
+	Ctime        time.Time `json:"ctime"`
+}
+
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// DiffLine 逐行对比中的一行
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// QuestionRevisionDiff 两个版本之间的逐行对比
+type QuestionRevisionDiff struct {
+	From     int        `json:"from"`
+	To       int        `json:"to"`
+	Category []DiffLine `json:"category"`
+	Content  []DiffLine `json:"content"`
+	Answer   []DiffLine `json:"answer"`
+}
+
+// QuestionFingerprint 题目内容的 SimHash 指纹
+type QuestionFingerprint struct {
+	Id      int64
+	Simhash uint64
+}
+
+// SimilarQuestion 相似题目，Similarity 为内容相似度，取值 0 到 1
+type SimilarQuestion struct {
+	Question
+	Similarity float64 `json:"similarity"`
+}
+
+// DuplicateCluster 一组互相近似的题目
+type DuplicateCluster struct {
+	Questions []Question `json:"questions"`
+	// MinSimilarity 组内连接各题目的相似度中的最小值
+	MinSimilarity float64 `json:"min_similarity"`
+}
+
+// QuestionQuery 题目列表的筛选、排序和分页条件
+type QuestionQuery struct {
+	Category     string
+	MasteryLevel *int
+	UpdatedSince time.Time
+	// Keyword 在题目内容和答案中模糊匹配
+	Keyword string
+	// SortBy 排序字段：id, ctime, utime, mastery_level, next_review_at, review_count
+	SortBy string
+	Desc   bool
+	// After 上一页的游标，为 nil 时从第一页开始
+	After *QuestionCursor
+	Limit int
+}
+
+// QuestionCursor 分页游标，记录上一页最后一条题目的排序值和ID
+type QuestionCursor struct {
+	SortBy string `json:"s"`
+	Desc   bool   `json:"d,omitempty"`
+	Value  int64  `json:"v"`
+	Id     int64  `json:"i"`
+}
+
+// QuestionPage 一页题目，NextCursor 为空表示没有下一页
+type QuestionPage struct {
+	Questions  []Question      `json:"questions"`
+	NextCursor string          `json:"next_cursor"`
+	Total      int64           `json:"total"`
+	Next       *QuestionCursor `json:"-"`
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// 复习会话状态
+const (
+	ReviewSessionActive   = "active"
+	ReviewSessionFinished = "finished"
+)
+
+// ReviewSession 一次八股复习会话
+type ReviewSession struct {
+	Id         int64               `json:"id"`
+	Category   string              `json:"category"` // 为空表示全部分类
+	Size       int                 `json:"size"`
+	Status     string              `json:"status"` // active, finished
+	Items      []ReviewSessionItem `json:"items"`
+	StartedAt  time.Time           `json:"started_at"`
+	FinishedAt *time.Time          `json:"finished_at,omitempty"`
+}
+
+// ReviewSessionItem 会话中的一道题
+type ReviewSessionItem struct {
+	Position      int        `json:"position"`
+	QuestionId    int64      `json:"question_id"`
+	Grade         *int       `json:"grade,omitempty"` // 未作答时为空
+	MasteryBefore int        `json:"mastery_before"`
+	MasteryAfter  int        `json:"mastery_after"`
+	DurationMs    int64      `json:"duration_ms"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+	// Skipped 题目已被删除，会话跳过了这道题
+	Skipped bool `json:"skipped,omitempty"`
+}
+
+// ReviewSessionSummary 会话总结
+type ReviewSessionSummary struct {
+	SessionId      int64           `json:"session_id"`
+	Status         string          `json:"status"`
+	Total          int             `json:"total"`
+	Reviewed       int             `json:"reviewed"`
+	Skipped        int             `json:"skipped"`
+	Correct        int             `json:"correct"`
+	DurationMs     int64           `json:"duration_ms"`
+	MasteryChanges []MasteryChange `json:"mastery_changes"`
+}
+
+// MasteryChange 单题掌握度变化
+type MasteryChange struct {
+	QuestionId int64 `json:"question_id"`
+	Before     int   `json:"before"`
+	After      int   `json:"after"`
+}
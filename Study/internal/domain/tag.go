@@ -0,0 +1,18 @@
+package domain
+
+import "strings"
+
+// TagPathSeparator 层级标签路径分隔符，例如 网络/TCP，题目分类也使用同样的路径
+const TagPathSeparator = "/"
+
+// NormalizeTagPath 去掉每一级首尾空白和空的层级，例如 " 网络 / TCP/ " => "网络/TCP"
+func NormalizeTagPath(path string) string {
+	parts := strings.Split(path, TagPathSeparator)
+	res := parts[:0]
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			res = append(res, part)
+		}
+	}
+	return strings.Join(res, TagPathSeparator)
+}
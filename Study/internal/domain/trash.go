@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+const (
+	TrashQuestion      = "question"
+	TrashCodingProblem = "coding_problem"
+)
+
+// TrashItem 回收站中的一项，Category 对八股题是分类，对刷题是难度
+type TrashItem struct {
+	Type      string    `json:"type"`
+	Id        int64     `json:"id"`
+	Title     string    `json:"title"`
+	Category  string    `json:"category"`
+	DeletedAt time.Time `json:"deleted_at"`
+	// PurgeAt 超过保留期后将被彻底删除的时间
+	PurgeAt time.Time `json:"purge_at"`
+}
@@ -0,0 +1,177 @@
+// Package crawlhttp 爬虫共用的 HTTP 客户端：令牌桶限速，遇到 429、5xx 和网络错误时
+// 按带抖动的指数退避重试，连续失败过多时熔断一段时间，期间的请求直接失败。
+// 为了避免日志里出现大段题目内容，客户端不会记录请求和响应的内容。
+package crawlhttp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrCircuitOpen 熔断期间的请求返回该错误
+var ErrCircuitOpen = errors.New("连续请求失败，暂停爬取")
+
+// StatusError 响应状态码不是 2xx
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("请求失败，状态码: %d", e.StatusCode)
+}
+
+// Config 客户端配置
+type Config struct {
+	Timeout time.Duration // 单次请求超时
+	Rate    float64       // 每秒允许的请求数
+	Burst   int           // 令牌桶容量
+	// MaxRetries 最多重试次数，不含第一次请求
+	MaxRetries int
+	BaseDelay  time.Duration // 第一次重试前的等待时间，之后每次翻倍
+	MaxDelay   time.Duration // 单次等待的上限
+	// FailureThreshold 连续失败多少次后熔断
+	FailureThreshold int
+	Cooldown         time.Duration // 熔断时长
+	Logger           *log.Logger
+}
+
+// DefaultConfig 爬取 LeetCode 使用的默认配置
+func DefaultConfig() Config {
+	return Config{
+		Timeout:          30 * time.Second,
+		Rate:             1,
+		Burst:            3,
+		MaxRetries:       3,
+		BaseDelay:        500 * time.Millisecond,
+		MaxDelay:         10 * time.Second,
+		FailureThreshold: 5,
+		Cooldown:         time.Minute,
+	}
+}
+
+// Client 并发安全，同一个站点的请求应共用一个 Client，限速和熔断才有意义
+type Client struct {
+	http    *http.Client
+	cfg     Config
+	limiter *tokenBucket
+	breaker *breaker
+	logger  *log.Logger
+}
+
+func New(cfg Config) *Client {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &Client{
+		http:    &http.Client{Timeout: cfg.Timeout},
+		cfg:     cfg,
+		limiter: newTokenBucket(cfg.Rate, cfg.Burst),
+		breaker: newBreaker(cfg.FailureThreshold, cfg.Cooldown),
+		logger:  logger,
+	}
+}
+
+// Post 发送 POST 请求，返回 2xx 响应的内容；失败时按配置重试
+func (c *Client) Post(ctx context.Context, url string, header http.Header, body []byte) ([]byte, error) {
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		if err := c.breaker.allow(); err != nil {
+			return nil, err
+		}
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		respBody, retryAfter, err := c.do(ctx, url, header, body)
+		if err == nil {
+			c.breaker.success()
+			return respBody, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !retryable(err) {
+			return nil, err
+		}
+		c.breaker.failure()
+		lastErr = err
+		if attempt >= c.cfg.MaxRetries {
+			break
+		}
+
+		delay := c.backoff(attempt)
+		if retryAfter > delay {
+			delay = min(retryAfter, c.cfg.MaxDelay)
+		}
+		c.logger.Printf("请求 %s 失败: %v，%v 后重试(%d/%d)", url, err, delay, attempt+1, c.cfg.MaxRetries)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+	return nil, fmt.Errorf("重试 %d 次后仍然失败: %w", c.cfg.MaxRetries, lastErr)
+}
+
+// do 发送一次请求，429 时同时返回 Retry-After 要求的等待时间
+func (c *Client) do(ctx context.Context, url string, header http.Header, body []byte) ([]byte, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, fmt.Errorf("创建请求失败: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("读取响应失败: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, retryAfter(resp.Header.Get("Retry-After")), &StatusError{StatusCode: resp.StatusCode}
+	}
+	return respBody, 0, nil
+}
+
+// retryable 429、5xx 以及超时、连接失败等网络错误可以重试
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	return true
+}
+
+// backoff 第 attempt 次重试前的等待时间：指数增长的一半固定，另一半随机，避免多个请求同时重试
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.cfg.BaseDelay << attempt
+	if delay <= 0 || delay > c.cfg.MaxDelay {
+		delay = c.cfg.MaxDelay
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// retryAfter 解析以秒为单位的 Retry-After
+func retryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
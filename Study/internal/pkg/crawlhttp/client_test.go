@@ -0,0 +1,219 @@
+package crawlhttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testConfig 不限速、重试等待很短的配置
+func testConfig() Config {
+	return Config{
+		Timeout:    time.Second,
+		MaxRetries: 3,
+		BaseDelay:  time.Millisecond,
+		MaxDelay:   2 * time.Second,
+		Logger:     log.New(io.Discard, "", 0),
+	}
+}
+
+// statusServer 按顺序返回 statuses 中的状态码，之后一直返回 200，hits 为收到的请求数
+func statusServer(t *testing.T, header http.Header, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(hits.Add(1))
+		if n <= len(statuses) {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func TestPostRetriesRetryableStatus(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway} {
+		srv, hits := statusServer(t, nil, status, status)
+		body, err := New(testConfig()).Post(context.Background(), srv.URL, nil, nil)
+		if err != nil {
+			t.Fatalf("status %d: %v", status, err)
+		}
+		if string(body) != "ok" || hits.Load() != 3 {
+			t.Errorf("status %d: body = %q, hits = %d, want ok after 3 requests", status, body, hits.Load())
+		}
+	}
+}
+
+func TestPostGivesUpAfterMaxRetries(t *testing.T) {
+	srv, hits := statusServer(t, nil, 503, 503, 503, 503, 503)
+	_, err := New(testConfig()).Post(context.Background(), srv.URL, nil, nil)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != 503 {
+		t.Fatalf("err = %v, want status 503", err)
+	}
+	if hits.Load() != 4 {
+		t.Errorf("hits = %d, want 4", hits.Load())
+	}
+}
+
+func TestPostHonoursRetryAfter(t *testing.T) {
+	srv, hits := statusServer(t, http.Header{"Retry-After": {"1"}}, http.StatusTooManyRequests)
+	start := time.Now()
+	if _, err := New(testConfig()).Post(context.Background(), srv.URL, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	// 退避时间只有几毫秒，等待 1 秒说明使用了 Retry-After
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, want at least 1s", elapsed)
+	}
+	if hits.Load() != 2 {
+		t.Errorf("hits = %d, want 2", hits.Load())
+	}
+}
+
+func TestPostRetryAfterCappedByMaxDelay(t *testing.T) {
+	srv, _ := statusServer(t, http.Header{"Retry-After": {"3600"}}, http.StatusTooManyRequests)
+	cfg := testConfig()
+	cfg.MaxDelay = 10 * time.Millisecond
+	start := time.Now()
+	if _, err := New(cfg).Post(context.Background(), srv.URL, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("retried after %v, want at most MaxDelay", elapsed)
+	}
+}
+
+func TestPostDoesNotRetryClientErrors(t *testing.T) {
+	for _, status := range []int{http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound} {
+		srv, hits := statusServer(t, nil, status)
+		cfg := testConfig()
+		cfg.FailureThreshold = 1
+		cfg.Cooldown = time.Hour
+		client := New(cfg)
+
+		_, err := client.Post(context.Background(), srv.URL, nil, nil)
+		var statusErr *StatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != status {
+			t.Fatalf("err = %v, want status %d", err, status)
+		}
+		if hits.Load() != 1 {
+			t.Errorf("status %d: hits = %d, want 1", status, hits.Load())
+		}
+		// 客户端错误不算站点故障，不会触发熔断
+		if _, err := client.Post(context.Background(), srv.URL, nil, nil); err != nil {
+			t.Errorf("status %d: breaker should stay closed: %v", status, err)
+		}
+	}
+}
+
+func TestPostSendsHeaderAndBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost || r.Header.Get("X-Test") != "1" || string(body) != "payload" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+	_, err := New(testConfig()).Post(context.Background(), srv.URL, http.Header{"X-Test": {"1"}}, []byte("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBreakerOpensAndRecovers(t *testing.T) {
+	srv, hits := statusServer(t, nil, 500, 500)
+	cfg := testConfig()
+	cfg.MaxRetries = 0
+	cfg.FailureThreshold = 2
+	cfg.Cooldown = 100 * time.Millisecond
+	client := New(cfg)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Post(context.Background(), srv.URL, nil, nil); err == nil {
+			t.Fatalf("request %d should fail", i+1)
+		}
+	}
+	// 熔断期间的请求不会发到服务端
+	if _, err := client.Post(context.Background(), srv.URL, nil, nil); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+	if hits.Load() != 2 {
+		t.Errorf("hits = %d, want 2", hits.Load())
+	}
+
+	time.Sleep(cfg.Cooldown + 20*time.Millisecond)
+	if _, err := client.Post(context.Background(), srv.URL, nil, nil); err != nil {
+		t.Fatalf("breaker should recover after cooldown: %v", err)
+	}
+	if hits.Load() != 3 {
+		t.Errorf("hits = %d, want 3", hits.Load())
+	}
+}
+
+func TestPostCancelledWhileWaitingForToken(t *testing.T) {
+	srv, hits := statusServer(t, nil)
+	cfg := testConfig()
+	cfg.Rate = 0.1
+	cfg.Burst = 1
+	client := New(cfg)
+	if _, err := client.Post(context.Background(), srv.URL, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := client.Post(ctx, srv.URL, nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("hits = %d, want 1", hits.Load())
+	}
+}
+
+func TestPostCancelledWhileBackingOff(t *testing.T) {
+	srv, hits := statusServer(t, http.Header{"Retry-After": {"10"}}, http.StatusTooManyRequests)
+	cfg := testConfig()
+	cfg.MaxDelay = time.Minute
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := New(cfg).Post(ctx, srv.URL, nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("cancelled backoff took %v", elapsed)
+	}
+	if hits.Load() != 1 {
+		t.Errorf("hits = %d, want 1", hits.Load())
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := map[string]time.Duration{
+		"":    0,
+		"3":   3 * time.Second,
+		"0":   0,
+		"-1":  0,
+		"abc": 0,
+		// HTTP 日期格式不支持，按指数退避等待
+		"Wed, 21 Oct 2015 07:28:00 GMT": 0,
+	}
+	for value, want := range tests {
+		if got := retryAfter(value); got != want {
+			t.Errorf("retryAfter(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package crawlhttp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBreakerReopensOnFailureAfterCooldown(t *testing.T) {
+	b := newBreaker(2, 50*time.Millisecond)
+	b.failure()
+	b.failure()
+	if !errors.Is(b.allow(), ErrCircuitOpen) {
+		t.Fatal("breaker should open after threshold")
+	}
+	time.Sleep(60 * time.Millisecond)
+	if err := b.allow(); err != nil {
+		t.Fatalf("breaker should allow after cooldown: %v", err)
+	}
+	// 冷却后的第一次请求失败时立即重新熔断
+	b.failure()
+	if !errors.Is(b.allow(), ErrCircuitOpen) {
+		t.Error("breaker should reopen on failure after cooldown")
+	}
+	// 成功一次后重新计数
+	time.Sleep(60 * time.Millisecond)
+	b.success()
+	b.failure()
+	if err := b.allow(); err != nil {
+		t.Errorf("breaker should stay closed after success: %v", err)
+	}
+}
+
+func TestTokenBucketWaits(t *testing.T) {
+	b := newTokenBucket(20, 2)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := b.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 前两个令牌立即可用，之后每 50ms 一个
+	elapsed := time.Since(start)
+	if elapsed < 90*time.Millisecond || elapsed > time.Second {
+		t.Errorf("4 tokens took %v, want about 100ms", elapsed)
+	}
+}
+
+func TestTokenBucketUnlimited(t *testing.T) {
+	b := newTokenBucket(0, 1)
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		if err := b.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("unlimited bucket took %v", elapsed)
+	}
+}
+
+func TestTokenBucketCancel(t *testing.T) {
+	b := newTokenBucket(1, 1)
+	if err := b.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := b.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("cancelled wait took %v", elapsed)
+	}
+
+	// 取消的请求归还预订的令牌，后面的请求不用多等一个令牌的时间
+	b.mu.Lock()
+	tokens := b.tokens
+	b.mu.Unlock()
+	if tokens < -0.1 {
+		t.Errorf("tokens = %v, want the cancelled reservation returned", tokens)
+	}
+}
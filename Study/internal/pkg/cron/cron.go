@@ -0,0 +1,181 @@
+// Package cron 解析标准的 5 段 cron 表达式：分 时 日 月 周，
+// 每段支持 *、数字、a-b 范围、逗号分隔的列表和 /n 步长，周日可以写 0 或 7。
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 解析后的 cron 表达式，按传入时间所在的时区计算
+type Schedule struct {
+	spec                          string
+	minute, hour, dom, month, dow uint64
+	// 日和周是否写了 *，用于判断两者的关系
+	domRestricted, dowRestricted bool
+}
+
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = []field{
+	{"分钟", 0, 59},
+	{"小时", 0, 23},
+	{"日", 1, 31},
+	{"月", 1, 12},
+	{"周", 0, 7},
+}
+
+// Parse 解析 cron 表达式
+func Parse(spec string) (Schedule, error) {
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return Schedule{}, fmt.Errorf("cron 表达式 %q 应为 5 段，实际为 %d 段", spec, len(parts))
+	}
+	bits := make([]uint64, len(fields))
+	for i, part := range parts {
+		b, err := parseField(part, fields[i])
+		if err != nil {
+			return Schedule{}, fmt.Errorf("cron 表达式 %q: %w", spec, err)
+		}
+		bits[i] = b
+	}
+	// 周日可以写 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+	return Schedule{
+		spec:          spec,
+		minute:        bits[0],
+		hour:          bits[1],
+		dom:           bits[2],
+		month:         bits[3],
+		dow:           bits[4],
+		domRestricted: parts[2] != "*",
+		dowRestricted: parts[4] != "*",
+	}, nil
+}
+
+// MustParse 解析失败时 panic，用于固定的表达式
+func MustParse(spec string) Schedule {
+	s, err := Parse(spec)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func parseField(part string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(part, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%s的步长 %q 无效", f.name, stepPart)
+			}
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			a, b, _ := strings.Cut(rangePart, "-")
+			var err error
+			if lo, err = parseValue(a, f); err != nil {
+				return 0, err
+			}
+			if hi, err = parseValue(b, f); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("%s的范围 %q 无效", f.name, rangePart)
+			}
+		default:
+			n, err := parseValue(rangePart, f)
+			if err != nil {
+				return 0, err
+			}
+			lo = n
+			// 5/10 表示从 5 开始每 10 个
+			if !hasStep {
+				hi = n
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, f field) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("%s的值 %q 应在 %d-%d 之间", f.name, s, f.min, f.max)
+	}
+	return n, nil
+}
+
+func (s Schedule) String() string {
+	return s.spec
+}
+
+// Next 返回 t 之后第一个满足表达式的时间，精确到分钟；五年内都不满足时返回零值。
+// 按 t 所在时区的墙上时间匹配：夏令时跳过的时间顺延到跳过之后运行，重复的时间只运行一次
+func (s Schedule) Next(t time.Time) time.Time {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+	limit := wall.AddDate(5, 0, 0)
+	for {
+		wall = s.nextWall(wall, limit)
+		if wall.IsZero() {
+			return time.Time{}
+		}
+		res := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), 0, 0, t.Location())
+		if res.Hour() != wall.Hour() || res.Minute() != wall.Minute() {
+			// 这个墙上时间被夏令时跳过，按跳过之前的时区偏移换算，得到跳过之后的时刻
+			_, offset := res.Zone()
+			res = wall.Add(-time.Duration(offset) * time.Second).In(t.Location())
+		}
+		// 夏令时结束后重复的一小时中，墙上时间对应的是第一次经过的时刻，可能早于 t
+		if res.After(t) {
+			return res
+		}
+	}
+}
+
+// nextWall 在没有夏令时的 UTC 中查找 wall 之后、limit 之前第一个满足表达式的墙上时间
+func (s Schedule) nextWall(wall, limit time.Time) time.Time {
+	t := wall.Add(time.Minute)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches 日和周都有限制时满足其一即可，和标准 cron 一致
+func (s Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return dom || dow
+	}
+	return dom && dow
+}
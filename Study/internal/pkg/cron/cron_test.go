@@ -0,0 +1,172 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+const layout = "2006-01-02 15:04"
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		from string
+		want []string // 从 from 开始连续调用 Next 得到的时间
+	}{
+		{"每分钟", "* * * * *", "2024-03-01 10:00", []string{"2024-03-01 10:01", "2024-03-01 10:02"}},
+		{"步长", "*/10 * * * *", "2024-03-01 10:05", []string{"2024-03-01 10:10", "2024-03-01 10:20", "2024-03-01 10:30"}},
+		{"从 5 开始的步长", "5/10 * * * *", "2024-03-01 10:00", []string{"2024-03-01 10:05", "2024-03-01 10:15", "2024-03-01 10:25"}},
+		{"步长跨小时", "5/20 * * * *", "2024-03-01 10:45", []string{"2024-03-01 11:05", "2024-03-01 11:25"}},
+		{"范围", "0 9-11 * * *", "2024-03-01 10:30", []string{"2024-03-01 11:00", "2024-03-02 09:00", "2024-03-02 10:00"}},
+		{"带步长的范围", "0 8-18/5 * * *", "2024-03-01 00:00", []string{"2024-03-01 08:00", "2024-03-01 13:00", "2024-03-01 18:00", "2024-03-02 08:00"}},
+		{"列表", "0 0 1,15 * *", "2024-03-01 00:00", []string{"2024-03-15 00:00", "2024-04-01 00:00"}},
+		// 2024-03-03 是周日
+		{"周日写作 0", "0 0 * * 0", "2024-03-01 00:00", []string{"2024-03-03 00:00", "2024-03-10 00:00"}},
+		{"周日写作 7", "0 0 * * 7", "2024-03-01 00:00", []string{"2024-03-03 00:00", "2024-03-10 00:00"}},
+		{"周范围包含 7", "0 0 * * 5-7", "2024-03-01 00:00", []string{"2024-03-02 00:00", "2024-03-03 00:00", "2024-03-08 00:00"}},
+		// 日和周都有限制时满足其一即可：每月 10 日或者每个周一
+		{"日和周取并集", "0 0 10 * 1", "2024-03-01 00:00", []string{"2024-03-04 00:00", "2024-03-10 00:00", "2024-03-11 00:00", "2024-03-18 00:00"}},
+		// 只限制其中一个时另一个不起作用
+		{"只限制日", "0 0 10 * *", "2024-03-01 00:00", []string{"2024-03-10 00:00", "2024-04-10 00:00"}},
+		{"只限制周", "0 0 * * 1", "2024-03-01 00:00", []string{"2024-03-04 00:00", "2024-03-11 00:00"}},
+		{"跨月", "0 0 1 * *", "2024-01-31 23:59", []string{"2024-02-01 00:00", "2024-03-01 00:00"}},
+		{"跨年", "30 23 31 12 *", "2024-12-31 23:30", []string{"2025-12-31 23:30"}},
+		{"跳过没有 31 日的月份", "0 0 31 * *", "2024-03-31 00:00", []string{"2024-05-31 00:00", "2024-07-31 00:00"}},
+		{"闰年的 2 月 29 日", "0 12 29 2 *", "2024-03-01 00:00", []string{"2028-02-29 12:00"}},
+		{"五年内都不满足", "0 0 30 2 *", "2024-01-01 00:00", []string{""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			from := mustTime(t, time.UTC, tt.from)
+			for _, want := range tt.want {
+				got := s.Next(from)
+				if want == "" {
+					if !got.IsZero() {
+						t.Fatalf("Next(%v) = %v, want zero", from, got)
+					}
+					return
+				}
+				if w := mustTime(t, time.UTC, want); !got.Equal(w) {
+					t.Fatalf("Next(%v) = %v, want %v", from, got, w)
+				}
+				from = got
+			}
+		})
+	}
+}
+
+func TestNextKeepsLocation(t *testing.T) {
+	loc := time.FixedZone("UTC+8", 8*3600)
+	s := MustParse("1 0 * * *")
+	// UTC 15:30 是 UTC+8 的 23:30
+	got := s.Next(mustTime(t, time.UTC, "2024-03-01 15:30").In(loc))
+	if want := mustTime(t, loc, "2024-03-02 00:01"); !got.Equal(want) || got.Location() != loc {
+		t.Errorf("Next = %v, want %v", got, want)
+	}
+}
+
+func TestNextDaylightSaving(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("时区数据不可用: ", err)
+	}
+	// 2024-03-10 2:00 跳到 3:00，2024-11-03 2:00 回到 1:00
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want []time.Time
+	}{
+		{
+			name: "跳过的时间顺延到跳过之后",
+			spec: "30 2 * * *",
+			from: mustTime(t, loc, "2024-03-09 03:00"),
+			want: []time.Time{
+				mustTime(t, loc, "2024-03-10 03:30"),
+				mustTime(t, loc, "2024-03-11 02:30"),
+			},
+		},
+		{
+			name: "每小时的任务在跳过的一小时不重复运行",
+			spec: "0 * * * *",
+			from: mustTime(t, loc, "2024-03-10 00:30"),
+			want: []time.Time{
+				mustTime(t, loc, "2024-03-10 01:00"),
+				mustTime(t, loc, "2024-03-10 03:00"),
+				mustTime(t, loc, "2024-03-10 04:00"),
+			},
+		},
+		{
+			name: "重复的时间只运行一次",
+			spec: "30 1 * * *",
+			from: mustTime(t, loc, "2024-11-02 12:00"),
+			want: []time.Time{
+				time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC), // 第一次经过的 1:30，UTC-4
+				mustTime(t, loc, "2024-11-04 01:30"),
+			},
+		},
+		{
+			name: "重复的一小时之后继续",
+			spec: "0 2 * * *",
+			// 第二次经过的 1:10，UTC-5
+			from: time.Date(2024, 11, 3, 6, 10, 0, 0, time.UTC).In(loc),
+			want: []time.Time{
+				time.Date(2024, 11, 3, 7, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := MustParse(tt.spec)
+			from := tt.from
+			for _, want := range tt.want {
+				got := s.Next(from)
+				if !got.Equal(want) {
+					t.Fatalf("Next(%v) = %v, want %v", from, got, want.In(loc))
+				}
+				from = got
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalid(t *testing.T) {
+	specs := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"-1 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 0 *",
+		"* * * 13 *",
+		"* * * * 8",
+		"a * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"1-60 * * * *",
+		"1,,2 * * * *",
+	}
+	for _, spec := range specs {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) should fail", spec)
+		}
+	}
+}
+
+func mustTime(t *testing.T, loc *time.Location, value string) time.Time {
+	t.Helper()
+	res, err := time.ParseInLocation(layout, value, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
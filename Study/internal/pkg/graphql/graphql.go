@@ -0,0 +1,142 @@
+// Package graphql 简单的 GraphQL 客户端：按操作名发送带 variables 的查询，
+// 把响应中的 data 解码到调用方给定的结构体，errors 转换为可以用 errors.Is 判断的错误。
+package graphql
+
+import (
+	"Training/Study/internal/pkg/crawlhttp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// 可以用 errors.Is 判断的错误类型，来自响应中的 errors 或 HTTP 状态码
+var (
+	ErrNotFound     = errors.New("请求的内容不存在")
+	ErrRateLimited  = errors.New("请求过于频繁")
+	ErrAuthRequired = errors.New("需要登录")
+)
+
+// Operation 命名的 GraphQL 操作，Name 必须和 Query 中的操作名一致
+type Operation struct {
+	Name  string
+	Query string
+}
+
+// Error 响应 errors 中的一项
+type Error struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// ResponseError 响应中带有 errors
+type ResponseError struct {
+	Operation string
+	Errors    []Error
+}
+
+func (e *ResponseError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Message)
+	}
+	return fmt.Sprintf("GraphQL %s 失败: %s", e.Operation, strings.Join(messages, "; "))
+}
+
+// Is 任意一项错误属于 target 类型时返回 true
+func (e *ResponseError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if classify(err) == target {
+			return true
+		}
+	}
+	return false
+}
+
+// classify 根据 extensions.code 和错误信息判断错误类型，无法判断时返回 nil
+func classify(e Error) error {
+	code, _ := e.Extensions["code"].(string)
+	code = strings.ToUpper(code)
+	msg := strings.ToLower(e.Message)
+	switch {
+	case code == "NOT_FOUND" || containsAny(msg, "not found", "does not exist", "不存在"):
+		return ErrNotFound
+	case code == "TOO_MANY_REQUESTS" || code == "RATE_LIMITED" || containsAny(msg, "too many requests", "rate limit", "频繁"):
+		return ErrRateLimited
+	case code == "UNAUTHENTICATED" || code == "FORBIDDEN" ||
+		containsAny(msg, "login", "log in", "not authenticated", "permission", "登录"):
+		return ErrAuthRequired
+	}
+	return nil
+}
+
+func containsAny(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// Client 向同一个端点发送 GraphQL 请求
+type Client struct {
+	http   *crawlhttp.Client
+	url    string
+	header http.Header
+}
+
+func NewClient(http *crawlhttp.Client, url string, header http.Header) *Client {
+	return &Client{http: http, url: url, header: header}
+}
+
+type request struct {
+	OperationName string                 `json:"operationName"`
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Query 执行操作并把 data 解码为 T；响应带有 errors 时返回 *ResponseError
+func Query[T any](ctx context.Context, c *Client, op Operation, variables map[string]interface{}) (T, error) {
+	var resp struct {
+		Data   T       `json:"data"`
+		Errors []Error `json:"errors"`
+	}
+	if variables == nil {
+		variables = map[string]interface{}{}
+	}
+	body, err := json.Marshal(request{OperationName: op.Name, Query: op.Query, Variables: variables})
+	if err != nil {
+		return resp.Data, fmt.Errorf("构建请求体失败: %w", err)
+	}
+
+	respBody, err := c.http.Post(ctx, c.url, c.header, body)
+	if err != nil {
+		return resp.Data, statusError(err)
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return resp.Data, fmt.Errorf("解析 GraphQL %s 响应失败: %w", op.Name, err)
+	}
+	if len(resp.Errors) > 0 {
+		return resp.Data, &ResponseError{Operation: op.Name, Errors: resp.Errors}
+	}
+	return resp.Data, nil
+}
+
+// statusError 把 429、401、403 转换为对应的错误类型
+func statusError(err error) error {
+	var statusErr *crawlhttp.StatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+	switch statusErr.StatusCode {
+	case http.StatusTooManyRequests:
+		return fmt.Errorf("%w: %w", ErrRateLimited, err)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%w: %w", ErrAuthRequired, err)
+	}
+	return err
+}
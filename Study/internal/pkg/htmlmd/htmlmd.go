@@ -0,0 +1,347 @@
+// Package htmlmd 把题目描述等 HTML 片段转换为 Markdown。
+// 转换时只保留排版相关的标签，脚本、样式、表单等内容直接丢弃，
+// 文本中的 Markdown 和 HTML 特殊字符会被转义，链接和图片只保留 http(s) 地址，
+// 因此输出中不会残留任何原始 HTML。
+package htmlmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// dropped 整个子树都会被丢弃的标签
+var dropped = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Iframe: true, atom.Object: true,
+	atom.Embed: true, atom.Form: true, atom.Input: true, atom.Button: true,
+	atom.Select: true, atom.Textarea: true, atom.Noscript: true, atom.Svg: true,
+	atom.Math: true, atom.Head: true, atom.Title: true, atom.Link: true, atom.Meta: true,
+}
+
+// blockTags 按块级处理的标签，前后需要空行
+var blockTags = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Pre: true, atom.Ul: true, atom.Ol: true, atom.Blockquote: true,
+	atom.Table: true, atom.Hr: true,
+}
+
+var (
+	spaces      = regexp.MustCompile(`[ \t]+`)
+	lineSpaces  = regexp.MustCompile(` *\n *`)
+	textEscaper = strings.NewReplacer(
+		`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`,
+		`[`, `\[`, `]`, `\]`, `<`, `&lt;`, `>`, `&gt;`,
+	)
+)
+
+// Convert 把 HTML 片段转换为 Markdown
+func Convert(src string) (string, error) {
+	if strings.TrimSpace(src) == "" {
+		return "", nil
+	}
+	nodes, err := html.ParseFragment(strings.NewReader(src), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return "", err
+	}
+	root := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	for _, n := range nodes {
+		root.AppendChild(n)
+	}
+	return strings.Join(blocks(root), "\n\n"), nil
+}
+
+// blocks 把子节点转换为若干个 Markdown 块，相邻的行内内容合并为一个段落
+func blocks(n *html.Node) []string {
+	var res []string
+	var inline strings.Builder
+	flush := func() {
+		if text := cleanInline(inline.String()); text != "" {
+			res = append(res, text)
+		}
+		inline.Reset()
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && blockTags[c.DataAtom] {
+			flush()
+			if b := block(c); b != "" {
+				res = append(res, b)
+			}
+			continue
+		}
+		inline.WriteString(inlineText(c))
+	}
+	flush()
+	return res
+}
+
+func block(n *html.Node) string {
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		text := cleanInline(strings.ReplaceAll(children(n), "\n", " "))
+		if text == "" {
+			return ""
+		}
+		level := int(n.Data[1] - '0')
+		return strings.Repeat("#", level) + " " + text
+	case atom.Pre:
+		return codeBlock(n)
+	case atom.Ul, atom.Ol:
+		return list(n)
+	case atom.Blockquote:
+		content := strings.Join(blocks(n), "\n\n")
+		if content == "" {
+			return ""
+		}
+		lines := strings.Split(content, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+		return strings.Join(lines, "\n")
+	case atom.Table:
+		return table(n)
+	case atom.Hr:
+		return "---"
+	default:
+		return strings.Join(blocks(n), "\n\n")
+	}
+}
+
+// inlineText 转换行内节点，块级标签出现在行内时按其文本处理
+func inlineText(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		text := strings.ReplaceAll(n.Data, "\u00a0", " ")
+		text = strings.NewReplacer("\r", " ", "\n", " ").Replace(text)
+		return textEscaper.Replace(text)
+	case html.ElementNode:
+	default:
+		return ""
+	}
+	if dropped[n.DataAtom] {
+		return ""
+	}
+	switch n.DataAtom {
+	case atom.Br:
+		return "\\\n"
+	case atom.Strong, atom.B:
+		return wrap(children(n), "**")
+	case atom.Em, atom.I:
+		return wrap(children(n), "*")
+	case atom.Del, atom.S, atom.Strike:
+		return wrap(children(n), "~~")
+	case atom.Code, atom.Kbd, atom.Samp, atom.Tt:
+		return codeSpan(plainText(n))
+	case atom.Sup:
+		return "^" + children(n)
+	case atom.Sub:
+		return `\_` + children(n)
+	case atom.A:
+		text := children(n)
+		href, ok := safeURL(attr(n, "href"))
+		if !ok || strings.TrimSpace(text) == "" {
+			return text
+		}
+		return "[" + strings.TrimSpace(text) + "](" + href + ")"
+	case atom.Img:
+		src, ok := safeURL(attr(n, "src"))
+		if !ok {
+			return ""
+		}
+		return "![" + textEscaper.Replace(attr(n, "alt")) + "](" + src + ")"
+	}
+	if blockTags[n.DataAtom] {
+		return " " + strings.Join(blocks(n), " ") + " "
+	}
+	return children(n)
+}
+
+func children(n *html.Node) string {
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(inlineText(c))
+	}
+	return sb.String()
+}
+
+// wrap 用 marker 包裹内容，首尾空白留在标记外面，否则 Markdown 不会识别
+func wrap(content, marker string) string {
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" {
+		return content
+	}
+	start := strings.Index(content, trimmed)
+	return content[:start] + marker + trimmed + marker + content[start+len(trimmed):]
+}
+
+// plainText 节点下的原始文本，用于代码
+func plainText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return strings.ReplaceAll(n.Data, "\u00a0", " ")
+	}
+	if n.Type == html.ElementNode && dropped[n.DataAtom] {
+		return ""
+	}
+	var sb strings.Builder
+	switch n.DataAtom {
+	case atom.Br:
+		return "\n"
+	case atom.Sup:
+		sb.WriteString("^")
+	case atom.Sub:
+		sb.WriteString("_")
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(plainText(c))
+	}
+	return sb.String()
+}
+
+func codeSpan(code string) string {
+	code = strings.Join(strings.Fields(code), " ")
+	if code == "" {
+		return ""
+	}
+	fence := strings.Repeat("`", longestRun(code, '`')+1)
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+		code = " " + code + " "
+	}
+	return fence + code + fence
+}
+
+func codeBlock(n *html.Node) string {
+	code := strings.Trim(plainText(n), "\n")
+	if strings.TrimSpace(code) == "" {
+		return ""
+	}
+	fence := strings.Repeat("`", max(3, longestRun(code, '`')+1))
+	return fence + "\n" + code + "\n" + fence
+}
+
+func list(n *html.Node) string {
+	var items []string
+	index := 1
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.DataAtom != atom.Li {
+			continue
+		}
+		marker := "- "
+		if n.DataAtom == atom.Ol {
+			marker = fmt.Sprintf("%d. ", index)
+			index++
+		}
+		content := strings.Join(blocks(c), "\n\n")
+		indent := strings.Repeat(" ", len(marker))
+		lines := strings.Split(content, "\n")
+		for i := 1; i < len(lines); i++ {
+			if lines[i] != "" {
+				lines[i] = indent + lines[i]
+			}
+		}
+		items = append(items, marker+strings.Join(lines, "\n"))
+	}
+	return strings.Join(items, "\n")
+}
+
+// table 转换为 GFM 表格，第一行作为表头
+func table(n *html.Node) string {
+	var rows [][]string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode {
+				continue
+			}
+			if c.DataAtom != atom.Tr {
+				walk(c)
+				continue
+			}
+			var row []string
+			for cell := c.FirstChild; cell != nil; cell = cell.NextSibling {
+				if cell.Type == html.ElementNode && (cell.DataAtom == atom.Td || cell.DataAtom == atom.Th) {
+					text := cleanInline(strings.ReplaceAll(children(cell), "\\\n", " "))
+					row = append(row, strings.ReplaceAll(text, "|", `\|`))
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	walk(n)
+	if len(rows) == 0 {
+		return ""
+	}
+
+	cols := 0
+	for _, row := range rows {
+		cols = max(cols, len(row))
+	}
+	if cols == 0 {
+		return ""
+	}
+	lines := make([]string, 0, len(rows)+1)
+	for i, row := range rows {
+		for len(row) < cols {
+			row = append(row, "")
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", cols))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// cleanInline 合并连续空白，去掉行首行尾的空格和首尾的换行
+func cleanInline(s string) string {
+	s = spaces.ReplaceAllString(s, " ")
+	s = lineSpaces.ReplaceAllString(s, "\n")
+	// 换行只来自 <br>，段落首尾的换行没有意义
+	for {
+		trimmed := strings.TrimSuffix(strings.TrimPrefix(strings.Trim(s, " "), "\\\n"), "\\\n")
+		if trimmed == s {
+			return s
+		}
+		s = trimmed
+	}
+}
+
+func safeURL(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "//") {
+		raw = "https:" + raw
+	}
+	lower := strings.ToLower(raw)
+	if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+		return "", false
+	}
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(raw), true
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func longestRun(s string, ch byte) int {
+	longest, cur := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == ch {
+			cur++
+			longest = max(longest, cur)
+		} else {
+			cur = 0
+		}
+	}
+	return longest
+}
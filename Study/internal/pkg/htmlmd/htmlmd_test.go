@@ -0,0 +1,95 @@
+package htmlmd
+
+import "testing"
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "丢弃脚本样式和内嵌页面",
+			src:  `<p>a</p><script>alert(1)</script><style>p{color:red}</style><iframe src="https://example.com"></iframe><p>b</p>`,
+			want: "a\n\nb",
+		},
+		{
+			name: "行内的脚本同样丢弃",
+			src:  `<p>a<script>alert(1)</script>b</p>`,
+			want: "ab",
+		},
+		{
+			name: "去掉 javascript 和 data 链接",
+			src:  `<a href="javascript:alert(1)">click</a> <a href=" JavaScript:alert(1)">x</a> <a href="data:text/html,x">d</a>`,
+			want: "click x d",
+		},
+		{
+			name: "去掉 javascript 和 data 图片",
+			src:  `<p>a<img src="data:image/png;base64,AA" alt="x"><img src="javascript:alert(1)">b</p>`,
+			want: "ab",
+		},
+		{
+			name: "保留 http 链接和图片",
+			src:  `<a href="https://leetcode.cn/problems/two-sum/">two sum</a> <img src="//example.com/a b.png" alt="图">`,
+			want: "[two sum](https://leetcode.cn/problems/two-sum/) ![图](https://example.com/a%20b.png)",
+		},
+		{
+			name: "转义尖括号",
+			src:  `<p>1 &lt; 2 &gt; 0，&lt;script&gt;alert(1)&lt;/script&gt;</p>`,
+			want: "1 &lt; 2 &gt; 0，&lt;script&gt;alert(1)&lt;/script&gt;",
+		},
+		{
+			name: "转义 Markdown 特殊字符",
+			src:  "<p>a*b_c [x](y) `z` \\</p>",
+			want: "a\\*b\\_c \\[x\\](y) \\`z\\` \\\\",
+		},
+		{
+			name: "代码块中的加粗输出为纯文本",
+			src:  "<pre><strong>输入：</strong>nums = [2,7], target = 9\n<strong>输出：</strong>[0,1]</pre>",
+			want: "```\n输入：nums = [2,7], target = 9\n输出：[0,1]\n```",
+		},
+		{
+			name: "代码块中的内容不转义",
+			src:  "<pre>a < b && *p</pre>",
+			want: "```\na < b && *p\n```",
+		},
+		{
+			name: "嵌套列表",
+			src:  `<ul><li>a<ul><li>b</li><li>c<ol><li>d</li><li>e</li></ol></li></ul></li><li>f</li></ul>`,
+			want: "- a\n\n  - b\n  - c\n\n    1. d\n    2. e\n- f",
+		},
+		{
+			name: "表格",
+			src: `<table><thead><tr><th>输入</th><th>a|b</th></tr></thead>` +
+				`<tbody><tr><td><code>1</code></td><td>x<br>y</td></tr><tr><td>2</td></tr></tbody></table>`,
+			want: "| 输入 | a\\|b |\n| --- | --- |\n| `1` | x y |\n| 2 |  |",
+		},
+		{
+			name: "上标和下标",
+			src:  `<p>2<sup>31</sup> - 1，x<sub>i</sub></p><pre>10<sup>4</sup></pre><p><code>a<sub>i</sub></code></p>`,
+			want: "2^31 - 1，x\\_i\n\n```\n10^4\n```\n\n`a_i`",
+		},
+		{
+			name: "LeetCode 的空段落和不换行空格",
+			src: `<p>给你一个整数数组&nbsp;<code>nums</code>&nbsp;和一个整数目标值&nbsp;<code>target</code>。</p>` +
+				`<p>&nbsp;</p><p><strong>示例 1：</strong></p>`,
+			want: "给你一个整数数组 `nums` 和一个整数目标值 `target`。\n\n**示例 1：**",
+		},
+		{
+			name: "空白内容",
+			src:  " \n ",
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Convert(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Convert(%q)\n got %q\nwant %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
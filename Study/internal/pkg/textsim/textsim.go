@@ -0,0 +1,94 @@
+// Package textsim 文本相似度：SimHash 指纹用于快速筛选候选，Jaccard 相似度用于确认
+package textsim
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+const (
+	// simhashShingle 计算指纹时以连续 3 个字符为一个分片
+	simhashShingle = 3
+	// jaccardShingle 计算相似度时以连续 2 个字符为一个分片，对短文本更稳定
+	jaccardShingle = 2
+)
+
+// Simhash 计算文本的 64 位 SimHash 指纹：只保留字母和数字并转为小写，按字符切分重叠分片
+func Simhash(text string) uint64 {
+	runes := normalize(text)
+	if len(runes) == 0 {
+		return 0
+	}
+	if len(runes) < simhashShingle {
+		return hash(string(runes))
+	}
+
+	var weights [64]int
+	for i := 0; i+simhashShingle <= len(runes); i++ {
+		h := hash(string(runes[i : i+simhashShingle]))
+		for bit := 0; bit < 64; bit++ {
+			if h&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+	var res uint64
+	for bit, w := range weights {
+		if w > 0 {
+			res |= 1 << bit
+		}
+	}
+	return res
+}
+
+// Distance 两个指纹的海明距离，越小越相似
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Jaccard 两段文本分片集合的 Jaccard 相似度，取值 0 到 1
+func Jaccard(a, b string) float64 {
+	sa, sb := shingles(a), shingles(b)
+	if len(sa) == 0 || len(sb) == 0 {
+		return 0
+	}
+	inter := 0
+	for s := range sa {
+		if _, ok := sb[s]; ok {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(sa)+len(sb)-inter)
+}
+
+func shingles(text string) map[string]struct{} {
+	runes := normalize(text)
+	res := make(map[string]struct{}, len(runes))
+	if len(runes) > 0 && len(runes) < jaccardShingle {
+		res[string(runes)] = struct{}{}
+	}
+	for i := 0; i+jaccardShingle <= len(runes); i++ {
+		res[string(runes[i:i+jaccardShingle])] = struct{}{}
+	}
+	return res
+}
+
+func normalize(text string) []rune {
+	runes := make([]rune, 0, len(text))
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			runes = append(runes, r)
+		}
+	}
+	return runes
+}
+
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum64()
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/repository/dao"
+	"context"
+
+	"github.com/ecodeclub/ekit/slice"
+)
+
+type CodingAttemptRepository interface {
+	// Create 保存做题记录，同时保存 schedule 根据题目当前状态算出的学习状态和重做计划，
+	// 题目不存在时返回 ErrCodingProblemNotFound
+	Create(ctx context.Context, attempt domain.CodingAttempt,
+		schedule func(problem domain.CodingProblem) domain.CodingProblem) (domain.CodingAttempt, error)
+	FindByProblem(ctx context.Context, problemId int64) ([]domain.CodingAttempt, error)
+}
+
+type codingAttemptRepository struct {
+	dao dao.CodingAttemptDao
+}
+
+func NewCodingAttemptRepository(dao dao.CodingAttemptDao) CodingAttemptRepository {
+	return &codingAttemptRepository{dao: dao}
+}
+
+func (r *codingAttemptRepository) Create(ctx context.Context, attempt domain.CodingAttempt,
+	schedule func(problem domain.CodingProblem) domain.CodingProblem) (domain.CodingAttempt, error) {
+	entity, err := r.dao.Insert(ctx, r.toEntity(attempt), func(problem dao.CodingProblem) dao.CodingProblem {
+		return toEntity(schedule(toCodingProblem(problem)))
+	})
+	if err != nil {
+		return domain.CodingAttempt{}, err
+	}
+	return r.toDomain(entity), nil
+}
+
+func (r *codingAttemptRepository) FindByProblem(ctx context.Context, problemId int64) ([]domain.CodingAttempt, error) {
+	attempts, err := r.dao.FindByProblem(ctx, problemId)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(attempts, func(idx int, src dao.CodingAttempt) domain.CodingAttempt {
+		return r.toDomain(src)
+	}), nil
+}
+
+func (r *codingAttemptRepository) toEntity(attempt domain.CodingAttempt) dao.CodingAttempt {
+	return dao.CodingAttempt{
+		Id:        attempt.Id,
+		ProblemId: attempt.ProblemId,
+		Language:  attempt.Language,
+		Code:      attempt.Code,
+		Result:    attempt.Result,
+		TimeSpent: attempt.TimeSpent,
+		Notes:     attempt.Notes,
+		Ctime:     attempt.Ctime,
+	}
+}
+
+func (r *codingAttemptRepository) toDomain(attempt dao.CodingAttempt) domain.CodingAttempt {
+	return domain.CodingAttempt{
+		Id:        attempt.Id,
+		ProblemId: attempt.ProblemId,
+		Language:  attempt.Language,
+		Code:      attempt.Code,
+		Result:    attempt.Result,
+		TimeSpent: attempt.TimeSpent,
+		Notes:     attempt.Notes,
+		Ctime:     attempt.Ctime,
+	}
+}
@@ -7,21 +7,39 @@ import (
 	"time"
 )
 
+var (
+	ErrCodingProblemNotFound = dao.ErrRecordNotFound
+	ErrProblemTrashed        = dao.ErrProblemTrashed
+)
+
 type CodingProblemRepository interface {
 	Create(ctx context.Context, problem domain.CodingProblem) error
 	FindAll(ctx context.Context) ([]domain.CodingProblem, error)
 	FindById(ctx context.Context, id int64) (domain.CodingProblem, error)
 	FindBySource(ctx context.Context, source string) ([]domain.CodingProblem, error)
-	FindBySourceId(ctx context.Context, sourceId string) ([]domain.CodingProblem, error)
+	// FindBySourceId 按来源和来源题号查找题目
+	FindBySourceId(ctx context.Context, source, sourceId string) ([]domain.CodingProblem, error)
+	// ExistsBySourceId 判断来源中的题目是否存在，回收站中的题目也算存在
+	ExistsBySourceId(ctx context.Context, source, sourceId string) (bool, error)
+	// Upsert 按来源和来源题号新增或更新题目元数据，created 表示是否为新增
+	Upsert(ctx context.Context, problem domain.CodingProblem) (res domain.CodingProblem, created bool, err error)
 	FindByDifficulty(ctx context.Context, difficulty string) ([]domain.CodingProblem, error)
+	FindDue(ctx context.Context, now time.Time, limit int) ([]domain.CodingProblem, error)
 	Update(ctx context.Context, problem domain.CodingProblem) error
+	// UpdateDetail 用抓取到的详情更新编号为 id 的题目的标题、标签、描述和会员标记
+	UpdateDetail(ctx context.Context, id int64, detail domain.CodingProblem) error
 	Delete(ctx context.Context, id int64) error
-	GetDailyProblem(ctx context.Context) (*domain.CodingProblem, error)
-	SetDailyProblem(ctx context.Context, problemId int64) error
+	// GetDailyProblem 来源 source 在 date 的每日一题，没有抓取到时返回 ErrCodingProblemNotFound
+	GetDailyProblem(ctx context.Context, source string, date domain.CivilDate) (*domain.CodingProblem, error)
+	SetDailyProblem(ctx context.Context, source string, problemId int64, date domain.CivilDate) error
 	GetDailyProblemHistory(ctx context.Context) ([]domain.CodingProblem, error)
-	MarkAsDailyProblem(ctx context.Context, problemId int64, date time.Time) error
+	// MarkAsDailyProblem 把题目记为来源 source 在 date 的每日一题，替换这个来源当天原有的记录
+	MarkAsDailyProblem(ctx context.Context, source string, problemId int64, date domain.CivilDate) error
 	UpdateStudyStatus(ctx context.Context, problemId int64, status string, lastStudied *time.Time) error
 	SaveDailyProblem(dailyProblem *domain.DailyProblem) error
+	FindDeleted(ctx context.Context) ([]domain.TrashItem, error)
+	Restore(ctx context.Context, id int64) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 }
 
 type CachedCodingProblemRepository struct {
@@ -69,8 +87,8 @@ func (r *CachedCodingProblemRepository) FindBySource(ctx context.Context, source
 	return result, nil
 }
 
-func (r *CachedCodingProblemRepository) FindBySourceId(ctx context.Context, sourceId string) ([]domain.CodingProblem, error) {
-	problems, err := r.dao.FindBySourceId(ctx, sourceId)
+func (r *CachedCodingProblemRepository) FindBySourceId(ctx context.Context, source, sourceId string) ([]domain.CodingProblem, error) {
+	problems, err := r.dao.FindBySourceId(ctx, source, sourceId)
 	if err != nil {
 		return nil, err
 	}
@@ -81,6 +99,18 @@ func (r *CachedCodingProblemRepository) FindBySourceId(ctx context.Context, sour
 	return result, nil
 }
 
+func (r *CachedCodingProblemRepository) Upsert(ctx context.Context, problem domain.CodingProblem) (domain.CodingProblem, bool, error) {
+	entity, created, err := r.dao.Upsert(ctx, toEntity(problem))
+	if err != nil {
+		return domain.CodingProblem{}, false, err
+	}
+	return r.toDomain(entity), created, nil
+}
+
+func (r *CachedCodingProblemRepository) ExistsBySourceId(ctx context.Context, source, sourceId string) (bool, error) {
+	return r.dao.ExistsBySourceId(ctx, source, sourceId)
+}
+
 func (r *CachedCodingProblemRepository) FindByDifficulty(ctx context.Context, difficulty string) ([]domain.CodingProblem, error) {
 	problems, err := r.dao.FindByDifficulty(ctx, difficulty)
 	if err != nil {
@@ -93,16 +123,32 @@ func (r *CachedCodingProblemRepository) FindByDifficulty(ctx context.Context, di
 	return result, nil
 }
 
+func (r *CachedCodingProblemRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]domain.CodingProblem, error) {
+	problems, err := r.dao.FindDue(ctx, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domain.CodingProblem, 0, len(problems))
+	for _, p := range problems {
+		result = append(result, r.toDomain(p))
+	}
+	return result, nil
+}
+
 func (r *CachedCodingProblemRepository) Update(ctx context.Context, problem domain.CodingProblem) error {
 	return r.dao.UpdateById(ctx, toEntity(problem))
 }
 
+func (r *CachedCodingProblemRepository) UpdateDetail(ctx context.Context, id int64, detail domain.CodingProblem) error {
+	return r.dao.UpdateDetail(ctx, id, toEntity(detail))
+}
+
 func (r *CachedCodingProblemRepository) Delete(ctx context.Context, id int64) error {
 	return r.dao.DeleteById(ctx, id)
 }
 
-func (c *CachedCodingProblemRepository) GetDailyProblem(ctx context.Context) (*domain.CodingProblem, error) {
-	problem, err := c.dao.GetDailyProblem(ctx)
+func (c *CachedCodingProblemRepository) GetDailyProblem(ctx context.Context, source string, date domain.CivilDate) (*domain.CodingProblem, error) {
+	problem, err := c.dao.GetDailyProblem(ctx, source, date)
 	if err != nil {
 		return nil, err
 	}
@@ -113,8 +159,8 @@ func (c *CachedCodingProblemRepository) GetDailyProblem(ctx context.Context) (*d
 	return &result, nil
 }
 
-func (c *CachedCodingProblemRepository) SetDailyProblem(ctx context.Context, problemId int64) error {
-	return c.dao.SetDailyProblem(ctx, problemId)
+func (c *CachedCodingProblemRepository) SetDailyProblem(ctx context.Context, source string, problemId int64, date domain.CivilDate) error {
+	return c.dao.SetDailyProblem(ctx, source, problemId, date)
 }
 
 func (c *CachedCodingProblemRepository) GetDailyProblemHistory(ctx context.Context) ([]domain.CodingProblem, error) {
@@ -130,8 +176,8 @@ func (c *CachedCodingProblemRepository) GetDailyProblemHistory(ctx context.Conte
 	return result, nil
 }
 
-func (c *CachedCodingProblemRepository) MarkAsDailyProblem(ctx context.Context, problemId int64, date time.Time) error {
-	return c.dao.MarkAsDailyProblem(ctx, problemId, date)
+func (c *CachedCodingProblemRepository) MarkAsDailyProblem(ctx context.Context, source string, problemId int64, date domain.CivilDate) error {
+	return c.dao.MarkAsDailyProblem(ctx, source, problemId, date)
 }
 
 func (c *CachedCodingProblemRepository) UpdateStudyStatus(ctx context.Context, problemId int64, status string, lastStudied *time.Time) error {
@@ -147,36 +193,76 @@ func toEntity(p domain.CodingProblem) dao.CodingProblem {
 	return dao.CodingProblem{
 		Id:             p.Id,
 		Title:          p.Title,
+		Description:    p.Description,
 		Difficulty:     p.Difficulty,
 		Tags:           dao.StringSlice(p.Tags),
 		Source:         p.Source,
-		SourceId:       p.SourceId,
+		SourceId:       dao.NullString(p.SourceId),
 		SourceUrl:      p.SourceUrl,
+		TitleSlug:      p.TitleSlug,
+		PaidOnly:       p.PaidOnly,
+		AcRate:         p.AcRate,
 		StudyStatus:    p.StudyStatus,
 		LastStudied:    p.LastStudied,
 		IsDailyProblem: p.IsDailyProblem,
 		DailyDate:      p.DailyDate,
-		IsHot100:       p.IsHot100,
+		ReviewStage:    p.ReviewStage,
+		NextReviewAt:   p.NextReviewAt,
 		Ctime:          p.Ctime,
 		Utime:          p.Utime,
 	}
 }
 
+func (r *CachedCodingProblemRepository) FindDeleted(ctx context.Context) ([]domain.TrashItem, error) {
+	problems, err := r.dao.FindDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domain.TrashItem, 0, len(problems))
+	for _, p := range problems {
+		result = append(result, domain.TrashItem{
+			Type:      domain.TrashCodingProblem,
+			Id:        p.Id,
+			Title:     p.Title,
+			Category:  p.Difficulty,
+			DeletedAt: p.DeletedAt.Time,
+		})
+	}
+	return result, nil
+}
+
+func (r *CachedCodingProblemRepository) Restore(ctx context.Context, id int64) error {
+	return r.dao.Restore(ctx, id)
+}
+
+func (r *CachedCodingProblemRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	return r.dao.PurgeDeleted(ctx, before)
+}
+
 // 转换方法
 func (r *CachedCodingProblemRepository) toDomain(p dao.CodingProblem) domain.CodingProblem {
+	return toCodingProblem(p)
+}
+
+func toCodingProblem(p dao.CodingProblem) domain.CodingProblem {
 	return domain.CodingProblem{
 		Id:             p.Id,
 		Title:          p.Title,
+		Description:    p.Description,
 		Difficulty:     p.Difficulty,
 		Tags:           []string(p.Tags),
 		Source:         p.Source,
-		SourceId:       p.SourceId,
+		SourceId:       string(p.SourceId),
 		SourceUrl:      p.SourceUrl,
+		TitleSlug:      p.TitleSlug,
+		PaidOnly:       p.PaidOnly,
+		AcRate:         p.AcRate,
 		StudyStatus:    p.StudyStatus,
 		LastStudied:    p.LastStudied,
 		IsDailyProblem: p.IsDailyProblem,
 		DailyDate:      p.DailyDate,
-		IsHot100:       p.IsHot100,
+		ReviewStage:    p.ReviewStage,
+		NextReviewAt:   p.NextReviewAt,
 		Ctime:          p.Ctime,
 		Utime:          p.Utime,
 	}
@@ -0,0 +1,68 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CodingAttempt 刷题的做题记录
+type CodingAttempt struct {
+	Id        int64     `gorm:"primaryKey,autoIncrement"`
+	ProblemId int64     `gorm:"type:bigint;not null;index"`
+	Language  string    `gorm:"type:varchar(50);not null;default:''"`
+	Code      string    `gorm:"type:mediumtext"`
+	Result    string    `gorm:"type:varchar(20);not null"`
+	TimeSpent int64     `gorm:"type:bigint;not null;default:0"` // 用时(秒)
+	Notes     string    `gorm:"type:text"`
+	Ctime     time.Time `gorm:"type:datetime(3);index"`
+}
+
+func (a CodingAttempt) TableName() string {
+	return "coding_attempts"
+}
+
+type CodingAttemptDao interface {
+	// Insert 保存做题记录，并在同一事务中锁定题目，用 schedule 的结果更新学习状态、最后学习时间和重做计划
+	Insert(ctx context.Context, attempt CodingAttempt, schedule func(problem CodingProblem) CodingProblem) (CodingAttempt, error)
+	// FindByProblem 查询题目的做题记录，按时间倒序
+	FindByProblem(ctx context.Context, problemId int64) ([]CodingAttempt, error)
+}
+
+type codingAttemptDao struct {
+	db *gorm.DB
+}
+
+func NewCodingAttemptDao(db *gorm.DB) CodingAttemptDao {
+	return &codingAttemptDao{db: db}
+}
+
+func (dao *codingAttemptDao) Insert(ctx context.Context, attempt CodingAttempt,
+	schedule func(problem CodingProblem) CodingProblem) (CodingAttempt, error) {
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var problem CodingProblem
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", attempt.ProblemId).First(&problem).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(&attempt).Error; err != nil {
+			return err
+		}
+		problem = schedule(problem)
+		problem.Utime = attempt.Ctime
+		return tx.Model(&CodingProblem{}).Where("id = ?", attempt.ProblemId).
+			Select("study_status", "last_studied", "review_stage", "next_review_at", "utime").
+			Updates(&problem).Error
+	})
+	return attempt, err
+}
+
+func (dao *codingAttemptDao) FindByProblem(ctx context.Context, problemId int64) ([]CodingAttempt, error) {
+	var attempts []CodingAttempt
+	err := dao.db.WithContext(ctx).Where("problem_id = ?", problemId).
+		Order("ctime DESC, id DESC").Find(&attempts).Error
+	return attempts, err
+}
@@ -4,10 +4,10 @@ import (
 	"Training/Study/internal/domain"
 	"context"
 	"errors"
-	"log"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CodingProblemDAO interface {
@@ -15,18 +15,37 @@ type CodingProblemDAO interface {
 	FindAll(ctx context.Context) ([]CodingProblem, error)
 	FindById(ctx context.Context, id int64) (CodingProblem, error)
 	FindBySource(ctx context.Context, source string) ([]CodingProblem, error)
-	FindBySourceId(ctx context.Context, sourceId string) ([]CodingProblem, error)
+	// FindBySourceId 按来源和来源题号查找题目，不同来源的题号可能相同
+	FindBySourceId(ctx context.Context, source, sourceId string) ([]CodingProblem, error)
+	// Upsert 按来源和来源题号新增或更新题目的元数据，学习进度保持不变，created 表示是否为新增；
+	// 题目在回收站中时返回 ErrProblemTrashed
+	Upsert(ctx context.Context, problem CodingProblem) (res CodingProblem, created bool, err error)
+	// ExistsBySourceId 判断来源中的题目是否存在，回收站中的题目也算存在
+	ExistsBySourceId(ctx context.Context, source, sourceId string) (bool, error)
 	FindByDifficulty(ctx context.Context, difficulty string) ([]CodingProblem, error)
+	// FindDue 查找到期需要重做的题目，按到期时间先后排序
+	FindDue(ctx context.Context, now time.Time, limit int) ([]CodingProblem, error)
 	UpdateById(ctx context.Context, problem CodingProblem) error
+	// UpdateDetail 用抓取到的详情更新题目的标题、标签、描述和会员标记，来源、题号和学习进度保持不变
+	UpdateDetail(ctx context.Context, id int64, detail CodingProblem) error
 	DeleteById(ctx context.Context, id int64) error
-	GetDailyProblem(ctx context.Context) (*CodingProblem, error)
-	SetDailyProblem(ctx context.Context, problemId int64) error
+	// GetDailyProblem 查找来源 source 在 date 的每日一题，没有抓取到时返回 ErrRecordNotFound
+	GetDailyProblem(ctx context.Context, source string, date domain.CivilDate) (*CodingProblem, error)
+	SetDailyProblem(ctx context.Context, source string, problemId int64, date domain.CivilDate) error
 	GetDailyProblemHistory(ctx context.Context) ([]CodingProblem, error)
-	MarkAsDailyProblem(ctx context.Context, problemId int64, date time.Time) error
+	// MarkAsDailyProblem 把题目记为来源 source 在 date 的每日一题，只替换这个来源的记录
+	MarkAsDailyProblem(ctx context.Context, source string, problemId int64, date domain.CivilDate) error
 	UpdateStudyStatus(ctx context.Context, problemId int64, status string, lastStudied *time.Time) error
 	SaveDailyProblem(ctx context.Context, dailyProblem *domain.DailyProblem) error
+	// FindDeleted 查询回收站中的题目，按删除时间倒序
+	FindDeleted(ctx context.Context) ([]CodingProblem, error)
+	Restore(ctx context.Context, id int64) error
+	// PurgeDeleted 彻底删除 before 之前删除的题目，返回删除的题目数
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 }
 
+var ErrProblemTrashed = errors.New("problem is in trash")
+
 type GormCodingProblemDAO struct {
 	db *gorm.DB
 }
@@ -47,9 +66,10 @@ func (g *GormCodingProblemDAO) Insert(ctx context.Context, problem CodingProblem
 	return g.db.WithContext(ctx).Create(&problem).Error
 }
 
+// FindAll 列表查询不带题目描述，描述只在 FindById 等单题查询中返回
 func (g *GormCodingProblemDAO) FindAll(ctx context.Context) ([]CodingProblem, error) {
 	var problems []CodingProblem
-	err := g.db.WithContext(ctx).Find(&problems).Error
+	err := g.db.WithContext(ctx).Omit("description").Find(&problems).Error
 	return problems, err
 }
 
@@ -61,19 +81,88 @@ func (g *GormCodingProblemDAO) FindById(ctx context.Context, id int64) (CodingPr
 
 func (g *GormCodingProblemDAO) FindBySource(ctx context.Context, source string) ([]CodingProblem, error) {
 	var problems []CodingProblem
-	err := g.db.WithContext(ctx).Where("source = ?", source).Find(&problems).Error
+	err := g.db.WithContext(ctx).Omit("description").Where("source = ?", source).Find(&problems).Error
 	return problems, err
 }
 
-func (g *GormCodingProblemDAO) FindBySourceId(ctx context.Context, sourceId string) ([]CodingProblem, error) {
+func (g *GormCodingProblemDAO) FindBySourceId(ctx context.Context, source, sourceId string) ([]CodingProblem, error) {
 	var problems []CodingProblem
-	err := g.db.WithContext(ctx).Where("source_id = ?", sourceId).Find(&problems).Error
+	err := g.db.WithContext(ctx).Where("source = ? AND source_id = ?", source, sourceId).Find(&problems).Error
 	return problems, err
 }
 
+func (g *GormCodingProblemDAO) Upsert(ctx context.Context, problem CodingProblem) (CodingProblem, bool, error) {
+	created := false
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		problem.Ctime = now
+		problem.Utime = now
+		if problem.StudyStatus == "" {
+			problem.StudyStatus = "not_started"
+		}
+		// 来源和来源题号有唯一索引，同时导入同一道题时只有一个请求会新增
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&problem)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			created = true
+			return nil
+		}
+
+		var existing CodingProblem
+		err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("source = ? AND source_id = ?", problem.Source, problem.SourceId).First(&existing).Error
+		if err != nil {
+			return err
+		}
+		if existing.DeletedAt.Valid {
+			return ErrProblemTrashed
+		}
+
+		updates := map[string]interface{}{
+			"title":      problem.Title,
+			"difficulty": problem.Difficulty,
+			"tags":       problem.Tags,
+			"source_url": problem.SourceUrl,
+			"title_slug": problem.TitleSlug,
+			"paid_only":  problem.PaidOnly,
+			"utime":      now,
+		}
+		// 抓取详情失败时没有描述，保留原来的描述
+		if problem.Description != "" {
+			updates["description"] = problem.Description
+		}
+		// 题目详情接口不返回通过率，保留题库同步时的值
+		if problem.AcRate > 0 {
+			updates["ac_rate"] = problem.AcRate
+		}
+		if err := tx.Model(&existing).Updates(updates).Error; err != nil {
+			return err
+		}
+		problem = CodingProblem{}
+		return tx.Where("id = ?", existing.Id).First(&problem).Error
+	})
+	return problem, created, err
+}
+
+func (g *GormCodingProblemDAO) ExistsBySourceId(ctx context.Context, source, sourceId string) (bool, error) {
+	var cnt int64
+	err := g.db.WithContext(ctx).Unscoped().Model(&CodingProblem{}).
+		Where("source = ? AND source_id = ?", source, sourceId).Count(&cnt).Error
+	return cnt > 0, err
+}
+
 func (g *GormCodingProblemDAO) FindByDifficulty(ctx context.Context, difficulty string) ([]CodingProblem, error) {
 	var problems []CodingProblem
-	err := g.db.WithContext(ctx).Where("difficulty = ?", difficulty).Find(&problems).Error
+	err := g.db.WithContext(ctx).Omit("description").Where("difficulty = ?", difficulty).Find(&problems).Error
+	return problems, err
+}
+
+func (g *GormCodingProblemDAO) FindDue(ctx context.Context, now time.Time, limit int) ([]CodingProblem, error) {
+	var problems []CodingProblem
+	err := g.db.WithContext(ctx).Omit("description").Where("next_review_at <= ?", now).
+		Order("next_review_at ASC, id ASC").Limit(limit).Find(&problems).Error
 	return problems, err
 }
 
@@ -82,62 +171,48 @@ func (g *GormCodingProblemDAO) UpdateById(ctx context.Context, problem CodingPro
 	return g.db.WithContext(ctx).Where("id = ?", problem.Id).Updates(&problem).Error
 }
 
+func (g *GormCodingProblemDAO) UpdateDetail(ctx context.Context, id int64, detail CodingProblem) error {
+	updates := map[string]interface{}{
+		"title":     detail.Title,
+		"tags":      detail.Tags,
+		"paid_only": detail.PaidOnly,
+		"utime":     time.Now(),
+	}
+	// 会员题的详情中没有描述，保留原来的描述
+	if detail.Description != "" {
+		updates["description"] = detail.Description
+	}
+	return g.db.WithContext(ctx).Model(&CodingProblem{}).Where("id = ?", id).Updates(updates).Error
+}
+
 func (g *GormCodingProblemDAO) DeleteById(ctx context.Context, id int64) error {
 	return g.db.WithContext(ctx).Where("id = ?", id).Delete(&CodingProblem{}).Error
 }
 
 // 每日一题相关方法
-func (g *GormCodingProblemDAO) GetDailyProblem(ctx context.Context) (*CodingProblem, error) {
-	today := time.Now()
-	todayStr := today.Format("2006-01-02")
-
-	// 查找今天标记为每日一题的题目
-	var problem CodingProblem
-	err := g.db.WithContext(ctx).Where("is_daily_problem = ? AND DATE(daily_date) = ?", true, todayStr).First(&problem).Error
-	if err == nil {
-		return &problem, nil
-	}
-
-	if err != gorm.ErrRecordNotFound {
-		// 如果是其他错误，记录日志
-		log.Printf("Error finding daily problem: %v", err)
-	}
-
-	// 如果没有找到今天的每日一题，尝试从 daily_problems 表获取
+func (g *GormCodingProblemDAO) GetDailyProblem(ctx context.Context, source string, date domain.CivilDate) (*CodingProblem, error) {
+	// 只返回从这个来源抓取到的当天记录，不能用其他题目代替网站没有发布的每日一题
 	var dailyProblem DailyProblem
-	err = g.db.WithContext(ctx).Where("date = ?", todayStr).First(&dailyProblem).Error
-	if err == nil {
-		// 找到了每日一题记录，获取对应的题目
-		err = g.db.WithContext(ctx).Where("id = ?", dailyProblem.ProblemId).First(&problem).Error
-		if err == nil {
-			return &problem, nil
-		}
-	}
-
-	// 如果还是没有找到，随机选择一题作为每日一题
-	err = g.db.WithContext(ctx).Order("RAND()").First(&problem).Error
+	err := g.db.WithContext(ctx).Where("source = ? AND date = ?", source, date).First(&dailyProblem).Error
 	if err != nil {
 		return nil, err
 	}
-
-	// 将选中的题目标记为每日一题
-	err = g.MarkAsDailyProblem(ctx, problem.Id, today)
+	var problem CodingProblem
+	err = g.db.WithContext(ctx).Where("id = ?", dailyProblem.ProblemId).First(&problem).Error
 	if err != nil {
-		log.Printf("Error marking problem as daily: %v", err)
 		return nil, err
 	}
-
 	return &problem, nil
 }
 
-func (g *GormCodingProblemDAO) SetDailyProblem(ctx context.Context, problemId int64) error {
-	return g.MarkAsDailyProblem(ctx, problemId, time.Now())
+func (g *GormCodingProblemDAO) SetDailyProblem(ctx context.Context, source string, problemId int64, date domain.CivilDate) error {
+	return g.MarkAsDailyProblem(ctx, source, problemId, date)
 }
 
 func (g *GormCodingProblemDAO) GetDailyProblemHistory(ctx context.Context) ([]CodingProblem, error) {
-	// 从 daily_problems 表获取历史记录，按日期倒序排列
+	// 从 daily_problems 表获取历史记录，按日期倒序排列，同一天按来源排列
 	var dailyProblems []DailyProblem
-	err := g.db.WithContext(ctx).Order("date desc").Find(&dailyProblems).Error
+	err := g.db.WithContext(ctx).Order("date desc, source").Find(&dailyProblems).Error
 	if err != nil {
 		return nil, err
 	}
@@ -145,16 +220,17 @@ func (g *GormCodingProblemDAO) GetDailyProblemHistory(ctx context.Context) ([]Co
 	// 转换为 CodingProblem 列表
 	var problems []CodingProblem
 	for _, dp := range dailyProblems {
+		date := dp.Date
 		problem := CodingProblem{
 			Id:             dp.ProblemId, // 使用原始题目ID
 			Title:          dp.Title,
 			Difficulty:     dp.Difficulty,
 			Tags:           dp.Tags,
 			Source:         dp.Source,
-			SourceId:       dp.SourceId,
+			SourceId:       NullString(dp.SourceId),
 			SourceUrl:      dp.SourceUrl,
-			IsDailyProblem: true,               // 历史记录中的都是每日一题
-			DailyDate:      parseDate(dp.Date), // 转换日期字符串为时间
+			IsDailyProblem: true, // 历史记录中的都是每日一题
+			DailyDate:      &date,
 			Ctime:          dp.Ctime,
 			Utime:          dp.Utime,
 		}
@@ -164,68 +240,54 @@ func (g *GormCodingProblemDAO) GetDailyProblemHistory(ctx context.Context) ([]Co
 	return problems, nil
 }
 
-// parseDate 辅助函数，将日期字符串转换为时间指针
-func parseDate(dateStr string) *time.Time {
-	if dateStr == "" {
-		return nil
-	}
-
-	t, err := time.Parse("2006-01-02", dateStr)
-	if err != nil {
-		return nil
-	}
-
-	return &t
-}
-
-func (g *GormCodingProblemDAO) MarkAsDailyProblem(ctx context.Context, problemId int64, date time.Time) error {
-	// 先重置所有题目的每日一题标记
-	err := g.db.WithContext(ctx).Model(&CodingProblem{}).Where("is_daily_problem = ?", true).Updates(map[string]interface{}{
-		"is_daily_problem": false,
-		"daily_date":       nil,
-	}).Error
-	if err != nil {
-		return err
-	}
-
-	// 更新选中的题目为每日一题
-	err = g.db.WithContext(ctx).Model(&CodingProblem{}).Where("id = ?", problemId).Updates(map[string]interface{}{
-		"is_daily_problem": true,
-		"daily_date":       date,
-	}).Error
-	if err != nil {
-		return err
-	}
-
-	// 获取题目信息
-	var problem CodingProblem
-	err = g.db.WithContext(ctx).Where("id = ?", problemId).First(&problem).Error
-	if err != nil {
-		return err
-	}
+func (g *GormCodingProblemDAO) MarkAsDailyProblem(ctx context.Context, source string, problemId int64, date domain.CivilDate) error {
+	return g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 获取题目信息
+		var problem CodingProblem
+		if err := tx.Where("id = ?", problemId).First(&problem).Error; err != nil {
+			return err
+		}
 
-	// 同时在 daily_problems 表中记录
-	dailyProblem := DailyProblem{
-		Date:       date.Format("2006-01-02"),
-		Title:      problem.Title,
-		Difficulty: problem.Difficulty,
-		Tags:       problem.Tags,
-		Source:     problem.Source,
-		SourceId:   problem.SourceId,
-		SourceUrl:  problem.SourceUrl,
-		ProblemId:  problem.Id,
-		Ctime:      date,
-		Utime:      date,
-	}
+		// 只重置这个来源原来的每日一题标记，其他来源的每日一题不受影响
+		err := tx.Model(&CodingProblem{}).
+			Where("is_daily_problem = ? AND id IN (?)", true,
+				tx.Model(&DailyProblem{}).Select("problem_id").Where("source = ?", source)).
+			Updates(map[string]interface{}{
+				"is_daily_problem": false,
+				"daily_date":       nil,
+			}).Error
+		if err != nil {
+			return err
+		}
 
-	// 先删除可能存在的当天记录
-	err = g.db.WithContext(ctx).Where("date = ?", dailyProblem.Date).Delete(&DailyProblem{}).Error
-	if err != nil {
-		return err
-	}
+		// 更新选中的题目为每日一题
+		err = tx.Model(&CodingProblem{}).Where("id = ?", problemId).Updates(map[string]interface{}{
+			"is_daily_problem": true,
+			"daily_date":       date,
+		}).Error
+		if err != nil {
+			return err
+		}
 
-	// 创建新记录
-	return g.db.WithContext(ctx).Create(&dailyProblem).Error
+		// 同时在 daily_problems 表中记录，先删除这个来源当天可能存在的记录
+		err = tx.Where("source = ? AND date = ?", source, date).Delete(&DailyProblem{}).Error
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		return tx.Create(&DailyProblem{
+			Date:       date,
+			Title:      problem.Title,
+			Difficulty: problem.Difficulty,
+			Tags:       problem.Tags,
+			Source:     source,
+			SourceId:   string(problem.SourceId),
+			SourceUrl:  problem.SourceUrl,
+			ProblemId:  problem.Id,
+			Ctime:      now,
+			Utime:      now,
+		}).Error
+	})
 }
 
 func (g *GormCodingProblemDAO) UpdateStudyStatus(ctx context.Context, problemId int64, status string, lastStudied *time.Time) error {
@@ -242,7 +304,7 @@ func (g *GormCodingProblemDAO) UpdateStudyStatus(ctx context.Context, problemId
 func (g *GormCodingProblemDAO) SaveDailyProblem(ctx context.Context, dailyProblem *domain.DailyProblem) error {
 	// 将domain.DailyProblem转换为dao.DailyProblem
 	daoDailyProblem := DailyProblem{
-		Date:       dailyProblem.Date.Format("2006-01-02"),
+		Date:       dailyProblem.Date,
 		Title:      dailyProblem.Title,
 		Difficulty: dailyProblem.Difficulty,
 		Tags:       StringSlice(dailyProblem.Tags),
@@ -253,9 +315,10 @@ func (g *GormCodingProblemDAO) SaveDailyProblem(ctx context.Context, dailyProble
 		Utime:      dailyProblem.Utime,
 	}
 
-	// 首先检查是否已存在相同日期的记录
+	// 首先检查这个来源是否已存在相同日期的记录
 	var existing DailyProblem
-	err := g.db.WithContext(ctx).Where("date = ?", daoDailyProblem.Date).First(&existing).Error
+	err := g.db.WithContext(ctx).Where("source = ? AND date = ?", daoDailyProblem.Source, daoDailyProblem.Date).
+		First(&existing).Error
 
 	if err == nil {
 		// 如果存在，更新记录
@@ -268,3 +331,48 @@ func (g *GormCodingProblemDAO) SaveDailyProblem(ctx context.Context, dailyProble
 
 	return err
 }
+
+func (g *GormCodingProblemDAO) FindDeleted(ctx context.Context) ([]CodingProblem, error) {
+	var problems []CodingProblem
+	err := g.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").Find(&problems).Error
+	return problems, err
+}
+
+func (g *GormCodingProblemDAO) Restore(ctx context.Context, id int64) error {
+	res := g.db.WithContext(ctx).Unscoped().Model(&CodingProblem{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"utime":      time.Now(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (g *GormCodingProblemDAO) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := g.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []int64
+		err := tx.Unscoped().Model(&CodingProblem{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		// daily_problems 通过外键引用题目，需要先删除
+		for _, model := range []interface{}{&DailyProblem{}, &CodingAttempt{}, &ProblemListItem{}} {
+			if err := tx.Where("problem_id IN ?", ids).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		res := tx.Unscoped().Where("id IN ?", ids).Delete(&CodingProblem{})
+		purged = res.RowsAffected
+		return res.Error
+	})
+	return purged, err
+}
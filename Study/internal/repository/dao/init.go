@@ -1,6 +1,8 @@
 package dao
 
 import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/pkg/textsim"
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
@@ -10,11 +12,232 @@ import (
 )
 
 func InitTables(db *gorm.DB) error {
-	err := db.AutoMigrate(&Question{}, &CodingProblem{}, &DailyProblem{})
+	// 唯一索引建立前先合并重复的题目
+	if err := dedupeCodingProblems(db); err != nil {
+		return err
+	}
+	err := db.AutoMigrate(&Question{}, &CodingProblem{}, &DailyProblem{}, &CodingAttempt{}, &ProblemList{}, &ProblemListItem{}, &ProblemSync{},
+		&ProblemEnrichment{}, &JobRun{}, &QuestionReview{}, &ReviewSession{}, &ReviewSessionItem{}, &Tag{}, &QuestionTag{}, &QuestionRevision{}, &Migration{})
 	if err != nil {
 		return err
 	}
 
+	if err := ensureQuestionFulltextIndex(db); err != nil {
+		return err
+	}
+	if err := migrateQuestionSchedules(db); err != nil {
+		return err
+	}
+	if err := migrateQuestionContentHash(db); err != nil {
+		return err
+	}
+	if err := migrateQuestionSimhash(db); err != nil {
+		return err
+	}
+	if err := migrateCategoryTags(db); err != nil {
+		return err
+	}
+	if err := migrateCodingReviews(db); err != nil {
+		return err
+	}
+	if err := migrateHot100List(db, domain.Hot100ListName); err != nil {
+		return err
+	}
+	if err := migrateCodingTitleSlug(db); err != nil {
+		return err
+	}
+	return migrateQuestionRevisions(db)
+}
+
+// runMigrationOnce 在事务中执行数据迁移并记录完成标记，已完成的迁移不再执行
+func runMigrationOnce(db *gorm.DB, name string, fn func(tx *gorm.DB) error) error {
+	var count int64
+	if err := db.Model(&Migration{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := fn(tx); err != nil {
+			return err
+		}
+		return tx.Create(&Migration{Name: name, Ctime: time.Now().UnixMilli()}).Error
+	})
+}
+
+// dedupeCodingProblems 建立来源和来源题号的唯一索引前，把重复导入的题目合并到编号最小的一题，
+// 和 Upsert 原来选中的题目一致；空的来源题号改为 NULL
+func dedupeCodingProblems(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&CodingProblem{}) || db.Migrator().HasIndex(&CodingProblem{}, "idx_coding_source_id") {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&CodingProblem{}).Where("source_id = ?", "").
+			Update("source_id", nil).Error
+		if err != nil {
+			return err
+		}
+
+		var groups []struct {
+			Source   string
+			SourceId string
+			KeepId   int64
+		}
+		err = tx.Unscoped().Model(&CodingProblem{}).
+			Select("source, source_id, MIN(id) AS keep_id").
+			Where("source_id IS NOT NULL").
+			Group("source, source_id").Having("COUNT(*) > 1").
+			Scan(&groups).Error
+		if err != nil {
+			return err
+		}
+		for _, group := range groups {
+			var ids []int64
+			err := tx.Unscoped().Model(&CodingProblem{}).
+				Where("source = ? AND source_id = ? AND id <> ?", group.Source, group.SourceId, group.KeepId).
+				Pluck("id", &ids).Error
+			if err != nil {
+				return err
+			}
+			if err := mergeCodingProblems(tx, group.KeepId, ids); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// mergeCodingProblems 把 mergeIds 的做题记录、每日一题和题单位置转到 keepId，然后彻底删除 mergeIds；
+// 在 AutoMigrate 之前执行，跳过还不存在的表
+func mergeCodingProblems(tx *gorm.DB, keepId int64, mergeIds []int64) error {
+	for _, model := range []interface{}{&CodingAttempt{}, &DailyProblem{}} {
+		if !tx.Migrator().HasTable(model) {
+			continue
+		}
+		err := tx.Model(model).Where("problem_id IN ?", mergeIds).Update("problem_id", keepId).Error
+		if err != nil {
+			return err
+		}
+	}
+	if tx.Migrator().HasTable(&ProblemListItem{}) {
+		for _, id := range mergeIds {
+			// 题单中已有保留的题目时删除重复的位置
+			var listIds []int64
+			err := tx.Model(&ProblemListItem{}).Where("problem_id = ?", keepId).Pluck("list_id", &listIds).Error
+			if err != nil {
+				return err
+			}
+			if len(listIds) > 0 {
+				err = tx.Where("problem_id = ? AND list_id IN ?", id, listIds).Delete(&ProblemListItem{}).Error
+				if err != nil {
+					return err
+				}
+			}
+			err = tx.Model(&ProblemListItem{}).Where("problem_id = ?", id).Update("problem_id", keepId).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if tx.Migrator().HasTable(&ProblemEnrichment{}) {
+		if err := tx.Where("problem_id IN ?", mergeIds).Delete(&ProblemEnrichment{}).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Unscoped().Where("id IN ?", mergeIds).Delete(&CodingProblem{}).Error
+}
+
+// migrateQuestionContentHash 为旧数据补齐内容指纹
+func migrateQuestionContentHash(db *gorm.DB) error {
+	var questions []Question
+	err := db.Select("id", "content").Where("content_hash = ?", "").Find(&questions).Error
+	if err != nil {
+		return err
+	}
+	for _, quest := range questions {
+		err = db.Model(&Question{}).Where("id = ?", quest.Id).
+			Update("content_hash", ContentHash(quest.Content)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateQuestionSimhash 为旧数据补齐 SimHash 指纹
+func migrateQuestionSimhash(db *gorm.DB) error {
+	var questions []Question
+	err := db.Select("id", "content").Where("simhash = ?", 0).Find(&questions).Error
+	if err != nil {
+		return err
+	}
+	for _, quest := range questions {
+		err = db.Model(&Question{}).Where("id = ?", quest.Id).
+			Update("simhash", int64(textsim.Simhash(quest.Content))).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateCodingReviews 已完成但还没有重做计划的题目从第一档开始，
+// 以最后学习时间为起点，没有学习时间的从现在开始
+func migrateCodingReviews(db *gorm.DB) error {
+	return db.Model(&CodingProblem{}).
+		Where("study_status = ? AND next_review_at IS NULL", domain.StudyCompleted).
+		Updates(map[string]interface{}{
+			"review_stage":   1,
+			"next_review_at": gorm.Expr("DATE_ADD(COALESCE(last_studied, ?), INTERVAL 1 DAY)", time.Now()),
+		}).Error
+}
+
+// migrateCodingTitleSlug 从 LeetCode 链接中解析旧数据的题目 slug，
+// 例如 https://leetcode.cn/problems/two-sum/ => two-sum
+func migrateCodingTitleSlug(db *gorm.DB) error {
+	return db.Unscoped().Model(&CodingProblem{}).
+		Where("title_slug = ? AND source = ? AND source_url LIKE ?", "", "leetcode", "%/problems/%").
+		Update("title_slug", gorm.Expr("SUBSTRING_INDEX(SUBSTRING_INDEX(source_url, '/problems/', -1), '/', 1)")).Error
+}
+
+// ensureQuestionFulltextIndex MySQL 下为题目内容和答案建立 ngram 全文索引，以支持中文检索
+func ensureQuestionFulltextIndex(db *gorm.DB) error {
+	if db.Dialector.Name() != "mysql" {
+		return nil
+	}
+	if db.Migrator().HasIndex(&Question{}, questionFulltextIndex) {
+		return nil
+	}
+	return db.Exec("CREATE FULLTEXT INDEX " + questionFulltextIndex +
+		" ON questions (content, answer) WITH PARSER ngram").Error
+}
+
+// migrateQuestionSchedules 为还没有复习计划的题目按原掌握度生成初始计划，避免已有题库被重置
+func migrateQuestionSchedules(db *gorm.DB) error {
+	now := time.Now()
+	schedules := []struct {
+		masteryLevel int
+		intervalDays int
+		repetitions  int
+	}{
+		{domain.MasteryUnlearned, 0, 0},
+		{domain.MasteryLearning, 1, 1},
+		{domain.MasteryMastered, 6, 2},
+	}
+	for _, s := range schedules {
+		err := db.Model(&Question{}).
+			Where("next_review_at = ? AND mastery_level = ?", 0, s.masteryLevel).
+			Updates(map[string]interface{}{
+				"ease_factor":    domain.DefaultEaseFactor,
+				"interval_days":  s.intervalDays,
+				"repetitions":    s.repetitions,
+				"next_review_at": now.AddDate(0, 0, s.intervalDays).UnixMilli(),
+			}).Error
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -42,22 +265,54 @@ func (s *StringSlice) Scan(value interface{}) error {
 	return json.Unmarshal(bytes, s)
 }
 
+// NullString 空字符串保存为 NULL，唯一索引不限制多个空值
+type NullString string
+
+func (s NullString) Value() (driver.Value, error) {
+	if s == "" {
+		return nil, nil
+	}
+	return string(s), nil
+}
+
+func (s *NullString) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*s = ""
+	case []byte:
+		*s = NullString(v)
+	case string:
+		*s = NullString(v)
+	default:
+		return errors.New("type assertion to string failed")
+	}
+	return nil
+}
+
 // CodingProblem 刷题问题数据库模型
 type CodingProblem struct {
-	Id             int64       `gorm:"primaryKey,autoIncrement" json:"id"`
-	Title          string      `gorm:"type:varchar(255);not null" json:"title"`
-	Difficulty     string      `gorm:"type:varchar(50)" json:"difficulty"`
-	Tags           StringSlice `gorm:"type:json" json:"tags"`
-	Source         string      `gorm:"type:varchar(100)" json:"source"`
-	SourceId       string      `gorm:"type:varchar(100)" json:"source_id"`
-	SourceUrl      string      `gorm:"type:varchar(500)" json:"source_url"`
-	StudyStatus    string      `gorm:"type:varchar(50);default:'not_started'" json:"study_status"` // 学习状态
-	LastStudied    *time.Time  `gorm:"type:datetime(3)" json:"last_studied"`                       // 最后学习时间
-	IsDailyProblem bool        `gorm:"type:boolean;default:false" json:"is_daily_problem"`         // 是否为每日一题
-	DailyDate      *time.Time  `gorm:"type:datetime(3)" json:"daily_date"`                         // 每日一题日期
-	IsHot100       bool        `gorm:"type:boolean;default:false" json:"is_hot100"`                // 是否为 Hot 100 题目
-	Ctime          time.Time   `gorm:"type:datetime(3)" json:"ctime"`
-	Utime          time.Time   `gorm:"type:datetime(3)" json:"utime"`
+	Id          int64       `gorm:"primaryKey,autoIncrement" json:"id"`
+	Title       string      `gorm:"type:varchar(255);not null" json:"title"`
+	Description string      `gorm:"type:mediumtext" json:"description"` // 题目描述(Markdown)
+	Difficulty  string      `gorm:"type:varchar(50)" json:"difficulty"`
+	Tags        StringSlice `gorm:"type:json" json:"tags"`
+	// Source 和 SourceId 有唯一索引，同一来源的题目只保存一份，手动添加的题目没有来源题号
+	Source         string            `gorm:"type:varchar(100);uniqueIndex:idx_coding_source_id,priority:1" json:"source"`
+	SourceId       NullString        `gorm:"type:varchar(100);uniqueIndex:idx_coding_source_id,priority:2" json:"source_id"`
+	SourceUrl      string            `gorm:"type:varchar(500)" json:"source_url"`
+	TitleSlug      string            `gorm:"type:varchar(255);not null;default:''" json:"title_slug"`    // LeetCode 题目 slug
+	PaidOnly       bool              `gorm:"not null;default:false" json:"paid_only"`                    // 是否会员题
+	AcRate         float64           `gorm:"not null;default:0" json:"ac_rate"`                          // 通过率(百分比)
+	StudyStatus    string            `gorm:"type:varchar(50);default:'not_started'" json:"study_status"` // 学习状态
+	LastStudied    *time.Time        `gorm:"type:datetime(3)" json:"last_studied"`                       // 最后学习时间
+	IsDailyProblem bool              `gorm:"type:boolean;default:false" json:"is_daily_problem"`         // 是否为每日一题
+	DailyDate      *domain.CivilDate `gorm:"type:date" json:"daily_date"`                                // 每日一题日期
+	ReviewStage    int               `gorm:"type:int;not null;default:0" json:"review_stage"`            // 连续做对的次数
+	NextReviewAt   *time.Time        `gorm:"type:datetime(3);index" json:"next_review_at"`               // 下次重做时间
+	Ctime          time.Time         `gorm:"type:datetime(3)" json:"ctime"`
+	Utime          time.Time         `gorm:"type:datetime(3)" json:"utime"`
+	// DeletedAt 软删除时间，删除的题目进入回收站
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 func (c CodingProblem) TableName() string {
@@ -66,33 +321,52 @@ func (c CodingProblem) TableName() string {
 
 // DailyProblem 每日一题模型
 type DailyProblem struct {
-	Id         int64         `gorm:"primarykey,autoIncrement"`
-	Date       string        `gorm:"column:date;unique;not null"` // 格式: 2024-06-17
-	Title      string        `gorm:"type:varchar(255);not null"`
-	Difficulty string        `gorm:"type:varchar(50)"`
-	Tags       StringSlice   `gorm:"type:json"`
-	Source     string        `gorm:"type:varchar(100)"`
-	SourceId   string        `gorm:"type:varchar(100)"`
-	SourceUrl  string        `gorm:"type:varchar(500)"`
-	ProblemId  int64         `gorm:"column:problem_id;not null"`
-	Problem    CodingProblem `gorm:"foreignKey:ProblemId"`
-	Ctime      time.Time     `gorm:"type:datetime(3)"`
-	Utime      time.Time     `gorm:"type:datetime(3)"`
+	Id int64 `gorm:"primarykey,autoIncrement"`
+	// Source 和 Date 确定一条记录，每个来源每天一题
+	Source     string           `gorm:"type:varchar(100);uniqueIndex:idx_daily_source_date,priority:1"`
+	Date       domain.CivilDate `gorm:"column:date;type:date;uniqueIndex:idx_daily_source_date,priority:2;not null"` // 来源站点时区中的日期
+	Title      string           `gorm:"type:varchar(255);not null"`
+	Difficulty string           `gorm:"type:varchar(50)"`
+	Tags       StringSlice      `gorm:"type:json"`
+	SourceId   string           `gorm:"type:varchar(100)"`
+	SourceUrl  string           `gorm:"type:varchar(500)"`
+	ProblemId  int64            `gorm:"column:problem_id;not null"`
+	Problem    CodingProblem    `gorm:"foreignKey:ProblemId"`
+	Ctime      time.Time        `gorm:"type:datetime(3)"`
+	Utime      time.Time        `gorm:"type:datetime(3)"`
 }
 
 func (d DailyProblem) TableName() string {
 	return "daily_problems"
 }
 
+// Migration 已完成的一次性数据迁移
+type Migration struct {
+	Name  string `gorm:"type:varchar(100);primaryKey"`
+	Ctime int64  `gorm:"type:bigint;not null"`
+}
+
+func (m Migration) TableName() string {
+	return "migrations"
+}
+
 type Question struct {
-	Id           int64  `gorm:"primary_key,autoIncrement"`
-	Category     string `gorm:"type:varchar(100);not null"`
-	Content      string `gorm:"type:text;not null"`
-	Answer       string `gorm:"type:text;not null"`
-	MasteryLevel int    `gorm:"type:int;default:0"` // 0: 未学习, 1: 学习中, 2: 已掌握
-	Count        int64  `gorm:"type:bigint;not null"`
-	Ctime        int64  `gorm:"type:bigint;not null"`
-	Utime        int64  `gorm:"type:bigint;not null"`
+	Id           int64   `gorm:"primary_key,autoIncrement"`
+	Category     string  `gorm:"type:varchar(100);not null"`
+	Content      string  `gorm:"type:text;not null"`
+	Answer       string  `gorm:"type:text;not null"`
+	ContentHash  string  `gorm:"type:char(64);not null;default:'';index"` // 归一化内容的 SHA-256，用于去重
+	Simhash      int64   `gorm:"type:bigint;not null;default:0"`          // 内容的 SimHash 指纹，用于发现近似重复
+	MasteryLevel int     `gorm:"type:int;default:0"`                      // 0: 未学习, 1: 学习中, 2: 已掌握
+	EaseFactor   float64 `gorm:"type:double;not null;default:2.5"`        // SM-2 难度因子
+	IntervalDays int     `gorm:"type:int;not null;default:0"`             // 复习间隔(天)
+	Repetitions  int     `gorm:"type:int;not null;default:0"`             // 连续答对次数
+	NextReviewAt int64   `gorm:"type:bigint;not null;default:0;index"`    // 下次复习时间(毫秒)
+	Count        int64   `gorm:"type:bigint;not null"`
+	Ctime        int64   `gorm:"type:bigint;not null"`
+	Utime        int64   `gorm:"type:bigint;not null"`
+	// DeletedAt 软删除时间，删除的题目进入回收站
+	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
 
 func (q Question) TableName() string {
@@ -0,0 +1,31 @@
+package dao
+
+import "testing"
+
+func TestNullString(t *testing.T) {
+	// 空字符串保存为 NULL，手动添加的题目不会违反来源题号的唯一索引
+	if v, err := NullString("").Value(); err != nil || v != nil {
+		t.Errorf("Value(\"\") = %v, %v, want nil", v, err)
+	}
+	if v, err := NullString("1").Value(); err != nil || v != "1" {
+		t.Errorf("Value(\"1\") = %v, %v, want 1", v, err)
+	}
+
+	for _, value := range []interface{}{nil, []byte("42"), "42"} {
+		s := NullString("old")
+		if err := s.Scan(value); err != nil {
+			t.Fatal(err)
+		}
+		want := NullString("42")
+		if value == nil {
+			want = ""
+		}
+		if s != want {
+			t.Errorf("Scan(%v) = %q, want %q", value, s, want)
+		}
+	}
+	var s NullString
+	if err := s.Scan(42); err == nil {
+		t.Error("Scan(int) should fail")
+	}
+}
@@ -0,0 +1,81 @@
+package dao
+
+import (
+	"Training/Study/internal/domain"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// JobRun 后台任务运行记录
+type JobRun struct {
+	Id         int64      `gorm:"primaryKey,autoIncrement"`
+	Job        string     `gorm:"type:varchar(100);not null;index:idx_job_started,priority:1"`
+	Trigger    string     `gorm:"type:varchar(20);not null"`
+	Attempt    int        `gorm:"not null;default:1"`
+	Status     string     `gorm:"type:varchar(20);not null;index"`
+	Error      string     `gorm:"type:text"`
+	StartedAt  time.Time  `gorm:"type:datetime(3);index:idx_job_started,priority:2"`
+	FinishedAt *time.Time `gorm:"type:datetime(3)"`
+}
+
+func (r JobRun) TableName() string {
+	return "job_runs"
+}
+
+type JobRunDao interface {
+	Insert(ctx context.Context, run JobRun) (int64, error)
+	Update(ctx context.Context, run JobRun) error
+	// FindByJob 任务最近的运行记录，按开始时间倒序
+	FindByJob(ctx context.Context, job string, limit int) ([]JobRun, error)
+	// FindLastByStatus 任务最近一次处于某个状态的运行
+	FindLastByStatus(ctx context.Context, job, status string) (JobRun, error)
+	// FailRunning 把所有运行中的记录标记为失败，用于启动时清理上次进程中断的运行
+	FailRunning(ctx context.Context, reason string) (int64, error)
+}
+
+type jobRunDao struct {
+	db *gorm.DB
+}
+
+func NewJobRunDao(db *gorm.DB) JobRunDao {
+	return &jobRunDao{db: db}
+}
+
+func (d *jobRunDao) Insert(ctx context.Context, run JobRun) (int64, error) {
+	err := d.db.WithContext(ctx).Create(&run).Error
+	return run.Id, err
+}
+
+func (d *jobRunDao) Update(ctx context.Context, run JobRun) error {
+	return d.db.WithContext(ctx).Model(&JobRun{}).Where("id = ?", run.Id).Updates(map[string]interface{}{
+		"status":      run.Status,
+		"error":       run.Error,
+		"finished_at": run.FinishedAt,
+	}).Error
+}
+
+func (d *jobRunDao) FindByJob(ctx context.Context, job string, limit int) ([]JobRun, error) {
+	var runs []JobRun
+	err := d.db.WithContext(ctx).Where("job = ?", job).
+		Order("started_at DESC, id DESC").Limit(limit).Find(&runs).Error
+	return runs, err
+}
+
+func (d *jobRunDao) FindLastByStatus(ctx context.Context, job, status string) (JobRun, error) {
+	var run JobRun
+	err := d.db.WithContext(ctx).Where("job = ? AND status = ?", job, status).
+		Order("started_at DESC, id DESC").First(&run).Error
+	return run, err
+}
+
+func (d *jobRunDao) FailRunning(ctx context.Context, reason string) (int64, error) {
+	res := d.db.WithContext(ctx).Model(&JobRun{}).Where("status = ?", domain.JobRunning).
+		Updates(map[string]interface{}{
+			"status":      domain.JobFailed,
+			"error":       reason,
+			"finished_at": time.Now(),
+		})
+	return res.RowsAffected, res.Error
+}
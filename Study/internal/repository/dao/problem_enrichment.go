@@ -0,0 +1,66 @@
+package dao
+
+import (
+	"Training/Study/internal/domain"
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProblemEnrichment 题目补全记录，每道题一条
+type ProblemEnrichment struct {
+	ProblemId     int64      `gorm:"primaryKey"`
+	Status        string     `gorm:"type:varchar(20);not null"`
+	Attempts      int        `gorm:"not null;default:0"`
+	Error         string     `gorm:"type:text"`
+	NextAttemptAt *time.Time `gorm:"type:datetime(3);index"`
+	Utime         time.Time  `gorm:"type:datetime(3)"`
+}
+
+func (e ProblemEnrichment) TableName() string {
+	return "problem_enrichments"
+}
+
+type ProblemEnrichmentDao interface {
+	// FindPending 查找需要补全的题目：没有描述、有 slug，并且从未补全过或者失败后已到重试时间，按 id 排序
+	FindPending(ctx context.Context, now time.Time, limit int) ([]CodingProblem, error)
+	FindByProblemId(ctx context.Context, problemId int64) (ProblemEnrichment, error)
+	// Save 保存补全记录，不存在时新增
+	Save(ctx context.Context, enrichment ProblemEnrichment) error
+}
+
+type problemEnrichmentDao struct {
+	db *gorm.DB
+}
+
+func NewProblemEnrichmentDao(db *gorm.DB) ProblemEnrichmentDao {
+	return &problemEnrichmentDao{db: db}
+}
+
+func (d *problemEnrichmentDao) FindPending(ctx context.Context, now time.Time, limit int) ([]CodingProblem, error) {
+	var problems []CodingProblem
+	err := d.db.WithContext(ctx).
+		Select("coding_problems.*").
+		Joins("LEFT JOIN problem_enrichments ON problem_enrichments.problem_id = coding_problems.id").
+		Where("(coding_problems.description IS NULL OR coding_problems.description = '')").
+		Where("coding_problems.title_slug <> ''").
+		Where("(problem_enrichments.problem_id IS NULL OR (problem_enrichments.status = ? AND problem_enrichments.next_attempt_at <= ?))",
+			domain.EnrichFailed, now).
+		Order("coding_problems.id ASC").
+		Limit(limit).
+		Find(&problems).Error
+	return problems, err
+}
+
+func (d *problemEnrichmentDao) FindByProblemId(ctx context.Context, problemId int64) (ProblemEnrichment, error) {
+	var enrichment ProblemEnrichment
+	err := d.db.WithContext(ctx).Where("problem_id = ?", problemId).First(&enrichment).Error
+	return enrichment, err
+}
+
+func (d *problemEnrichmentDao) Save(ctx context.Context, enrichment ProblemEnrichment) error {
+	enrichment.Utime = time.Now()
+	return d.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&enrichment).Error
+}
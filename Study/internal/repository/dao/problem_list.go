@@ -0,0 +1,374 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrProblemListExists = errors.New("problem list exists")
+
+// ProblemList 题单
+type ProblemList struct {
+	Id          int64  `gorm:"primaryKey,autoIncrement"`
+	Name        string `gorm:"type:varchar(100);not null;uniqueIndex"`
+	Description string `gorm:"type:varchar(500);not null;default:''"`
+	BuiltIn     bool   `gorm:"type:boolean;not null;default:false"`
+	// Source / SourceSlug 从外部导入的题单记录来源和原题单标识，重新导入时据此更新同一个题单
+	Source     string    `gorm:"type:varchar(50);not null;default:'';index:idx_list_source"`
+	SourceSlug string    `gorm:"type:varchar(255);not null;default:'';index:idx_list_source"`
+	Ctime      time.Time `gorm:"type:datetime(3)"`
+	Utime      time.Time `gorm:"type:datetime(3)"`
+}
+
+func (l ProblemList) TableName() string {
+	return "problem_lists"
+}
+
+// ProblemListItem 题单中的题目，Position 从 1 开始
+type ProblemListItem struct {
+	ListId    int64 `gorm:"primaryKey;autoIncrement:false"`
+	ProblemId int64 `gorm:"primaryKey;autoIncrement:false;index"`
+	Position  int   `gorm:"type:int;not null"`
+}
+
+func (i ProblemListItem) TableName() string {
+	return "problem_list_items"
+}
+
+// ProblemListStat 题单中某个学习状态和难度组合的题目数
+type ProblemListStat struct {
+	StudyStatus string
+	Difficulty  string
+	Cnt         int64
+}
+
+type ProblemListDao interface {
+	FindAll(ctx context.Context) ([]ProblemList, error)
+	FindById(ctx context.Context, id int64) (ProblemList, error)
+	FindByName(ctx context.Context, name string) (ProblemList, error)
+	// CountProblems 统计每个题单中的题目数
+	CountProblems(ctx context.Context) (map[int64]int64, error)
+	// Insert 创建题单，重名时返回 ErrProblemListExists
+	Insert(ctx context.Context, list ProblemList) (ProblemList, error)
+	// Update 修改名称和描述，重名时返回 ErrProblemListExists
+	Update(ctx context.Context, list ProblemList) error
+	// Delete 删除题单及其题目关联，题目本身不受影响
+	Delete(ctx context.Context, id int64) error
+	// FindProblems 按顺序查询题单中的题目，不含题目描述
+	FindProblems(ctx context.Context, listId int64) ([]CodingProblem, error)
+	// SetProblems 用给定顺序整体替换题单中的题目
+	SetProblems(ctx context.Context, listId int64, problemIds []int64) error
+	// AddProblems 把题目追加到题单末尾，已在题单中的题目保持原位置
+	AddProblems(ctx context.Context, listId int64, problemIds []int64) error
+	RemoveProblem(ctx context.Context, listId, problemId int64) error
+	// Stats 按学习状态和难度统计题单中的题目
+	Stats(ctx context.Context, listId int64) ([]ProblemListStat, error)
+	// SaveImported 按来源保存导入的题单：不存在时创建，存在时更新名称和描述，并按给定顺序替换题目
+	SaveImported(ctx context.Context, list ProblemList, problemIds []int64) (ProblemList, error)
+}
+
+type problemListDao struct {
+	db *gorm.DB
+}
+
+func NewProblemListDao(db *gorm.DB) ProblemListDao {
+	return &problemListDao{db: db}
+}
+
+func (dao *problemListDao) FindAll(ctx context.Context) ([]ProblemList, error) {
+	var lists []ProblemList
+	err := dao.db.WithContext(ctx).Order("built_in DESC, id ASC").Find(&lists).Error
+	return lists, err
+}
+
+func (dao *problemListDao) FindById(ctx context.Context, id int64) (ProblemList, error) {
+	var list ProblemList
+	err := dao.db.WithContext(ctx).Where("id = ?", id).First(&list).Error
+	return list, err
+}
+
+func (dao *problemListDao) FindByName(ctx context.Context, name string) (ProblemList, error) {
+	var list ProblemList
+	err := dao.db.WithContext(ctx).Where("name = ?", name).First(&list).Error
+	return list, err
+}
+
+func (dao *problemListDao) CountProblems(ctx context.Context) (map[int64]int64, error) {
+	var rows []struct {
+		ListId int64
+		Cnt    int64
+	}
+	err := dao.db.WithContext(ctx).Model(&ProblemListItem{}).
+		Select("problem_list_items.list_id, COUNT(*) AS cnt").
+		Joins("JOIN coding_problems ON coding_problems.id = problem_list_items.problem_id AND coding_problems.deleted_at IS NULL").
+		Group("problem_list_items.list_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		res[row.ListId] = row.Cnt
+	}
+	return res, nil
+}
+
+func (dao *problemListDao) Insert(ctx context.Context, list ProblemList) (ProblemList, error) {
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkListName(tx, list.Name, 0); err != nil {
+			return err
+		}
+		now := time.Now()
+		list.Ctime = now
+		list.Utime = now
+		return tx.Create(&list).Error
+	})
+	return list, err
+}
+
+func (dao *problemListDao) Update(ctx context.Context, list ProblemList) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", list.Id).First(&ProblemList{}).Error; err != nil {
+			return err
+		}
+		if err := checkListName(tx, list.Name, list.Id); err != nil {
+			return err
+		}
+		return tx.Model(&ProblemList{}).Where("id = ?", list.Id).
+			Updates(map[string]interface{}{
+				"name":        list.Name,
+				"description": list.Description,
+				"utime":       time.Now(),
+			}).Error
+	})
+}
+
+// checkListName 检查除 exceptId 外是否已有同名题单
+func checkListName(tx *gorm.DB, name string, exceptId int64) error {
+	var cnt int64
+	err := tx.Model(&ProblemList{}).Where("name = ? AND id <> ?", name, exceptId).Count(&cnt).Error
+	if err != nil {
+		return err
+	}
+	if cnt > 0 {
+		return ErrProblemListExists
+	}
+	return nil
+}
+
+func (dao *problemListDao) Delete(ctx context.Context, id int64) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("list_id = ?", id).Delete(&ProblemListItem{}).Error; err != nil {
+			return err
+		}
+		res := tx.Where("id = ?", id).Delete(&ProblemList{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (dao *problemListDao) FindProblems(ctx context.Context, listId int64) ([]CodingProblem, error) {
+	var problems []CodingProblem
+	err := dao.db.WithContext(ctx).Omit("description").
+		Joins("JOIN problem_list_items ON problem_list_items.problem_id = coding_problems.id").
+		Where("problem_list_items.list_id = ?", listId).
+		Order("problem_list_items.position ASC").
+		Find(&problems).Error
+	return problems, err
+}
+
+func (dao *problemListDao) SetProblems(ctx context.Context, listId int64, problemIds []int64) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkListProblems(tx, listId, problemIds); err != nil {
+			return err
+		}
+		if err := tx.Where("list_id = ?", listId).Delete(&ProblemListItem{}).Error; err != nil {
+			return err
+		}
+		if err := appendListItems(tx, listId, problemIds, 0); err != nil {
+			return err
+		}
+		return touchList(tx, listId)
+	})
+}
+
+func (dao *problemListDao) AddProblems(ctx context.Context, listId int64, problemIds []int64) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkListProblems(tx, listId, problemIds); err != nil {
+			return err
+		}
+		var last int
+		err := tx.Model(&ProblemListItem{}).Where("list_id = ?", listId).
+			Select("COALESCE(MAX(position), 0)").Scan(&last).Error
+		if err != nil {
+			return err
+		}
+		if err := appendListItems(tx, listId, problemIds, last); err != nil {
+			return err
+		}
+		return touchList(tx, listId)
+	})
+}
+
+func (dao *problemListDao) RemoveProblem(ctx context.Context, listId, problemId int64) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("list_id = ? AND problem_id = ?", listId, problemId).Delete(&ProblemListItem{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return touchList(tx, listId)
+	})
+}
+
+// checkListProblems 确认题单和全部题目都存在
+func checkListProblems(tx *gorm.DB, listId int64, problemIds []int64) error {
+	if err := tx.Where("id = ?", listId).First(&ProblemList{}).Error; err != nil {
+		return err
+	}
+	if len(problemIds) == 0 {
+		return nil
+	}
+	unique := make(map[int64]struct{}, len(problemIds))
+	for _, id := range problemIds {
+		unique[id] = struct{}{}
+	}
+	var cnt int64
+	if err := tx.Model(&CodingProblem{}).Where("id IN ?", problemIds).Count(&cnt).Error; err != nil {
+		return err
+	}
+	if cnt != int64(len(unique)) {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// appendListItems 从 last+1 开始依次追加题目，重复或已在题单中的题目跳过
+func appendListItems(tx *gorm.DB, listId int64, problemIds []int64, last int) error {
+	items := listItems(listId, problemIds, last)
+	if len(items) == 0 {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(items, 500).Error
+}
+
+// listItems 按 problemIds 的顺序生成题单项，位置从 last+1 开始连续编号，重复的题目只保留第一次出现
+func listItems(listId int64, problemIds []int64, last int) []ProblemListItem {
+	seen := make(map[int64]struct{}, len(problemIds))
+	items := make([]ProblemListItem, 0, len(problemIds))
+	for _, id := range problemIds {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		last++
+		items = append(items, ProblemListItem{ListId: listId, ProblemId: id, Position: last})
+	}
+	return items
+}
+
+func touchList(tx *gorm.DB, listId int64) error {
+	return tx.Model(&ProblemList{}).Where("id = ?", listId).Update("utime", time.Now()).Error
+}
+
+func (dao *problemListDao) Stats(ctx context.Context, listId int64) ([]ProblemListStat, error) {
+	var stats []ProblemListStat
+	err := dao.db.WithContext(ctx).Model(&CodingProblem{}).
+		Select("coding_problems.study_status, coding_problems.difficulty, COUNT(*) AS cnt").
+		Joins("JOIN problem_list_items ON problem_list_items.problem_id = coding_problems.id").
+		Where("problem_list_items.list_id = ?", listId).
+		Group("coding_problems.study_status, coding_problems.difficulty").
+		Scan(&stats).Error
+	return stats, err
+}
+
+func (dao *problemListDao) SaveImported(ctx context.Context, list ProblemList, problemIds []int64) (ProblemList, error) {
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing ProblemList
+		err := tx.Where("source = ? AND source_slug = ?", list.Source, list.SourceSlug).First(&existing).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := checkListName(tx, list.Name, 0); err != nil {
+				return err
+			}
+			now := time.Now()
+			list.Ctime = now
+			list.Utime = now
+			if err := tx.Create(&list).Error; err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		default:
+			if err := checkListName(tx, list.Name, existing.Id); err != nil {
+				return err
+			}
+			existing.Name = list.Name
+			existing.Description = list.Description
+			existing.Utime = time.Now()
+			if err := tx.Select("name", "description", "utime").Updates(&existing).Error; err != nil {
+				return err
+			}
+			list = existing
+		}
+
+		if err := tx.Where("list_id = ?", list.Id).Delete(&ProblemListItem{}).Error; err != nil {
+			return err
+		}
+		return appendListItems(tx, list.Id, problemIds, 0)
+	})
+	return list, err
+}
+
+// migrateHot100List 创建内置的 Hot 100 题单，并把旧的 is_hot100 标记迁移为题单成员，
+// 迁移完成后删除 is_hot100 列
+func migrateHot100List(db *gorm.DB, name string) error {
+	now := time.Now()
+	list := ProblemList{
+		Name:        name,
+		Description: "LeetCode 热题 100",
+		BuiltIn:     true,
+		Ctime:       now,
+		Utime:       now,
+	}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&list).Error; err != nil {
+		return err
+	}
+	if err := db.Where("name = ?", name).First(&list).Error; err != nil {
+		return err
+	}
+
+	if !db.Migrator().HasColumn(&CodingProblem{}, "is_hot100") {
+		return nil
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var ids []int64
+		err := tx.Unscoped().Model(&CodingProblem{}).Where("is_hot100 = ?", true).
+			Order("CAST(source_id AS UNSIGNED) ASC, id ASC").Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+		var last int
+		err = tx.Model(&ProblemListItem{}).Where("list_id = ?", list.Id).
+			Select("COALESCE(MAX(position), 0)").Scan(&last).Error
+		if err != nil {
+			return err
+		}
+		return appendListItems(tx, list.Id, ids, last)
+	})
+	if err != nil {
+		return err
+	}
+	return db.Migrator().DropColumn(&CodingProblem{}, "is_hot100")
+}
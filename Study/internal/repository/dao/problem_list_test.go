@@ -0,0 +1,28 @@
+package dao
+
+import "testing"
+
+func TestListItems(t *testing.T) {
+	items := listItems(7, []int64{30, 10, 20, 10, 40}, 2)
+
+	want := []ProblemListItem{
+		{ListId: 7, ProblemId: 30, Position: 3},
+		{ListId: 7, ProblemId: 10, Position: 4},
+		{ListId: 7, ProblemId: 20, Position: 5},
+		{ListId: 7, ProblemId: 40, Position: 6},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("got %d items, want %d: %+v", len(items), len(want), items)
+	}
+	for i := range want {
+		if items[i] != want[i] {
+			t.Errorf("item %d = %+v, want %+v", i, items[i], want[i])
+		}
+	}
+}
+
+func TestListItemsEmpty(t *testing.T) {
+	if items := listItems(1, nil, 0); len(items) != 0 {
+		t.Errorf("got %+v, want no items", items)
+	}
+}
@@ -0,0 +1,61 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProblemSync 题库同步的断点，每个题目来源一条
+type ProblemSync struct {
+	Source     string     `gorm:"type:varchar(100);primaryKey"`
+	Status     string     `gorm:"type:varchar(20);not null"`
+	Offset     int        `gorm:"column:sync_offset;not null;default:0"` // offset 是 MySQL 关键字
+	Total      int        `gorm:"not null;default:0"`
+	Created    int        `gorm:"not null;default:0"`
+	Updated    int        `gorm:"not null;default:0"`
+	Skipped    int        `gorm:"not null;default:0"`
+	Error      string     `gorm:"type:text"`
+	StartedAt  *time.Time `gorm:"type:datetime(3)"`
+	FinishedAt *time.Time `gorm:"type:datetime(3)"`
+	Utime      time.Time  `gorm:"type:datetime(3)"`
+}
+
+func (s ProblemSync) TableName() string {
+	return "problem_syncs"
+}
+
+type ProblemSyncDao interface {
+	FindBySource(ctx context.Context, source string) (ProblemSync, error)
+	// FindByStatus 查询处于某个状态的同步，用于重启后继续
+	FindByStatus(ctx context.Context, status string) ([]ProblemSync, error)
+	// Save 保存同步进度，不存在时新增
+	Save(ctx context.Context, sync ProblemSync) error
+}
+
+type problemSyncDao struct {
+	db *gorm.DB
+}
+
+func NewProblemSyncDao(db *gorm.DB) ProblemSyncDao {
+	return &problemSyncDao{db: db}
+}
+
+func (d *problemSyncDao) FindBySource(ctx context.Context, source string) (ProblemSync, error) {
+	var sync ProblemSync
+	err := d.db.WithContext(ctx).Where("source = ?", source).First(&sync).Error
+	return sync, err
+}
+
+func (d *problemSyncDao) FindByStatus(ctx context.Context, status string) ([]ProblemSync, error) {
+	var syncs []ProblemSync
+	err := d.db.WithContext(ctx).Where("status = ?", status).Find(&syncs).Error
+	return syncs, err
+}
+
+func (d *problemSyncDao) Save(ctx context.Context, sync ProblemSync) error {
+	sync.Utime = time.Now()
+	return d.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&sync).Error
+}
@@ -1,22 +1,57 @@
 package dao
 
 import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/pkg/textsim"
 	"context"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var ErrRecordNotFound = gorm.ErrRecordNotFound
+
 type QuestDao interface {
 	Insert(ctx context.Context, quest Question) error
+	FindById(ctx context.Context, id int64) (Question, error)
 	FindByCategory(ctx context.Context, category string) ([]Question, error)
 	FindAll(ctx context.Context) ([]Question, error)
-	UpdateById(ctx context.Context, quest Question) error
+	FindPage(ctx context.Context, q QuestionQuery) ([]Question, int64, error)
+	FindByIds(ctx context.Context, ids []int64) ([]Question, error)
+	FindDue(ctx context.Context, category string, now int64, limit int) ([]Question, error)
+	// UpdateById 修改题目，并保存一个新的修订版本；restoredFrom 为 0 表示普通修改
+	UpdateById(ctx context.Context, quest Question, author string, restoredFrom int) error
 	UpdateMasteryLevel(ctx context.Context, id int64, masteryLevel int) error
+	// UpdateSchedule 锁定题目，用 schedule 根据题目当前状态算出的复习计划更新题目，并记录一条评分事件，
+	// 返回更新后的题目
+	UpdateSchedule(ctx context.Context, id int64, schedule func(quest Question) Question, review QuestionReview) (Question, error)
 	DeleteById(ctx context.Context, id int64) error
-	DeleteByCategory(ctx context.Context, category string) error
+	// DeleteByCategory 删除分类及其子分类下的题目和对应标签，返回删除的题目数
+	DeleteByCategory(ctx context.Context, category string) (int64, error)
+	// CountByCategory 统计分类及其子分类下的题目数
+	CountByCategory(ctx context.Context, category string) (int64, error)
+	// MoveCategory 将分类及其子分类整体移动到 to 下，to 已存在时即为合并，返回涉及的题目数
+	MoveCategory(ctx context.Context, from, to string) (int64, error)
+	// FindDeleted 查询回收站中的题目，按删除时间倒序
+	FindDeleted(ctx context.Context) ([]Question, error)
+	// Restore 从回收站恢复题目
+	Restore(ctx context.Context, id int64) error
+	// PurgeDeleted 彻底删除 before 之前删除的题目及其关联数据，返回删除的题目数
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 	FindAllCategories(ctx context.Context) ([]string, error)
 	GetMasteryStats(ctx context.Context) (map[string]int, error)
+	Search(ctx context.Context, keyword string, limit int) ([]QuestionSearchHit, error)
+	// Import 在一个事务中按内容指纹导入题目，dryRun 时回滚事务只返回结果
+	Import(ctx context.Context, quests []Question, overwrite bool, dryRun bool) ([]ImportOutcome, error)
+	FindReviews(ctx context.Context, questionId int64, limit int) ([]QuestionReview, error)
+	FindRecentReviews(ctx context.Context, before int64, limit int) ([]QuestionReview, error)
+	FindRevisions(ctx context.Context, questionId int64) ([]QuestionRevision, error)
+	FindRevision(ctx context.Context, questionId int64, rev int) (QuestionRevision, error)
+	// FindFingerprints 返回全部题目的 SimHash 指纹
+	FindFingerprints(ctx context.Context) ([]QuestionFingerprint, error)
+	// Merge 将 mergeIds 合并到 keepId，迁移复习记录和标签后删除被合并的题目
+	Merge(ctx context.Context, keepId int64, mergeIds []int64) error
 }
 
 type questionDao struct {
@@ -27,17 +62,45 @@ func NewQuestionDao(db *gorm.DB) QuestDao {
 	return &questionDao{db: db}
 }
 
+// Insert 创建题目，并关联分类对应的标签
 func (dao *questionDao) Insert(ctx context.Context, quest Question) error {
 	now := time.Now()
+	dao.prepareInsert(&quest, now)
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&quest).Error; err != nil {
+			return err
+		}
+		if err := saveRevision(tx, quest.Id, "", 0, now.Unix()); err != nil {
+			return err
+		}
+		return relinkCategory(tx, quest.Id, "", quest.Category, now.UnixMilli())
+	})
+}
+
+// prepareInsert 填充新题目的时间、内容指纹和初始复习计划
+func (dao *questionDao) prepareInsert(quest *Question, now time.Time) {
 	quest.Ctime = now.Unix()
 	quest.Utime = now.Unix()
-	err := dao.db.WithContext(ctx).Create(&quest).Error
-	return err
+	quest.ContentHash = ContentHash(quest.Content)
+	quest.Simhash = int64(textsim.Simhash(quest.Content))
+	// 新题目立即进入复习队列
+	if quest.EaseFactor == 0 {
+		quest.EaseFactor = domain.DefaultEaseFactor
+	}
+	if quest.NextReviewAt == 0 {
+		quest.NextReviewAt = now.UnixMilli()
+	}
+}
+
+func (dao *questionDao) FindById(ctx context.Context, id int64) (Question, error) {
+	var quest Question
+	err := dao.db.WithContext(ctx).Where("id = ?", id).First(&quest).Error
+	return quest, err
 }
 
 func (dao *questionDao) FindByCategory(ctx context.Context, category string) ([]Question, error) {
 	var questions []Question
-	err := dao.db.WithContext(ctx).Where("category = ?", category).Find(&questions).Error
+	err := dao.db.WithContext(ctx).Scopes(categoryScope(category)).Find(&questions).Error
 	return questions, err
 }
 
@@ -47,32 +110,132 @@ func (dao *questionDao) FindAll(ctx context.Context) ([]Question, error) {
 	return questions, err
 }
 
-func (dao *questionDao) UpdateById(ctx context.Context, quest Question) error {
+func (dao *questionDao) FindByIds(ctx context.Context, ids []int64) ([]Question, error) {
+	var questions []Question
+	if len(ids) == 0 {
+		return questions, nil
+	}
+	err := dao.db.WithContext(ctx).Where("id IN ?", ids).Find(&questions).Error
+	return questions, err
+}
+
+// FindDue 查找到期需要复习的题目，按到期时间先后排序
+func (dao *questionDao) FindDue(ctx context.Context, category string, now int64, limit int) ([]Question, error) {
+	var questions []Question
+	query := dao.db.WithContext(ctx).Where("next_review_at <= ?", now)
+	if category != "" {
+		query = query.Scopes(categoryScope(category))
+	}
+	err := query.Order("next_review_at ASC, id ASC").Limit(limit).Find(&questions).Error
+	return questions, err
+}
+
+func (dao *questionDao) UpdateById(ctx context.Context, quest Question, author string, restoredFrom int) error {
 	now := time.Now()
 	quest.Utime = now.Unix()
-	err := dao.db.WithContext(ctx).Where("id = ?", quest.Id).Updates(&quest).Error
-	return err
+	if quest.Content != "" {
+		quest.ContentHash = ContentHash(quest.Content)
+		quest.Simhash = int64(textsim.Simhash(quest.Content))
+	}
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var old Question
+		if err := tx.Where("id = ?", quest.Id).First(&old).Error; err != nil {
+			return err
+		}
+		// 更新不会修改创建时间，调用方传入的 Ctime 可能是零值时间换算出的负数
+		if err := tx.Where("id = ?", quest.Id).Omit("ctime").Updates(&quest).Error; err != nil {
+			return err
+		}
+		if err := saveRevision(tx, quest.Id, author, restoredFrom, now.Unix()); err != nil {
+			return err
+		}
+		if quest.Category == "" || quest.Category == old.Category {
+			return nil
+		}
+		return relinkCategory(tx, quest.Id, old.Category, quest.Category, now.UnixMilli())
+	})
 }
 
+// UpdateMasteryLevel 手动修改掌握度，同时记录一条掌握度变更事件
 func (dao *questionDao) UpdateMasteryLevel(ctx context.Context, id int64, masteryLevel int) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var quest Question
+		if err := tx.Where("id = ?", id).First(&quest).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		err := tx.Model(&Question{}).
+			Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"mastery_level": masteryLevel,
+				"count":         gorm.Expr("count + 1"),
+				"utime":         now.Unix(),
+			}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&QuestionReview{
+			QuestionId:    id,
+			Kind:          domain.QuestionReviewMastery,
+			MasteryBefore: quest.MasteryLevel,
+			MasteryAfter:  masteryLevel,
+			Ctime:         now.UnixMilli(),
+		}).Error
+	})
+}
+
+// UpdateSchedule 更新复习计划，同时同步掌握度
+func (dao *questionDao) UpdateSchedule(ctx context.Context, id int64, schedule func(quest Question) Question,
+	review QuestionReview) (Question, error) {
+	var quest Question
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		_, quest, err = updateSchedule(tx, id, schedule, review)
+		return err
+	})
+	return quest, err
+}
+
+// updateSchedule 在事务中锁定题目，按 schedule 的结果更新复习计划并记录评分事件，
+// 返回更新前后的题目；题目不存在时返回 ErrRecordNotFound。
+// 复习计划必须基于锁定后读到的状态计算，否则并发的评分会互相覆盖
+func updateSchedule(tx *gorm.DB, id int64, schedule func(quest Question) Question,
+	review QuestionReview) (Question, Question, error) {
+	var old Question
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).First(&old).Error
+	if err != nil {
+		return Question{}, Question{}, err
+	}
+	quest := schedule(old)
 	now := time.Now()
-	err := dao.db.WithContext(ctx).Model(&Question{}).
+	err = tx.Model(&Question{}).
 		Where("id = ?", id).
 		Updates(map[string]interface{}{
-			"mastery_level": masteryLevel,
-			"utime":         now.Unix(),
+			"mastery_level":  quest.MasteryLevel,
+			"ease_factor":    quest.EaseFactor,
+			"interval_days":  quest.IntervalDays,
+			"repetitions":    quest.Repetitions,
+			"next_review_at": quest.NextReviewAt,
+			"count":          gorm.Expr("count + 1"),
+			"utime":          now.Unix(),
 		}).Error
-	return err
+	if err != nil {
+		return Question{}, Question{}, err
+	}
+	quest.Count = old.Count + 1
+	quest.Utime = now.Unix()
+	review.QuestionId = id
+	review.Kind = domain.QuestionReviewGrade
+	review.MasteryBefore = old.MasteryLevel
+	review.MasteryAfter = quest.MasteryLevel
+	review.Ctime = now.UnixMilli()
+	return old, quest, tx.Create(&review).Error
 }
 
+// DeleteById 软删除题目，标签和修订记录保留，以便从回收站恢复
 func (dao *questionDao) DeleteById(ctx context.Context, id int64) error {
-	err := dao.db.WithContext(ctx).Where("id = ?", id).Delete(&Question{}).Error
-	return err
-}
-
-func (dao *questionDao) DeleteByCategory(ctx context.Context, category string) error {
-	err := dao.db.WithContext(ctx).Where("category = ?", category).Delete(&Question{}).Error
-	return err
+	return dao.db.WithContext(ctx).Where("id = ?", id).Delete(&Question{}).Error
 }
 
 func (dao *questionDao) FindAllCategories(ctx context.Context) ([]string, error) {
@@ -0,0 +1,147 @@
+package dao
+
+import (
+	"Training/Study/internal/domain"
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// categoryAuthor 重命名或合并分类产生的修订记录的作者
+const categoryAuthor = "category"
+
+// movedPath 把 from 子树中的路径移动到 to 下。保存的路径可能没有归一化，
+// 大小写也可能与 from 不同（数据库比较不区分大小写），因此先归一化，再按层级数替换前缀
+func movedPath(path, from, to string) string {
+	parts := strings.Split(domain.NormalizeTagPath(path), domain.TagPathSeparator)
+	n := len(strings.Split(domain.NormalizeTagPath(from), domain.TagPathSeparator))
+	if len(parts) <= n {
+		return to
+	}
+	return to + domain.TagPathSeparator + strings.Join(parts[n:], domain.TagPathSeparator)
+}
+
+// categorySubtree 题目的分类等于 category 或是它的子分类
+func categorySubtree(category string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("questions.category = ? OR questions.category LIKE ?",
+			category, escapeLike(category)+domain.TagPathSeparator+"%")
+	}
+}
+
+// tagSubtree 路径等于 path 或是它的子路径的标签
+func tagSubtree(path string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("tags.path = ? OR tags.path LIKE ?", path, escapeLike(path)+domain.TagPathSeparator+"%")
+	}
+}
+
+func (dao *questionDao) CountByCategory(ctx context.Context, category string) (int64, error) {
+	var count int64
+	err := dao.db.WithContext(ctx).Model(&Question{}).Scopes(categorySubtree(category)).Count(&count).Error
+	return count, err
+}
+
+func (dao *questionDao) DeleteByCategory(ctx context.Context, category string) (int64, error) {
+	var deleted int64
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []int64
+		if err := tx.Model(&Question{}).Scopes(categorySubtree(category)).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) > 0 {
+			// 题目进入回收站，恢复时会重新关联分类标签
+			res := tx.Where("id IN ?", ids).Delete(&Question{})
+			if res.Error != nil {
+				return res.Error
+			}
+			deleted = res.RowsAffected
+		}
+		// 其他分类的题目上挂的该分类标签一并解除
+		tagIds := tx.Model(&Tag{}).Select("id").Scopes(tagSubtree(domain.NormalizeTagPath(category)))
+		if err := tx.Where("tag_id IN (?)", tagIds).Delete(&QuestionTag{}).Error; err != nil {
+			return err
+		}
+		return tx.Scopes(tagSubtree(domain.NormalizeTagPath(category))).Delete(&Tag{}).Error
+	})
+	return deleted, err
+}
+
+func (dao *questionDao) MoveCategory(ctx context.Context, from, to string) (int64, error) {
+	var moved int64
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		// 逐个子分类改名，保留子分类的层级
+		var categories []string
+		err := tx.Model(&Question{}).Scopes(categorySubtree(from)).
+			Distinct("category").Pluck("category", &categories).Error
+		if err != nil {
+			return err
+		}
+		for _, category := range categories {
+			var ids []int64
+			if err := tx.Model(&Question{}).Where("category = ?", category).Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				continue
+			}
+			res := tx.Model(&Question{}).Where("id IN ?", ids).
+				Updates(map[string]interface{}{
+					"category": movedPath(category, from, to),
+					"utime":    now.Unix(),
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+			moved += res.RowsAffected
+			for _, id := range ids {
+				if err := saveRevision(tx, id, categoryAuthor, 0, now.Unix()); err != nil {
+					return err
+				}
+			}
+		}
+
+		// 标签按路径逐个迁移到新路径，新路径已存在时合并关联
+		fromPath, toPath := domain.NormalizeTagPath(from), domain.NormalizeTagPath(to)
+		var tags []Tag
+		if err := tx.Scopes(tagSubtree(fromPath)).Order("path ASC").Find(&tags).Error; err != nil {
+			return err
+		}
+		for _, tag := range tags {
+			target, err := ensureTagPath(tx, movedPath(tag.Path, fromPath, toPath), now.UnixMilli())
+			if err != nil {
+				return err
+			}
+			var questionIds []int64
+			err = tx.Model(&QuestionTag{}).Where("tag_id = ?", tag.Id).Pluck("question_id", &questionIds).Error
+			if err != nil {
+				return err
+			}
+			if len(questionIds) > 0 {
+				links := make([]QuestionTag, 0, len(questionIds))
+				for _, id := range questionIds {
+					links = append(links, QuestionTag{QuestionId: id, TagId: target.Id})
+				}
+				if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Where("tag_id = ?", tag.Id).Delete(&QuestionTag{}).Error; err != nil {
+				return err
+			}
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+		ids := make([]int64, 0, len(tags))
+		for _, tag := range tags {
+			ids = append(ids, tag.Id)
+		}
+		return tx.Where("id IN ?", ids).Delete(&Tag{}).Error
+	})
+	return moved, err
+}
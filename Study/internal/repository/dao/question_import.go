@@ -0,0 +1,103 @@
+package dao
+
+import (
+	"Training/Study/internal/domain"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// errDryRun 用于在预演模式下回滚事务
+var errDryRun = errors.New("dry run")
+
+// importAuthor 批量导入产生的修订记录的作者
+const importAuthor = "import"
+
+// ImportOutcome 单条题目的导入结果
+type ImportOutcome struct {
+	Id     int64
+	Status string // created, updated, skipped, trashed
+}
+
+// ContentHash 计算归一化后题目内容的指纹：去掉首尾空白、合并连续空白并转为小写
+func ContentHash(content string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(content), " "))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func (dao *questionDao) Import(ctx context.Context, quests []Question, overwrite bool, dryRun bool) ([]ImportOutcome, error) {
+	outcomes := make([]ImportOutcome, 0, len(quests))
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		for _, quest := range quests {
+			outcome, err := dao.importOne(tx, quest, overwrite, now)
+			if err != nil {
+				return err
+			}
+			outcomes = append(outcomes, outcome)
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return outcomes, nil
+}
+
+func (dao *questionDao) importOne(tx *gorm.DB, quest Question, overwrite bool, now time.Time) (ImportOutcome, error) {
+	// 回收站中的题目也参与去重，否则恢复后会出现两道相同的题目；同时存在时优先匹配未删除的
+	var existing Question
+	err := tx.Unscoped().Where("content_hash = ?", ContentHash(quest.Content)).
+		Order("deleted_at IS NOT NULL, id").First(&existing).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		dao.prepareInsert(&quest, now)
+		if err := tx.Create(&quest).Error; err != nil {
+			return ImportOutcome{}, err
+		}
+		if err := saveRevision(tx, quest.Id, importAuthor, 0, now.Unix()); err != nil {
+			return ImportOutcome{}, err
+		}
+		if err := relinkCategory(tx, quest.Id, "", quest.Category, now.UnixMilli()); err != nil {
+			return ImportOutcome{}, err
+		}
+		return ImportOutcome{Id: quest.Id, Status: domain.ImportCreated}, nil
+	case err != nil:
+		return ImportOutcome{}, err
+	}
+
+	if existing.DeletedAt.Valid {
+		return ImportOutcome{Id: existing.Id, Status: domain.ImportTrashed}, nil
+	}
+	if !overwrite || (existing.Answer == quest.Answer && existing.Category == quest.Category) {
+		return ImportOutcome{Id: existing.Id, Status: domain.ImportSkipped}, nil
+	}
+	err = tx.Model(&Question{}).Where("id = ?", existing.Id).
+		Updates(map[string]interface{}{
+			"answer":   quest.Answer,
+			"category": quest.Category,
+			"utime":    now.Unix(),
+		}).Error
+	if err != nil {
+		return ImportOutcome{}, err
+	}
+	if err := saveRevision(tx, existing.Id, importAuthor, 0, now.Unix()); err != nil {
+		return ImportOutcome{}, err
+	}
+	if err := relinkCategory(tx, existing.Id, existing.Category, quest.Category, now.UnixMilli()); err != nil {
+		return ImportOutcome{}, err
+	}
+	return ImportOutcome{Id: existing.Id, Status: domain.ImportUpdated}, nil
+}
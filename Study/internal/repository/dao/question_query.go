@@ -0,0 +1,105 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// questionSortColumns 允许排序的列，均为整数列，便于用作游标
+var questionSortColumns = map[string]bool{
+	"id":             true,
+	"ctime":          true,
+	"utime":          true,
+	"mastery_level":  true,
+	"next_review_at": true,
+	"count":          true,
+}
+
+// QuestionQuery 题目列表查询条件
+type QuestionQuery struct {
+	Category     string
+	MasteryLevel *int
+	// UpdatedSince 秒级时间戳，0 表示不限
+	UpdatedSince int64
+	Keyword      string
+	SortColumn   string
+	Desc         bool
+	// AfterValue、AfterId 上一页最后一条记录的排序列取值和ID，AfterId 为 0 表示第一页
+	AfterValue int64
+	AfterId    int64
+	Limit      int
+}
+
+// SortValue 取出题目在排序列上的值，用于生成下一页游标
+func (q QuestionQuery) SortValue(quest Question) int64 {
+	switch q.SortColumn {
+	case "ctime":
+		return quest.Ctime
+	case "utime":
+		return quest.Utime
+	case "mastery_level":
+		return int64(quest.MasteryLevel)
+	case "next_review_at":
+		return quest.NextReviewAt
+	case "count":
+		return quest.Count
+	default:
+		return quest.Id
+	}
+}
+
+// FindPage 按条件分页查询题目，使用 (排序列, id) 作为游标，返回本页数据和满足条件的总数
+func (dao *questionDao) FindPage(ctx context.Context, q QuestionQuery) ([]Question, int64, error) {
+	column := q.SortColumn
+	if !questionSortColumns[column] {
+		return nil, 0, fmt.Errorf("unsupported sort column %q", column)
+	}
+
+	filtered := dao.db.WithContext(ctx).Model(&Question{}).Scopes(questionFilter(q))
+	var total int64
+	if err := filtered.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	order, cmp := "ASC", ">"
+	if q.Desc {
+		order, cmp = "DESC", "<"
+	}
+	query := filtered.Session(&gorm.Session{})
+	if q.AfterId > 0 {
+		if column == "id" {
+			query = query.Where("questions.id "+cmp+" ?", q.AfterId)
+		} else {
+			query = query.Where(
+				fmt.Sprintf("questions.%s %s ? OR (questions.%s = ? AND questions.id %s ?)", column, cmp, column, cmp),
+				q.AfterValue, q.AfterValue, q.AfterId)
+		}
+	}
+	if column != "id" {
+		query = query.Order(fmt.Sprintf("questions.%s %s", column, order))
+	}
+	var questions []Question
+	err := query.Order("questions.id " + order).Limit(q.Limit).Find(&questions).Error
+	return questions, total, err
+}
+
+func questionFilter(q QuestionQuery) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if q.Category != "" {
+			db = db.Scopes(categoryScope(q.Category))
+		}
+		if q.MasteryLevel != nil {
+			db = db.Where("questions.mastery_level = ?", *q.MasteryLevel)
+		}
+		if q.UpdatedSince > 0 {
+			db = db.Where("questions.utime >= ?", q.UpdatedSince)
+		}
+		if q.Keyword != "" {
+			pattern := "%" + escapeLike(q.Keyword) + "%"
+			db = db.Where("questions.content LIKE ? OR questions.answer LIKE ?", pattern, pattern)
+		}
+		return db
+	}
+}
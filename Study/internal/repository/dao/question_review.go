@@ -0,0 +1,40 @@
+package dao
+
+import (
+	"context"
+)
+
+// QuestionReview 题目复习事件，记录每一次评分或掌握度变更
+type QuestionReview struct {
+	Id            int64  `gorm:"primaryKey,autoIncrement"`
+	QuestionId    int64  `gorm:"type:bigint;not null;index"`
+	Kind          string `gorm:"type:varchar(20);not null"` // grade, mastery
+	Grade         *int   `gorm:"type:int"`                  // 仅评分事件有值
+	MasteryBefore int    `gorm:"type:int;not null;default:0"`
+	MasteryAfter  int    `gorm:"type:int;not null;default:0"`
+	DurationMs    int64  `gorm:"type:bigint;not null;default:0"`
+	Ctime         int64  `gorm:"type:bigint;not null;index"`
+}
+
+func (r QuestionReview) TableName() string {
+	return "question_reviews"
+}
+
+// FindReviews 查询单个题目的复习记录，按时间倒序
+func (dao *questionDao) FindReviews(ctx context.Context, questionId int64, limit int) ([]QuestionReview, error) {
+	var reviews []QuestionReview
+	err := dao.db.WithContext(ctx).Where("question_id = ?", questionId).
+		Order("ctime DESC, id DESC").Limit(limit).Find(&reviews).Error
+	return reviews, err
+}
+
+// FindRecentReviews 查询全部题目的复习动态，before 为 0 时从最新开始
+func (dao *questionDao) FindRecentReviews(ctx context.Context, before int64, limit int) ([]QuestionReview, error) {
+	var reviews []QuestionReview
+	query := dao.db.WithContext(ctx)
+	if before > 0 {
+		query = query.Where("ctime < ?", before)
+	}
+	err := query.Order("ctime DESC, id DESC").Limit(limit).Find(&reviews).Error
+	return reviews, err
+}
@@ -0,0 +1,71 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// QuestionRevision 题目修订记录，保存每次修改后的完整内容
+type QuestionRevision struct {
+	Id         int64  `gorm:"primaryKey,autoIncrement"`
+	QuestionId int64  `gorm:"type:bigint;not null;uniqueIndex:idx_question_rev"`
+	Rev        int    `gorm:"type:int;not null;uniqueIndex:idx_question_rev"`
+	Category   string `gorm:"type:varchar(100);not null"`
+	Content    string `gorm:"type:text;not null"`
+	Answer     string `gorm:"type:text;not null"`
+	Author     string `gorm:"type:varchar(100);not null;default:''"`
+	// RestoredFrom 由哪个版本恢复而来，0 表示普通修改
+	RestoredFrom int   `gorm:"type:int;not null;default:0"`
+	Ctime        int64 `gorm:"type:bigint;not null"` // 与 questions.utime 一致，单位为秒
+}
+
+func (r QuestionRevision) TableName() string {
+	return "question_revisions"
+}
+
+// FindRevisions 查询题目的全部修订记录，按版本号倒序
+func (dao *questionDao) FindRevisions(ctx context.Context, questionId int64) ([]QuestionRevision, error) {
+	var revisions []QuestionRevision
+	err := dao.db.WithContext(ctx).Where("question_id = ?", questionId).
+		Order("rev DESC").Find(&revisions).Error
+	return revisions, err
+}
+
+func (dao *questionDao) FindRevision(ctx context.Context, questionId int64, rev int) (QuestionRevision, error) {
+	var revision QuestionRevision
+	err := dao.db.WithContext(ctx).Where("question_id = ? AND rev = ?", questionId, rev).
+		First(&revision).Error
+	return revision, err
+}
+
+// saveRevision 在事务中读取题目当前内容，追加为一个新版本，now 的单位为秒
+func saveRevision(tx *gorm.DB, questionId int64, author string, restoredFrom int, now int64) error {
+	var quest Question
+	if err := tx.Where("id = ?", questionId).First(&quest).Error; err != nil {
+		return err
+	}
+	var last int
+	err := tx.Model(&QuestionRevision{}).Where("question_id = ?", questionId).
+		Select("COALESCE(MAX(rev), 0)").Scan(&last).Error
+	if err != nil {
+		return err
+	}
+	return tx.Create(&QuestionRevision{
+		QuestionId:   questionId,
+		Rev:          last + 1,
+		Category:     quest.Category,
+		Content:      quest.Content,
+		Answer:       quest.Answer,
+		Author:       author,
+		RestoredFrom: restoredFrom,
+		Ctime:        now,
+	}).Error
+}
+
+// migrateQuestionRevisions 为还没有修订记录的题目补一个初始版本，可重复执行
+func migrateQuestionRevisions(db *gorm.DB) error {
+	return db.Exec(`INSERT INTO question_revisions (question_id, rev, category, content, answer, author, restored_from, ctime)
+SELECT id, 1, category, content, answer, '', 0, utime FROM questions
+WHERE id NOT IN (SELECT question_id FROM question_revisions)`).Error
+}
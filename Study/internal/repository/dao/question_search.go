@@ -0,0 +1,52 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+const questionFulltextIndex = "idx_questions_fulltext"
+
+// ErrSearchUnsupported 当前数据库不支持全文检索，调用方需要回退到进程内索引
+var ErrSearchUnsupported = errors.New("search is not supported by this database")
+
+// QuestionSearchHit 全文检索命中结果
+type QuestionSearchHit struct {
+	Question `gorm:"embedded"`
+	Score    float64
+}
+
+// Search 使用 MySQL ngram 全文索引检索题目内容和答案，所有关键词都需要命中
+func (dao *questionDao) Search(ctx context.Context, keyword string, limit int) ([]QuestionSearchHit, error) {
+	if dao.db.Dialector.Name() != "mysql" {
+		return nil, ErrSearchUnsupported
+	}
+	boolean := booleanQuery(keyword)
+	if boolean == "" {
+		return []QuestionSearchHit{}, nil
+	}
+
+	var hits []QuestionSearchHit
+	err := dao.db.WithContext(ctx).Model(&Question{}).
+		Select("*, MATCH(content, answer) AGAINST (? IN NATURAL LANGUAGE MODE) AS score", keyword).
+		Where("MATCH(content, answer) AGAINST (? IN BOOLEAN MODE)", boolean).
+		Order("score DESC").
+		Limit(limit).
+		Scan(&hits).Error
+	return hits, err
+}
+
+// booleanQuery 将关键词转换为 BOOLEAN MODE 表达式，每个词作为必须命中的短语
+func booleanQuery(keyword string) string {
+	terms := strings.Fields(strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`+-<>()~*"@`, r) {
+			return ' '
+		}
+		return r
+	}, keyword))
+	for i, term := range terms {
+		terms[i] = `+"` + term + `"`
+	}
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,154 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// mergeAuthor 合并重复题目产生的修订记录的作者
+const mergeAuthor = "merge"
+
+// QuestionFingerprint 题目ID和内容指纹
+type QuestionFingerprint struct {
+	Id      int64
+	Simhash int64
+}
+
+func (dao *questionDao) FindFingerprints(ctx context.Context) ([]QuestionFingerprint, error) {
+	var res []QuestionFingerprint
+	err := dao.db.WithContext(ctx).Model(&Question{}).
+		Select("id", "simhash").Order("id ASC").Scan(&res).Error
+	return res, err
+}
+
+// Merge 合并重复题目：保留题目沿用各题中掌握程度最高的复习计划，复习次数累加，
+// 复习记录、复习会话中的题目、标签和修订记录都迁移到保留题目上
+func (dao *questionDao) Merge(ctx context.Context, keepId int64, mergeIds []int64) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var keep Question
+		if err := tx.Where("id = ?", keepId).First(&keep).Error; err != nil {
+			return err
+		}
+		var merged []Question
+		if err := tx.Where("id IN ?", mergeIds).Find(&merged).Error; err != nil {
+			return err
+		}
+		if len(merged) != len(mergeIds) {
+			return ErrRecordNotFound
+		}
+
+		best := keep
+		count := keep.Count
+		for _, quest := range merged {
+			count += quest.Count
+			if moreMastered(quest, best) {
+				best = quest
+			}
+		}
+		err := tx.Model(&Question{}).Where("id = ?", keepId).
+			Updates(map[string]interface{}{
+				"mastery_level":  best.MasteryLevel,
+				"ease_factor":    best.EaseFactor,
+				"interval_days":  best.IntervalDays,
+				"repetitions":    best.Repetitions,
+				"next_review_at": best.NextReviewAt,
+				"count":          count,
+				"utime":          time.Now().Unix(),
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&QuestionReview{}).Where("question_id IN ?", mergeIds).
+			Update("question_id", keepId).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&ReviewSessionItem{}).Where("question_id IN ?", mergeIds).
+			Update("question_id", keepId).Error
+		if err != nil {
+			return err
+		}
+
+		var tagIds []int64
+		err = tx.Model(&QuestionTag{}).Where("question_id IN ?", mergeIds).
+			Distinct("tag_id").Pluck("tag_id", &tagIds).Error
+		if err != nil {
+			return err
+		}
+		if len(tagIds) > 0 {
+			links := make([]QuestionTag, 0, len(tagIds))
+			for _, tagId := range tagIds {
+				links = append(links, QuestionTag{QuestionId: keepId, TagId: tagId})
+			}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("question_id IN ?", mergeIds).Delete(&QuestionTag{}).Error; err != nil {
+			return err
+		}
+		if err := moveRevisions(tx, keepId, mergeIds); err != nil {
+			return err
+		}
+		// 被合并的题目已经迁移了全部数据，直接删除而不是放入回收站
+		return tx.Unscoped().Where("id IN ?", mergeIds).Delete(&Question{}).Error
+	})
+}
+
+// moveRevisions 把被合并题目的修订记录按时间顺序接在保留题目的版本之后并重新编号，
+// 最后为保留题目的当前内容追加一个版本，使最新版本仍然是题目当前的内容
+func moveRevisions(tx *gorm.DB, keepId int64, mergeIds []int64) error {
+	var last int
+	err := tx.Model(&QuestionRevision{}).Where("question_id = ?", keepId).
+		Select("COALESCE(MAX(rev), 0)").Scan(&last).Error
+	if err != nil {
+		return err
+	}
+	var revisions []QuestionRevision
+	err = tx.Where("question_id IN ?", mergeIds).
+		Order("ctime ASC, question_id ASC, rev ASC").Find(&revisions).Error
+	if err != nil {
+		return err
+	}
+	// 恢复来源的版本号同样需要换成新编号
+	renumbered := make(map[int64]map[int]int, len(mergeIds))
+	for _, revision := range revisions {
+		if renumbered[revision.QuestionId] == nil {
+			renumbered[revision.QuestionId] = make(map[int]int)
+		}
+		last++
+		renumbered[revision.QuestionId][revision.Rev] = last
+	}
+	for _, revision := range revisions {
+		updates := map[string]interface{}{
+			"question_id": keepId,
+			"rev":         renumbered[revision.QuestionId][revision.Rev],
+		}
+		if revision.RestoredFrom > 0 {
+			updates["restored_from"] = renumbered[revision.QuestionId][revision.RestoredFrom]
+		}
+		if err := tx.Model(&QuestionRevision{}).Where("id = ?", revision.Id).Updates(updates).Error; err != nil {
+			return err
+		}
+	}
+	if len(revisions) == 0 {
+		return nil
+	}
+	return saveRevision(tx, keepId, mergeAuthor, 0, time.Now().Unix())
+}
+
+// moreMastered 按掌握度、连续答对次数、复习间隔依次比较
+func moreMastered(a, b Question) bool {
+	if a.MasteryLevel != b.MasteryLevel {
+		return a.MasteryLevel > b.MasteryLevel
+	}
+	if a.Repetitions != b.Repetitions {
+		return a.Repetitions > b.Repetitions
+	}
+	return a.IntervalDays > b.IntervalDays
+}
@@ -0,0 +1,74 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func (dao *questionDao) FindDeleted(ctx context.Context) ([]Question, error) {
+	var questions []Question
+	err := dao.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").Find(&questions).Error
+	return questions, err
+}
+
+// Restore 恢复题目，并重新关联分类标签（删除分类时分类标签会被删除）
+func (dao *questionDao) Restore(ctx context.Context, id int64) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var quest Question
+		err := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&quest).Error
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		err = tx.Unscoped().Model(&Question{}).Where("id = ?", id).
+			Updates(map[string]interface{}{
+				"deleted_at": nil,
+				"utime":      now.Unix(),
+			}).Error
+		if err != nil {
+			return err
+		}
+		return relinkCategory(tx, id, "", quest.Category, now.UnixMilli())
+	})
+}
+
+func (dao *questionDao) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var purged int64
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []int64
+		err := tx.Unscoped().Model(&Question{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		for _, model := range []interface{}{&QuestionTag{}, &QuestionRevision{}, &QuestionReview{}} {
+			if err := tx.Where("question_id IN ?", ids).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := detachSessionItems(tx, ids); err != nil {
+			return err
+		}
+		res := tx.Unscoped().Where("id IN ?", ids).Delete(&Question{})
+		purged = res.RowsAffected
+		return res.Error
+	})
+	return purged, err
+}
+
+// detachSessionItems 解除复习会话题目与被彻底删除题目的关联：
+// 未作答的标记为跳过，已作答的保留作答记录，题目ID清零
+func detachSessionItems(tx *gorm.DB, ids []int64) error {
+	err := tx.Model(&ReviewSessionItem{}).Where("question_id IN ? AND reviewed_at = 0", ids).
+		Updates(map[string]interface{}{
+			"skipped":     true,
+			"reviewed_at": time.Now().UnixMilli(),
+		}).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&ReviewSessionItem{}).Where("question_id IN ?", ids).Update("question_id", 0).Error
+}
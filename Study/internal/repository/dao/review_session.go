@@ -0,0 +1,165 @@
+package dao
+
+import (
+	"Training/Study/internal/domain"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrReviewItemAnswered = errors.New("会话题目已作答")
+
+type ReviewSessionDao interface {
+	Insert(ctx context.Context, session ReviewSession, items []ReviewSessionItem) (int64, error)
+	FindById(ctx context.Context, id int64) (ReviewSession, error)
+	FindLatestActive(ctx context.Context) (ReviewSession, error)
+	FindItems(ctx context.Context, sessionId int64) ([]ReviewSessionItem, error)
+	// Answer 在同一事务中用 schedule 更新题目的复习计划并记录作答结果，返回填好评分前后掌握度的会话题目；
+	// 题目已作答时返回 ErrReviewItemAnswered
+	Answer(ctx context.Context, item ReviewSessionItem, schedule func(quest Question) Question,
+		review QuestionReview) (ReviewSessionItem, error)
+	// SkipItem 跳过题目已被删除的会话题目
+	SkipItem(ctx context.Context, sessionId int64, position int) error
+	Finish(ctx context.Context, id int64) error
+}
+
+type reviewSessionDao struct {
+	db *gorm.DB
+}
+
+func NewReviewSessionDao(db *gorm.DB) ReviewSessionDao {
+	return &reviewSessionDao{db: db}
+}
+
+// Insert 在同一事务中创建会话及其题目
+func (dao *reviewSessionDao) Insert(ctx context.Context, session ReviewSession, items []ReviewSessionItem) (int64, error) {
+	now := time.Now().UnixMilli()
+	session.StartedAt = now
+	session.Ctime = now
+	session.Utime = now
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		for i := range items {
+			items[i].SessionId = session.Id
+		}
+		return tx.Create(&items).Error
+	})
+	return session.Id, err
+}
+
+func (dao *reviewSessionDao) FindById(ctx context.Context, id int64) (ReviewSession, error) {
+	var session ReviewSession
+	err := dao.db.WithContext(ctx).Where("id = ?", id).First(&session).Error
+	return session, err
+}
+
+func (dao *reviewSessionDao) FindLatestActive(ctx context.Context) (ReviewSession, error) {
+	var session ReviewSession
+	err := dao.db.WithContext(ctx).Where("status = ?", domain.ReviewSessionActive).
+		Order("id DESC").First(&session).Error
+	return session, err
+}
+
+func (dao *reviewSessionDao) FindItems(ctx context.Context, sessionId int64) ([]ReviewSessionItem, error) {
+	var items []ReviewSessionItem
+	err := dao.db.WithContext(ctx).Where("session_id = ?", sessionId).
+		Order("position ASC").Find(&items).Error
+	return items, err
+}
+
+func (dao *reviewSessionDao) Answer(ctx context.Context, item ReviewSessionItem, schedule func(quest Question) Question,
+	review QuestionReview) (ReviewSessionItem, error) {
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		before, after, err := updateSchedule(tx, item.QuestionId, schedule, review)
+		if err != nil {
+			return err
+		}
+		item.MasteryBefore = before.MasteryLevel
+		item.MasteryAfter = after.MasteryLevel
+		return updateItem(tx, item, map[string]interface{}{
+			"grade":          item.Grade,
+			"mastery_before": item.MasteryBefore,
+			"mastery_after":  item.MasteryAfter,
+			"duration_ms":    item.DurationMs,
+			"reviewed_at":    item.ReviewedAt,
+		})
+	})
+	return item, err
+}
+
+func (dao *reviewSessionDao) SkipItem(ctx context.Context, sessionId int64, position int) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return updateItem(tx, ReviewSessionItem{SessionId: sessionId, Position: position}, map[string]interface{}{
+			"skipped":     true,
+			"reviewed_at": time.Now().UnixMilli(),
+		})
+	})
+}
+
+// updateItem 更新尚未作答的会话题目，并刷新会话的更新时间
+func updateItem(tx *gorm.DB, item ReviewSessionItem, updates map[string]interface{}) error {
+	res := tx.Model(&ReviewSessionItem{}).
+		Where("session_id = ? AND position = ? AND reviewed_at = 0", item.SessionId, item.Position).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrReviewItemAnswered
+	}
+	return tx.Model(&ReviewSession{}).Where("id = ?", item.SessionId).
+		Update("utime", time.Now().UnixMilli()).Error
+}
+
+func (dao *reviewSessionDao) Finish(ctx context.Context, id int64) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Model(&ReviewSession{}).
+		Where("id = ? AND status = ?", id, domain.ReviewSessionActive).
+		Updates(map[string]interface{}{
+			"status":      domain.ReviewSessionFinished,
+			"finished_at": now,
+			"utime":       now,
+		}).Error
+}
+
+// ReviewSession 复习会话
+type ReviewSession struct {
+	Id         int64  `gorm:"primaryKey,autoIncrement"`
+	Category   string `gorm:"type:varchar(100)"`
+	Size       int    `gorm:"type:int;not null"`
+	Status     string `gorm:"type:varchar(20);not null;index"` // active, finished
+	StartedAt  int64  `gorm:"type:bigint;not null"`
+	FinishedAt int64  `gorm:"type:bigint;not null;default:0"`
+	Ctime      int64  `gorm:"type:bigint;not null"`
+	Utime      int64  `gorm:"type:bigint;not null"`
+}
+
+func (s ReviewSession) TableName() string {
+	return "review_sessions"
+}
+
+// ReviewSessionItem 会话中的题目，ReviewedAt 为 0 表示尚未作答，
+// Skipped 表示题目已被删除，会话跳过了这道题
+type ReviewSessionItem struct {
+	Id            int64 `gorm:"primaryKey,autoIncrement"`
+	SessionId     int64 `gorm:"uniqueIndex:idx_session_position;not null"`
+	Position      int   `gorm:"uniqueIndex:idx_session_position;not null"`
+	QuestionId    int64 `gorm:"type:bigint;not null"`
+	Grade         int   `gorm:"type:int;not null;default:0"`
+	MasteryBefore int   `gorm:"type:int;not null;default:0"`
+	MasteryAfter  int   `gorm:"type:int;not null;default:0"`
+	DurationMs    int64 `gorm:"type:bigint;not null;default:0"`
+	ReviewedAt    int64 `gorm:"type:bigint;not null;default:0"`
+	Skipped       bool  `gorm:"not null;default:false"`
+}
+
+func (i ReviewSessionItem) TableName() string {
+	return "review_session_items"
+}
@@ -0,0 +1,270 @@
+package dao
+
+import (
+	"Training/Study/internal/domain"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidTagPath = errors.New("invalid tag path")
+
+type TagDao interface {
+	FindAll(ctx context.Context) ([]Tag, error)
+	FindByPath(ctx context.Context, path string) (Tag, error)
+	// EnsurePath 按路径查找标签，不存在时连同祖先一起创建
+	EnsurePath(ctx context.Context, path string) (Tag, error)
+	// SetQuestionTags 用给定路径整体替换题目的标签
+	SetQuestionTags(ctx context.Context, questionId int64, paths []string) error
+	FindQuestionTags(ctx context.Context, questionIds []int64) (map[int64][]string, error)
+	// CountQuestions 统计每个标签直接关联的题目数
+	CountQuestions(ctx context.Context) (map[int64]int64, error)
+}
+
+type tagDao struct {
+	db *gorm.DB
+}
+
+func NewTagDao(db *gorm.DB) TagDao {
+	return &tagDao{db: db}
+}
+
+func (dao *tagDao) FindAll(ctx context.Context) ([]Tag, error) {
+	var tags []Tag
+	err := dao.db.WithContext(ctx).Order("path ASC").Find(&tags).Error
+	return tags, err
+}
+
+func (dao *tagDao) FindByPath(ctx context.Context, path string) (Tag, error) {
+	var tag Tag
+	err := dao.db.WithContext(ctx).Where("path = ?", domain.NormalizeTagPath(path)).First(&tag).Error
+	return tag, err
+}
+
+func (dao *tagDao) EnsurePath(ctx context.Context, path string) (Tag, error) {
+	var tag Tag
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		tag, err = ensureTagPath(tx, path, time.Now().UnixMilli())
+		return err
+	})
+	return tag, err
+}
+
+func (dao *tagDao) SetQuestionTags(ctx context.Context, questionId int64, paths []string) error {
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", questionId).First(&Question{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("question_id = ?", questionId).Delete(&QuestionTag{}).Error; err != nil {
+			return err
+		}
+		now := time.Now().UnixMilli()
+		for _, path := range paths {
+			tag, err := ensureTagPath(tx, path, now)
+			if err != nil {
+				return err
+			}
+			if err := linkQuestionTag(tx, questionId, tag.Id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (dao *tagDao) FindQuestionTags(ctx context.Context, questionIds []int64) (map[int64][]string, error) {
+	res := make(map[int64][]string, len(questionIds))
+	if len(questionIds) == 0 {
+		return res, nil
+	}
+	var rows []struct {
+		QuestionId int64
+		Path       string
+	}
+	err := dao.db.WithContext(ctx).Model(&QuestionTag{}).
+		Select("question_tags.question_id, tags.path").
+		Joins("JOIN tags ON tags.id = question_tags.tag_id").
+		Where("question_tags.question_id IN ?", questionIds).
+		Order("tags.path ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		res[row.QuestionId] = append(res[row.QuestionId], row.Path)
+	}
+	return res, nil
+}
+
+func (dao *tagDao) CountQuestions(ctx context.Context) (map[int64]int64, error) {
+	var rows []struct {
+		TagId int64
+		Cnt   int64
+	}
+	// 回收站中的题目不计入
+	err := dao.db.WithContext(ctx).Model(&QuestionTag{}).
+		Select("question_tags.tag_id, COUNT(*) AS cnt").
+		Joins("JOIN questions ON questions.id = question_tags.question_id AND questions.deleted_at IS NULL").
+		Group("question_tags.tag_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		res[row.TagId] = row.Cnt
+	}
+	return res, nil
+}
+
+// ensureTagPath 在事务中逐级查找或创建标签，返回路径最末一级
+func ensureTagPath(tx *gorm.DB, path string, now int64) (Tag, error) {
+	path = domain.NormalizeTagPath(path)
+	if path == "" {
+		return Tag{}, ErrInvalidTagPath
+	}
+	var tag Tag
+	parts := strings.Split(path, domain.TagPathSeparator)
+	for i, name := range parts {
+		current := strings.Join(parts[:i+1], domain.TagPathSeparator)
+		next := Tag{
+			Name:     name,
+			ParentId: tag.Id,
+			Path:     current,
+			Ctime:    now,
+			Utime:    now,
+		}
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&next).Error
+		if err != nil {
+			return Tag{}, err
+		}
+		if err := tx.Where("path = ?", current).First(&tag).Error; err != nil {
+			return Tag{}, err
+		}
+	}
+	return tag, nil
+}
+
+func linkQuestionTag(tx *gorm.DB, questionId, tagId int64) error {
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&QuestionTag{QuestionId: questionId, TagId: tagId}).Error
+}
+
+// relinkCategory 题目分类变化时，解除旧分类标签并关联新分类标签
+func relinkCategory(tx *gorm.DB, questionId int64, oldCategory, newCategory string, now int64) error {
+	if oldCategory != "" && domain.NormalizeTagPath(oldCategory) != domain.NormalizeTagPath(newCategory) {
+		var old Tag
+		err := tx.Where("path = ?", domain.NormalizeTagPath(oldCategory)).First(&old).Error
+		if err == nil {
+			err = tx.Where("question_id = ? AND tag_id = ?", questionId, old.Id).Delete(&QuestionTag{}).Error
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+	if domain.NormalizeTagPath(newCategory) == "" {
+		return nil
+	}
+	tag, err := ensureTagPath(tx, newCategory, now)
+	if err != nil {
+		return err
+	}
+	return linkQuestionTag(tx, questionId, tag.Id)
+}
+
+// categoryScope 按分类筛选题目：包含分类对应标签及其全部子标签下的题目，
+// 同时兼容尚未关联标签的旧数据
+func categoryScope(category string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		path := domain.NormalizeTagPath(category)
+		subtree := db.Session(&gorm.Session{NewDB: true}).Model(&QuestionTag{}).
+			Select("question_tags.question_id").
+			Joins("JOIN tags ON tags.id = question_tags.tag_id").
+			Where("tags.path = ? OR tags.path LIKE ?", path, escapeLike(path)+domain.TagPathSeparator+"%")
+		return db.Where("questions.category = ? OR questions.id IN (?)", category, subtree)
+	}
+}
+
+// escapeLike 转义 LIKE 中的通配符
+func escapeLike(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(s)
+}
+
+// legacySeparator 旧分类名中的 / 不表示层级，迁移时替换为全角斜杠，例如 TCP/IP => TCP／IP
+const legacySeparator = "／"
+
+// migrateCategoryTags 将已有分类转换为顶层标签并关联题目，只执行一次。
+// 引入标签之前分类没有层级，分类名中的 / 替换为全角斜杠，题目分类一并改写，避免被拆成多级标签
+func migrateCategoryTags(db *gorm.DB) error {
+	return runMigrationOnce(db, "category_tags", func(tx *gorm.DB) error {
+		var categories []string
+		err := tx.Unscoped().Model(&Question{}).Distinct("category").Pluck("category", &categories).Error
+		if err != nil {
+			return err
+		}
+		now := time.Now().UnixMilli()
+		for _, category := range categories {
+			name := strings.TrimSpace(strings.ReplaceAll(category, domain.TagPathSeparator, legacySeparator))
+			if name == "" {
+				continue
+			}
+			if name != category {
+				err := tx.Unscoped().Model(&Question{}).Where("category = ?", category).
+					Update("category", name).Error
+				if err != nil {
+					return err
+				}
+			}
+			tag, err := ensureTagPath(tx, name, now)
+			if err != nil {
+				return err
+			}
+			var ids []int64
+			err = tx.Unscoped().Model(&Question{}).Where("category = ?", name).Pluck("id", &ids).Error
+			if err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				continue
+			}
+			links := make([]QuestionTag, 0, len(ids))
+			for _, id := range ids {
+				links = append(links, QuestionTag{QuestionId: id, TagId: tag.Id})
+			}
+			err = tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(links, 500).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Tag 标签，Path 为从根到自身的完整路径
+type Tag struct {
+	Id       int64  `gorm:"primaryKey,autoIncrement"`
+	Name     string `gorm:"type:varchar(100);not null"`
+	ParentId int64  `gorm:"type:bigint;not null;default:0;index"`
+	Path     string `gorm:"type:varchar(500);not null;uniqueIndex"`
+	Ctime    int64  `gorm:"type:bigint;not null"`
+	Utime    int64  `gorm:"type:bigint;not null"`
+}
+
+func (t Tag) TableName() string {
+	return "tags"
+}
+
+// QuestionTag 题目与标签的多对多关联
+type QuestionTag struct {
+	QuestionId int64 `gorm:"primaryKey;autoIncrement:false"`
+	TagId      int64 `gorm:"primaryKey;autoIncrement:false;index"`
+}
+
+func (qt QuestionTag) TableName() string {
+	return "question_tags"
+}
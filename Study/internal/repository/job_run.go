@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/repository/dao"
+	"context"
+
+	"github.com/ecodeclub/ekit/slice"
+)
+
+var ErrJobRunNotFound = dao.ErrRecordNotFound
+
+type JobRunRepository interface {
+	Create(ctx context.Context, run domain.JobRun) (int64, error)
+	Update(ctx context.Context, run domain.JobRun) error
+	FindByJob(ctx context.Context, job string, limit int) ([]domain.JobRun, error)
+	FindLastByStatus(ctx context.Context, job, status string) (domain.JobRun, error)
+	FailRunning(ctx context.Context, reason string) (int64, error)
+}
+
+type jobRunRepository struct {
+	dao dao.JobRunDao
+}
+
+func NewJobRunRepository(dao dao.JobRunDao) JobRunRepository {
+	return &jobRunRepository{dao: dao}
+}
+
+func (r *jobRunRepository) Create(ctx context.Context, run domain.JobRun) (int64, error) {
+	return r.dao.Insert(ctx, r.toEntity(run))
+}
+
+func (r *jobRunRepository) Update(ctx context.Context, run domain.JobRun) error {
+	return r.dao.Update(ctx, r.toEntity(run))
+}
+
+func (r *jobRunRepository) FindByJob(ctx context.Context, job string, limit int) ([]domain.JobRun, error) {
+	runs, err := r.dao.FindByJob(ctx, job, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(runs, func(idx int, src dao.JobRun) domain.JobRun {
+		return r.toDomain(src)
+	}), nil
+}
+
+func (r *jobRunRepository) FindLastByStatus(ctx context.Context, job, status string) (domain.JobRun, error) {
+	run, err := r.dao.FindLastByStatus(ctx, job, status)
+	if err != nil {
+		return domain.JobRun{}, err
+	}
+	return r.toDomain(run), nil
+}
+
+func (r *jobRunRepository) FailRunning(ctx context.Context, reason string) (int64, error) {
+	return r.dao.FailRunning(ctx, reason)
+}
+
+func (r *jobRunRepository) toEntity(run domain.JobRun) dao.JobRun {
+	return dao.JobRun{
+		Id:         run.Id,
+		Job:        run.Job,
+		Trigger:    run.Trigger,
+		Attempt:    run.Attempt,
+		Status:     run.Status,
+		Error:      run.Error,
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+	}
+}
+
+func (r *jobRunRepository) toDomain(run dao.JobRun) domain.JobRun {
+	return domain.JobRun{
+		Id:         run.Id,
+		Job:        run.Job,
+		Trigger:    run.Trigger,
+		Attempt:    run.Attempt,
+		Status:     run.Status,
+		Error:      run.Error,
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+	}
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/repository/dao"
+	"context"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+)
+
+var ErrProblemEnrichmentNotFound = dao.ErrRecordNotFound
+
+type ProblemEnrichmentRepository interface {
+	// FindPending 需要补全的题目，按 id 排序
+	FindPending(ctx context.Context, now time.Time, limit int) ([]domain.CodingProblem, error)
+	FindByProblemId(ctx context.Context, problemId int64) (domain.ProblemEnrichment, error)
+	Save(ctx context.Context, enrichment domain.ProblemEnrichment) error
+}
+
+type problemEnrichmentRepository struct {
+	dao dao.ProblemEnrichmentDao
+}
+
+func NewProblemEnrichmentRepository(dao dao.ProblemEnrichmentDao) ProblemEnrichmentRepository {
+	return &problemEnrichmentRepository{dao: dao}
+}
+
+func (r *problemEnrichmentRepository) FindPending(ctx context.Context, now time.Time, limit int) ([]domain.CodingProblem, error) {
+	problems, err := r.dao.FindPending(ctx, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(problems, func(idx int, src dao.CodingProblem) domain.CodingProblem {
+		return toCodingProblem(src)
+	}), nil
+}
+
+func (r *problemEnrichmentRepository) FindByProblemId(ctx context.Context, problemId int64) (domain.ProblemEnrichment, error) {
+	enrichment, err := r.dao.FindByProblemId(ctx, problemId)
+	if err != nil {
+		return domain.ProblemEnrichment{}, err
+	}
+	return domain.ProblemEnrichment{
+		ProblemId:     enrichment.ProblemId,
+		Status:        enrichment.Status,
+		Attempts:      enrichment.Attempts,
+		Error:         enrichment.Error,
+		NextAttemptAt: enrichment.NextAttemptAt,
+		Utime:         enrichment.Utime,
+	}, nil
+}
+
+func (r *problemEnrichmentRepository) Save(ctx context.Context, enrichment domain.ProblemEnrichment) error {
+	return r.dao.Save(ctx, dao.ProblemEnrichment{
+		ProblemId:     enrichment.ProblemId,
+		Status:        enrichment.Status,
+		Attempts:      enrichment.Attempts,
+		Error:         enrichment.Error,
+		NextAttemptAt: enrichment.NextAttemptAt,
+		Utime:         enrichment.Utime,
+	})
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/repository/dao"
+	"context"
+
+	"github.com/ecodeclub/ekit/slice"
+)
+
+var (
+	ErrProblemListNotFound = dao.ErrRecordNotFound
+	ErrProblemListExists   = dao.ErrProblemListExists
+)
+
+type ProblemListRepository interface {
+	// FindAll 全部题单，内置题单在前，ProblemCount 为题单中的题目数
+	FindAll(ctx context.Context) ([]domain.ProblemList, error)
+	FindById(ctx context.Context, id int64) (domain.ProblemList, error)
+	FindByName(ctx context.Context, name string) (domain.ProblemList, error)
+	Create(ctx context.Context, list domain.ProblemList) (domain.ProblemList, error)
+	Update(ctx context.Context, list domain.ProblemList) error
+	Delete(ctx context.Context, id int64) error
+	FindProblems(ctx context.Context, listId int64) ([]domain.CodingProblem, error)
+	SetProblems(ctx context.Context, listId int64, problemIds []int64) error
+	AddProblems(ctx context.Context, listId int64, problemIds []int64) error
+	RemoveProblem(ctx context.Context, listId, problemId int64) error
+	Progress(ctx context.Context, listId int64) (domain.ProblemListProgress, error)
+	// SaveImported 按来源保存导入的题单及题目顺序，重复导入时更新同一个题单
+	SaveImported(ctx context.Context, list domain.ProblemList, problemIds []int64) (domain.ProblemList, error)
+}
+
+type problemListRepository struct {
+	dao dao.ProblemListDao
+}
+
+func NewProblemListRepository(dao dao.ProblemListDao) ProblemListRepository {
+	return &problemListRepository{dao: dao}
+}
+
+func (r *problemListRepository) FindAll(ctx context.Context) ([]domain.ProblemList, error) {
+	lists, err := r.dao.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := r.dao.CountProblems(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(lists, func(idx int, src dao.ProblemList) domain.ProblemList {
+		list := r.toDomain(src)
+		list.ProblemCount = counts[src.Id]
+		return list
+	}), nil
+}
+
+func (r *problemListRepository) FindById(ctx context.Context, id int64) (domain.ProblemList, error) {
+	list, err := r.dao.FindById(ctx, id)
+	if err != nil {
+		return domain.ProblemList{}, err
+	}
+	return r.toDomain(list), nil
+}
+
+func (r *problemListRepository) FindByName(ctx context.Context, name string) (domain.ProblemList, error) {
+	list, err := r.dao.FindByName(ctx, name)
+	if err != nil {
+		return domain.ProblemList{}, err
+	}
+	return r.toDomain(list), nil
+}
+
+func (r *problemListRepository) Create(ctx context.Context, list domain.ProblemList) (domain.ProblemList, error) {
+	entity, err := r.dao.Insert(ctx, r.toEntity(list))
+	if err != nil {
+		return domain.ProblemList{}, err
+	}
+	return r.toDomain(entity), nil
+}
+
+func (r *problemListRepository) Update(ctx context.Context, list domain.ProblemList) error {
+	return r.dao.Update(ctx, r.toEntity(list))
+}
+
+func (r *problemListRepository) Delete(ctx context.Context, id int64) error {
+	return r.dao.Delete(ctx, id)
+}
+
+func (r *problemListRepository) FindProblems(ctx context.Context, listId int64) ([]domain.CodingProblem, error) {
+	problems, err := r.dao.FindProblems(ctx, listId)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(problems, func(idx int, src dao.CodingProblem) domain.CodingProblem {
+		return toCodingProblem(src)
+	}), nil
+}
+
+func (r *problemListRepository) SetProblems(ctx context.Context, listId int64, problemIds []int64) error {
+	return r.dao.SetProblems(ctx, listId, problemIds)
+}
+
+func (r *problemListRepository) AddProblems(ctx context.Context, listId int64, problemIds []int64) error {
+	return r.dao.AddProblems(ctx, listId, problemIds)
+}
+
+func (r *problemListRepository) RemoveProblem(ctx context.Context, listId, problemId int64) error {
+	return r.dao.RemoveProblem(ctx, listId, problemId)
+}
+
+func (r *problemListRepository) Progress(ctx context.Context, listId int64) (domain.ProblemListProgress, error) {
+	stats, err := r.dao.Stats(ctx, listId)
+	if err != nil {
+		return domain.ProblemListProgress{}, err
+	}
+	progress := domain.ProblemListProgress{
+		ListId: listId,
+		ByStatus: map[string]int64{
+			domain.StudyNotStarted: 0,
+			domain.StudyInProgress: 0,
+			domain.StudyCompleted:  0,
+		},
+		ByDifficulty: map[string]int64{},
+	}
+	for _, stat := range stats {
+		progress.Total += stat.Cnt
+		progress.ByStatus[stat.StudyStatus] += stat.Cnt
+		progress.ByDifficulty[stat.Difficulty] += stat.Cnt
+	}
+	return progress, nil
+}
+
+func (r *problemListRepository) SaveImported(ctx context.Context, list domain.ProblemList, problemIds []int64) (domain.ProblemList, error) {
+	entity, err := r.dao.SaveImported(ctx, r.toEntity(list), problemIds)
+	if err != nil {
+		return domain.ProblemList{}, err
+	}
+	return r.toDomain(entity), nil
+}
+
+func (r *problemListRepository) toEntity(list domain.ProblemList) dao.ProblemList {
+	return dao.ProblemList{
+		Id:          list.Id,
+		Name:        list.Name,
+		Description: list.Description,
+		BuiltIn:     list.BuiltIn,
+		Source:      list.Source,
+		SourceSlug:  list.SourceSlug,
+		Ctime:       list.Ctime,
+		Utime:       list.Utime,
+	}
+}
+
+func (r *problemListRepository) toDomain(list dao.ProblemList) domain.ProblemList {
+	return domain.ProblemList{
+		Id:          list.Id,
+		Name:        list.Name,
+		Description: list.Description,
+		BuiltIn:     list.BuiltIn,
+		Source:      list.Source,
+		SourceSlug:  list.SourceSlug,
+		Ctime:       list.Ctime,
+		Utime:       list.Utime,
+	}
+}
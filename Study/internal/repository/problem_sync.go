@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/repository/dao"
+	"context"
+
+	"github.com/ecodeclub/ekit/slice"
+)
+
+var ErrProblemSyncNotFound = dao.ErrRecordNotFound
+
+type ProblemSyncRepository interface {
+	FindBySource(ctx context.Context, source string) (domain.ProblemSync, error)
+	FindByStatus(ctx context.Context, status string) ([]domain.ProblemSync, error)
+	Save(ctx context.Context, sync domain.ProblemSync) error
+}
+
+type problemSyncRepository struct {
+	dao dao.ProblemSyncDao
+}
+
+func NewProblemSyncRepository(dao dao.ProblemSyncDao) ProblemSyncRepository {
+	return &problemSyncRepository{dao: dao}
+}
+
+func (r *problemSyncRepository) FindBySource(ctx context.Context, source string) (domain.ProblemSync, error) {
+	sync, err := r.dao.FindBySource(ctx, source)
+	if err != nil {
+		return domain.ProblemSync{}, err
+	}
+	return r.toDomain(sync), nil
+}
+
+func (r *problemSyncRepository) FindByStatus(ctx context.Context, status string) ([]domain.ProblemSync, error) {
+	syncs, err := r.dao.FindByStatus(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(syncs, func(idx int, src dao.ProblemSync) domain.ProblemSync {
+		return r.toDomain(src)
+	}), nil
+}
+
+func (r *problemSyncRepository) Save(ctx context.Context, sync domain.ProblemSync) error {
+	return r.dao.Save(ctx, r.toEntity(sync))
+}
+
+func (r *problemSyncRepository) toEntity(sync domain.ProblemSync) dao.ProblemSync {
+	return dao.ProblemSync{
+		Source:     sync.Source,
+		Status:     sync.Status,
+		Offset:     sync.Offset,
+		Total:      sync.Total,
+		Created:    sync.Created,
+		Updated:    sync.Updated,
+		Skipped:    sync.Skipped,
+		Error:      sync.Error,
+		StartedAt:  sync.StartedAt,
+		FinishedAt: sync.FinishedAt,
+		Utime:      sync.Utime,
+	}
+}
+
+func (r *problemSyncRepository) toDomain(sync dao.ProblemSync) domain.ProblemSync {
+	return domain.ProblemSync{
+		Source:     sync.Source,
+		Status:     sync.Status,
+		Offset:     sync.Offset,
+		Total:      sync.Total,
+		Created:    sync.Created,
+		Updated:    sync.Updated,
+		Skipped:    sync.Skipped,
+		Error:      sync.Error,
+		StartedAt:  sync.StartedAt,
+		FinishedAt: sync.FinishedAt,
+		Utime:      sync.Utime,
+	}
+}
@@ -10,39 +10,90 @@ import (
 	"time"
 )
 
+var (
+	ErrQuestionNotFound  = dao.ErrRecordNotFound
+	ErrSearchUnsupported = dao.ErrSearchUnsupported
+	ErrRevisionNotFound  = dao.ErrRecordNotFound
+)
+
 type QuestRepository interface {
 	Insert(ctx context.Context, quest domain.Question) error
+	FindById(ctx context.Context, id int64) (domain.Question, error)
 	FindByCategory(ctx context.Context, category string) ([]domain.Question, error)
 	FindAll(ctx context.Context) ([]domain.Question, error)
-	UpdateById(ctx context.Context, quest domain.Question) error
+	// FindPage 按条件分页查询，返回本页题目、下一页游标和总数
+	FindPage(ctx context.Context, q domain.QuestionQuery) (domain.QuestionPage, error)
+	FindDue(ctx context.Context, category string, now time.Time, limit int) ([]domain.Question, error)
+	// UpdateById 修改题目并保存新版本，restoredFrom 为 0 表示普通修改
+	UpdateById(ctx context.Context, quest domain.Question, author string, restoredFrom int) error
 	UpdateMasteryLevel(ctx context.Context, id int64, masteryLevel int) error
+	// UpdateSchedule 在锁定题目的事务中用 schedule 计算评分后的复习计划并保存，同时记录本次复习耗时，
+	// 返回更新后的题目
+	UpdateSchedule(ctx context.Context, id int64, grade int, duration time.Duration,
+		schedule func(quest domain.Question) domain.Question) (domain.Question, error)
 	DeleteById(ctx context.Context, id int64) error
-	DeleteByCategory(ctx context.Context, category string) error
+	// DeleteByCategory 在一个事务中删除分类及其子分类下的题目，返回删除的题目数
+	DeleteByCategory(ctx context.Context, category string) (int64, error)
+	CountByCategory(ctx context.Context, category string) (int64, error)
+	// MoveCategory 在一个事务中把分类及其子分类移动到 to，to 已存在时即为合并
+	MoveCategory(ctx context.Context, from, to string) (int64, error)
+	// FindDeleted 回收站中的题目
+	FindDeleted(ctx context.Context) ([]domain.TrashItem, error)
+	// Restore 从回收站恢复题目
+	Restore(ctx context.Context, id int64) error
+	// PurgeDeleted 彻底删除 before 之前进入回收站的题目
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
 	FindAllCategories(ctx context.Context) ([]string, error)
 	GetMasteryStats(ctx context.Context) (map[string]int, error)
+	// Search 数据库全文检索，不支持时返回 ErrSearchUnsupported
+	Search(ctx context.Context, keyword string, limit int) ([]domain.QuestionSearchResult, error)
+	// Import 在一个事务中导入题目，按内容指纹判断重复，返回结果与入参一一对应
+	Import(ctx context.Context, quests []domain.Question, overwrite bool, dryRun bool) ([]domain.QuestionImportResult, error)
+	FindReviews(ctx context.Context, questionId int64, limit int) ([]domain.QuestionReview, error)
+	// FindActivity 全局复习动态，before 为零值时从最新开始
+	FindActivity(ctx context.Context, before time.Time, limit int) ([]domain.QuestionActivity, error)
+	FindRevisions(ctx context.Context, questionId int64) ([]domain.QuestionRevision, error)
+	FindRevision(ctx context.Context, questionId int64, rev int) (domain.QuestionRevision, error)
+	FindByIds(ctx context.Context, ids []int64) ([]domain.Question, error)
+	FindFingerprints(ctx context.Context) ([]domain.QuestionFingerprint, error)
+	// Merge 将 mergeIds 合并到 keepId，保留最高的掌握度以及全部复习记录和标签
+	Merge(ctx context.Context, keepId int64, mergeIds []int64) error
 }
 
 type questRepository struct {
-	dao dao.QuestDao
+	dao    dao.QuestDao
+	tagDao dao.TagDao
 }
 
-func NewQuestRepository(dao dao.QuestDao) QuestRepository {
-	return &questRepository{dao: dao}
+func NewQuestRepository(dao dao.QuestDao, tagDao dao.TagDao) QuestRepository {
+	return &questRepository{
+		dao:    dao,
+		tagDao: tagDao,
+	}
 }
 
 func (r *questRepository) Insert(ctx context.Context, quest domain.Question) error {
 	return r.dao.Insert(ctx, r.toEntity(quest))
 }
 
+func (r *questRepository) FindById(ctx context.Context, id int64) (domain.Question, error) {
+	res, err := r.dao.FindById(ctx, id)
+	if err != nil {
+		return domain.Question{}, err
+	}
+	data, err := r.withTags(ctx, []dao.Question{res})
+	if err != nil {
+		return domain.Question{}, err
+	}
+	return data[0], nil
+}
+
 func (r *questRepository) FindByCategory(ctx context.Context, category string) ([]domain.Question, error) {
 	res, err := r.dao.FindByCategory(ctx, category)
 	if err != nil {
 		return nil, err
 	}
-	data := slice.Map(res, func(idx int, src dao.Question) domain.Question {
-		return r.toDomain(src)
-	})
-	return data, err
+	return r.withTags(ctx, res)
 }
 
 func (r *questRepository) FindAll(ctx context.Context) ([]domain.Question, error) {
@@ -50,28 +101,79 @@ func (r *questRepository) FindAll(ctx context.Context) ([]domain.Question, error
 	if err != nil {
 		return nil, err
 	}
-	data := slice.Map(res, func(idx int, src dao.Question) domain.Question {
-		return r.toDomain(src)
-	})
-	return data, err
+	return r.withTags(ctx, res)
 }
 
-func (r *questRepository) UpdateById(ctx context.Context, quest domain.Question) error {
-	return r.dao.UpdateById(ctx, r.toEntity(quest))
+func (r *questRepository) FindDue(ctx context.Context, category string, now time.Time, limit int) ([]domain.Question, error) {
+	res, err := r.dao.FindDue(ctx, category, now.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.withTags(ctx, res)
+}
+
+func (r *questRepository) UpdateById(ctx context.Context, quest domain.Question, author string, restoredFrom int) error {
+	return r.dao.UpdateById(ctx, r.toEntity(quest), author, restoredFrom)
+}
+
+func (r *questRepository) FindRevisions(ctx context.Context, questionId int64) ([]domain.QuestionRevision, error) {
+	revisions, err := r.dao.FindRevisions(ctx, questionId)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(revisions, func(idx int, src dao.QuestionRevision) domain.QuestionRevision {
+		return r.revisionToDomain(src)
+	}), nil
+}
+
+func (r *questRepository) FindRevision(ctx context.Context, questionId int64, rev int) (domain.QuestionRevision, error) {
+	revision, err := r.dao.FindRevision(ctx, questionId, rev)
+	if err != nil {
+		return domain.QuestionRevision{}, err
+	}
+	return r.revisionToDomain(revision), nil
 }
 
 func (r *questRepository) UpdateMasteryLevel(ctx context.Context, id int64, masteryLevel int) error {
 	return r.dao.UpdateMasteryLevel(ctx, id, masteryLevel)
 }
 
+func (r *questRepository) UpdateSchedule(ctx context.Context, id int64, grade int, duration time.Duration,
+	schedule func(quest domain.Question) domain.Question) (domain.Question, error) {
+	quest, err := r.dao.UpdateSchedule(ctx, id, questionSchedule(schedule), dao.QuestionReview{
+		Grade:      &grade,
+		DurationMs: duration.Milliseconds(),
+	})
+	if err != nil {
+		return domain.Question{}, err
+	}
+	return r.toDomain(quest), nil
+}
+
+// questionSchedule 将领域对象上的复习计划函数转换为 dao 层在事务中调用的函数
+func questionSchedule(schedule func(quest domain.Question) domain.Question) func(quest dao.Question) dao.Question {
+	var r questRepository
+	return func(quest dao.Question) dao.Question {
+		return r.toEntity(schedule(r.toDomain(quest)))
+	}
+}
+
 func (r *questRepository) DeleteById(ctx context.Context, id int64) error {
 	return r.dao.DeleteById(ctx, id)
 }
 
-func (r *questRepository) DeleteByCategory(ctx context.Context, category string) error {
+func (r *questRepository) DeleteByCategory(ctx context.Context, category string) (int64, error) {
 	return r.dao.DeleteByCategory(ctx, category)
 }
 
+func (r *questRepository) CountByCategory(ctx context.Context, category string) (int64, error) {
+	return r.dao.CountByCategory(ctx, category)
+}
+
+func (r *questRepository) MoveCategory(ctx context.Context, from, to string) (int64, error) {
+	return r.dao.MoveCategory(ctx, from, to)
+}
+
 func (r *questRepository) FindAllCategories(ctx context.Context) ([]string, error) {
 	return r.dao.FindAllCategories(ctx)
 }
@@ -80,6 +182,225 @@ func (r *questRepository) GetMasteryStats(ctx context.Context) (map[string]int,
 	return r.dao.GetMasteryStats(ctx)
 }
 
+func (r *questRepository) Search(ctx context.Context, keyword string, limit int) ([]domain.QuestionSearchResult, error) {
+	hits, err := r.dao.Search(ctx, keyword, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(hits, func(idx int, src dao.QuestionSearchHit) domain.QuestionSearchResult {
+		return domain.QuestionSearchResult{
+			Question: r.toDomain(src.Question),
+			Score:    src.Score,
+		}
+	}), nil
+}
+
+func (r *questRepository) Import(ctx context.Context, quests []domain.Question, overwrite bool, dryRun bool) ([]domain.QuestionImportResult, error) {
+	entities := slice.Map(quests, func(idx int, src domain.Question) dao.Question {
+		return r.toEntity(src)
+	})
+	outcomes, err := r.dao.Import(ctx, entities, overwrite, dryRun)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(outcomes, func(idx int, src dao.ImportOutcome) domain.QuestionImportResult {
+		return domain.QuestionImportResult{
+			Id:     src.Id,
+			Status: src.Status,
+		}
+	}), nil
+}
+
+func (r *questRepository) FindReviews(ctx context.Context, questionId int64, limit int) ([]domain.QuestionReview, error) {
+	res, err := r.dao.FindReviews(ctx, questionId, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(res, func(idx int, src dao.QuestionReview) domain.QuestionReview {
+		return r.reviewToDomain(src)
+	}), nil
+}
+
+func (r *questRepository) FindActivity(ctx context.Context, before time.Time, limit int) ([]domain.QuestionActivity, error) {
+	reviews, err := r.dao.FindRecentReviews(ctx, r.toMillis(before), limit)
+	if err != nil {
+		return nil, err
+	}
+	ids := slice.Map(reviews, func(idx int, src dao.QuestionReview) int64 {
+		return src.QuestionId
+	})
+	quests, err := r.dao.FindByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	questMap := make(map[int64]dao.Question, len(quests))
+	for _, quest := range quests {
+		questMap[quest.Id] = quest
+	}
+	activities := make([]domain.QuestionActivity, 0, len(reviews))
+	for _, review := range reviews {
+		quest, ok := questMap[review.QuestionId]
+		// 回收站中的题目不出现在动态里
+		if !ok {
+			continue
+		}
+		activities = append(activities, domain.QuestionActivity{
+			QuestionReview: r.reviewToDomain(review),
+			Category:       quest.Category,
+			Content:        quest.Content,
+		})
+	}
+	return activities, nil
+}
+
+func (r *questRepository) FindByIds(ctx context.Context, ids []int64) ([]domain.Question, error) {
+	quests, err := r.dao.FindByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return r.withTags(ctx, quests)
+}
+
+func (r *questRepository) FindFingerprints(ctx context.Context) ([]domain.QuestionFingerprint, error) {
+	fingerprints, err := r.dao.FindFingerprints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(fingerprints, func(idx int, src dao.QuestionFingerprint) domain.QuestionFingerprint {
+		return domain.QuestionFingerprint{
+			Id:      src.Id,
+			Simhash: uint64(src.Simhash),
+		}
+	}), nil
+}
+
+func (r *questRepository) Merge(ctx context.Context, keepId int64, mergeIds []int64) error {
+	return r.dao.Merge(ctx, keepId, mergeIds)
+}
+
+func (r *questRepository) FindDeleted(ctx context.Context) ([]domain.TrashItem, error) {
+	quests, err := r.dao.FindDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(quests, func(idx int, src dao.Question) domain.TrashItem {
+		return domain.TrashItem{
+			Type:      domain.TrashQuestion,
+			Id:        src.Id,
+			Title:     src.Content,
+			Category:  src.Category,
+			DeletedAt: src.DeletedAt.Time,
+		}
+	}), nil
+}
+
+func (r *questRepository) Restore(ctx context.Context, id int64) error {
+	return r.dao.Restore(ctx, id)
+}
+
+func (r *questRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	return r.dao.PurgeDeleted(ctx, before)
+}
+
+// questionSortColumns 排序字段与数据库列的对应关系
+var questionSortColumns = map[string]string{
+	"id":             "id",
+	"ctime":          "ctime",
+	"utime":          "utime",
+	"mastery_level":  "mastery_level",
+	"next_review_at": "next_review_at",
+	"review_count":   "count",
+}
+
+func (r *questRepository) FindPage(ctx context.Context, q domain.QuestionQuery) (domain.QuestionPage, error) {
+	query := dao.QuestionQuery{
+		Category:     q.Category,
+		MasteryLevel: q.MasteryLevel,
+		Keyword:      q.Keyword,
+		SortColumn:   questionSortColumns[q.SortBy],
+		Desc:         q.Desc,
+		// 多取一条用于判断是否还有下一页
+		Limit: q.Limit + 1,
+	}
+	if !q.UpdatedSince.IsZero() {
+		query.UpdatedSince = q.UpdatedSince.Unix()
+	}
+	if q.After != nil {
+		query.AfterValue = q.After.Value
+		query.AfterId = q.After.Id
+	}
+	quests, total, err := r.dao.FindPage(ctx, query)
+	if err != nil {
+		return domain.QuestionPage{}, err
+	}
+
+	var next *domain.QuestionCursor
+	if len(quests) > q.Limit {
+		quests = quests[:q.Limit]
+		last := quests[len(quests)-1]
+		next = &domain.QuestionCursor{
+			SortBy: q.SortBy,
+			Desc:   q.Desc,
+			Value:  query.SortValue(last),
+			Id:     last.Id,
+		}
+	}
+	data, err := r.withTags(ctx, quests)
+	if err != nil {
+		return domain.QuestionPage{}, err
+	}
+	return domain.QuestionPage{
+		Questions: data,
+		Total:     total,
+		Next:      next,
+	}, nil
+}
+
+// withTags 转换为领域对象并批量带上标签
+func (r *questRepository) withTags(ctx context.Context, quests []dao.Question) ([]domain.Question, error) {
+	ids := slice.Map(quests, func(idx int, src dao.Question) int64 {
+		return src.Id
+	})
+	tags, err := r.tagDao.FindQuestionTags(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(quests, func(idx int, src dao.Question) domain.Question {
+		quest := r.toDomain(src)
+		quest.Tags = tags[src.Id]
+		if quest.Tags == nil {
+			quest.Tags = []string{}
+		}
+		return quest
+	}), nil
+}
+
+func (r *questRepository) revisionToDomain(revision dao.QuestionRevision) domain.QuestionRevision {
+	return domain.QuestionRevision{
+		QuestionId:   revision.QuestionId,
+		Rev:          revision.Rev,
+		Category:     revision.Category,
+		Content:      revision.Content,
+		Answer:       revision.Answer,
+		Author:       revision.Author,
+		RestoredFrom: revision.RestoredFrom,
+		Ctime:        time.Unix(revision.Ctime, 0),
+	}
+}
+
+func (r *questRepository) reviewToDomain(review dao.QuestionReview) domain.QuestionReview {
+	return domain.QuestionReview{
+		Id:            review.Id,
+		QuestionId:    review.QuestionId,
+		Kind:          review.Kind,
+		Grade:         review.Grade,
+		MasteryBefore: review.MasteryBefore,
+		MasteryAfter:  review.MasteryAfter,
+		DurationMs:    review.DurationMs,
+		Ctime:         time.UnixMilli(review.Ctime),
+	}
+}
+
 func (r *questRepository) toDomain(quest dao.Question) domain.Question {
 	return domain.Question{
 		Id:           quest.Id,
@@ -87,6 +408,11 @@ func (r *questRepository) toDomain(quest dao.Question) domain.Question {
 		Content:      quest.Content,
 		Answer:       quest.Answer,
 		MasteryLevel: quest.MasteryLevel,
+		EaseFactor:   quest.EaseFactor,
+		Interval:     quest.IntervalDays,
+		Repetitions:  quest.Repetitions,
+		NextReviewAt: time.UnixMilli(quest.NextReviewAt),
+		ReviewCount:  quest.Count,
 		Ctime:        time.UnixMilli(quest.Ctime),
 		Utime:        time.UnixMilli(quest.Utime),
 	}
@@ -99,7 +425,19 @@ func (r *questRepository) toEntity(quest domain.Question) dao.Question {
 		Content:      quest.Content,
 		Answer:       quest.Answer,
 		MasteryLevel: quest.MasteryLevel,
+		EaseFactor:   quest.EaseFactor,
+		IntervalDays: quest.Interval,
+		Repetitions:  quest.Repetitions,
+		NextReviewAt: r.toMillis(quest.NextReviewAt),
 		Ctime:        quest.Ctime.UnixMilli(),
 		Utime:        quest.Utime.UnixMilli(),
 	}
 }
+
+// toMillis 零值时间转换为 0，避免 Updates 时写入负数时间戳
+func (r *questRepository) toMillis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/repository/dao"
+	"context"
+	"time"
+
+	"github.com/ecodeclub/ekit/slice"
+)
+
+var (
+	ErrReviewSessionNotFound = dao.ErrRecordNotFound
+	ErrReviewItemAnswered    = dao.ErrReviewItemAnswered
+)
+
+type ReviewSessionRepository interface {
+	Create(ctx context.Context, session domain.ReviewSession) (int64, error)
+	// FindById 查找会话并带上全部题目
+	FindById(ctx context.Context, id int64) (domain.ReviewSession, error)
+	FindLatestActive(ctx context.Context) (domain.ReviewSession, error)
+	// Answer 在锁定题目的同一事务中用 schedule 计算并保存评分后的复习计划和会话题目的作答结果，
+	// 返回填好评分前后掌握度的会话题目；
+	// 题目已被删除时返回 ErrQuestionNotFound，会话题目已作答时返回 ErrReviewItemAnswered
+	Answer(ctx context.Context, sessionId int64, item domain.ReviewSessionItem,
+		schedule func(quest domain.Question) domain.Question) (domain.ReviewSessionItem, error)
+	SkipItem(ctx context.Context, sessionId int64, position int) error
+	Finish(ctx context.Context, id int64) error
+}
+
+type reviewSessionRepository struct {
+	dao dao.ReviewSessionDao
+}
+
+func NewReviewSessionRepository(dao dao.ReviewSessionDao) ReviewSessionRepository {
+	return &reviewSessionRepository{dao: dao}
+}
+
+func (r *reviewSessionRepository) Create(ctx context.Context, session domain.ReviewSession) (int64, error) {
+	items := slice.Map(session.Items, func(idx int, src domain.ReviewSessionItem) dao.ReviewSessionItem {
+		return r.itemToEntity(0, src)
+	})
+	return r.dao.Insert(ctx, dao.ReviewSession{
+		Category: session.Category,
+		Size:     session.Size,
+		Status:   domain.ReviewSessionActive,
+	}, items)
+}
+
+func (r *reviewSessionRepository) FindById(ctx context.Context, id int64) (domain.ReviewSession, error) {
+	session, err := r.dao.FindById(ctx, id)
+	if err != nil {
+		return domain.ReviewSession{}, err
+	}
+	return r.withItems(ctx, session)
+}
+
+func (r *reviewSessionRepository) FindLatestActive(ctx context.Context) (domain.ReviewSession, error) {
+	session, err := r.dao.FindLatestActive(ctx)
+	if err != nil {
+		return domain.ReviewSession{}, err
+	}
+	return r.withItems(ctx, session)
+}
+
+func (r *reviewSessionRepository) Answer(ctx context.Context, sessionId int64, item domain.ReviewSessionItem,
+	schedule func(quest domain.Question) domain.Question) (domain.ReviewSessionItem, error) {
+	res, err := r.dao.Answer(ctx, r.itemToEntity(sessionId, item), questionSchedule(schedule), dao.QuestionReview{
+		Grade:      item.Grade,
+		DurationMs: item.DurationMs,
+	})
+	if err != nil {
+		return domain.ReviewSessionItem{}, err
+	}
+	return r.itemToDomain(res), nil
+}
+
+func (r *reviewSessionRepository) SkipItem(ctx context.Context, sessionId int64, position int) error {
+	return r.dao.SkipItem(ctx, sessionId, position)
+}
+
+func (r *reviewSessionRepository) Finish(ctx context.Context, id int64) error {
+	return r.dao.Finish(ctx, id)
+}
+
+func (r *reviewSessionRepository) withItems(ctx context.Context, session dao.ReviewSession) (domain.ReviewSession, error) {
+	items, err := r.dao.FindItems(ctx, session.Id)
+	if err != nil {
+		return domain.ReviewSession{}, err
+	}
+	res := domain.ReviewSession{
+		Id:        session.Id,
+		Category:  session.Category,
+		Size:      session.Size,
+		Status:    session.Status,
+		StartedAt: time.UnixMilli(session.StartedAt),
+		Items: slice.Map(items, func(idx int, src dao.ReviewSessionItem) domain.ReviewSessionItem {
+			return r.itemToDomain(src)
+		}),
+	}
+	if session.FinishedAt > 0 {
+		finishedAt := time.UnixMilli(session.FinishedAt)
+		res.FinishedAt = &finishedAt
+	}
+	return res, nil
+}
+
+func (r *reviewSessionRepository) itemToDomain(item dao.ReviewSessionItem) domain.ReviewSessionItem {
+	res := domain.ReviewSessionItem{
+		Position:      item.Position,
+		QuestionId:    item.QuestionId,
+		MasteryBefore: item.MasteryBefore,
+		MasteryAfter:  item.MasteryAfter,
+		DurationMs:    item.DurationMs,
+		Skipped:       item.Skipped,
+	}
+	if item.Skipped {
+		reviewedAt := time.UnixMilli(item.ReviewedAt)
+		res.ReviewedAt = &reviewedAt
+	} else if item.ReviewedAt > 0 {
+		grade := item.Grade
+		reviewedAt := time.UnixMilli(item.ReviewedAt)
+		res.Grade = &grade
+		res.ReviewedAt = &reviewedAt
+	}
+	return res
+}
+
+func (r *reviewSessionRepository) itemToEntity(sessionId int64, item domain.ReviewSessionItem) dao.ReviewSessionItem {
+	res := dao.ReviewSessionItem{
+		SessionId:     sessionId,
+		Position:      item.Position,
+		QuestionId:    item.QuestionId,
+		MasteryBefore: item.MasteryBefore,
+		MasteryAfter:  item.MasteryAfter,
+		DurationMs:    item.DurationMs,
+	}
+	if item.Grade != nil {
+		res.Grade = *item.Grade
+	}
+	if item.ReviewedAt != nil {
+		res.ReviewedAt = item.ReviewedAt.UnixMilli()
+	}
+	return res
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/repository/dao"
+	"context"
+
+	"github.com/ecodeclub/ekit/slice"
+)
+
+var (
+	ErrTagNotFound    = dao.ErrRecordNotFound
+	ErrInvalidTagPath = dao.ErrInvalidTagPath
+)
+
+type TagRepository interface {
+	// FindAll 返回全部标签（平铺，按路径排序），QuestionCount 为直接关联的题目数
+	FindAll(ctx context.Context) ([]domain.Tag, error)
+	Create(ctx context.Context, path string) (domain.Tag, error)
+	SetQuestionTags(ctx context.Context, questionId int64, paths []string) error
+}
+
+type tagRepository struct {
+	dao dao.TagDao
+}
+
+func NewTagRepository(dao dao.TagDao) TagRepository {
+	return &tagRepository{dao: dao}
+}
+
+func (r *tagRepository) FindAll(ctx context.Context) ([]domain.Tag, error) {
+	tags, err := r.dao.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := r.dao.CountQuestions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(tags, func(idx int, src dao.Tag) domain.Tag {
+		tag := r.toDomain(src)
+		tag.QuestionCount = counts[src.Id]
+		return tag
+	}), nil
+}
+
+func (r *tagRepository) Create(ctx context.Context, path string) (domain.Tag, error) {
+	tag, err := r.dao.EnsurePath(ctx, path)
+	if err != nil {
+		return domain.Tag{}, err
+	}
+	return r.toDomain(tag), nil
+}
+
+func (r *tagRepository) SetQuestionTags(ctx context.Context, questionId int64, paths []string) error {
+	return r.dao.SetQuestionTags(ctx, questionId, paths)
+}
+
+func (r *tagRepository) toDomain(tag dao.Tag) domain.Tag {
+	return domain.Tag{
+		Id:       tag.Id,
+		Name:     tag.Name,
+		ParentId: tag.ParentId,
+		Path:     tag.Path,
+		Children: []domain.Tag{},
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/repository"
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrInvalidAttempt = errors.New("无效的做题记录")
+
+type CodingAttemptService interface {
+	// Record 记录一次做题，题目的学习状态和重做计划由这次结果决定
+	Record(ctx context.Context, attempt domain.CodingAttempt) (domain.CodingAttempt, error)
+	// List 题目的做题记录，最新的在前
+	List(ctx context.Context, problemId int64) ([]domain.CodingAttempt, error)
+}
+
+type codingAttemptService struct {
+	repo repository.CodingAttemptRepository
+}
+
+func NewCodingAttemptService(repo repository.CodingAttemptRepository) CodingAttemptService {
+	return &codingAttemptService{
+		repo: repo,
+	}
+}
+
+func (svc *codingAttemptService) Record(ctx context.Context, attempt domain.CodingAttempt) (domain.CodingAttempt, error) {
+	switch attempt.Result {
+	case domain.AttemptAccepted, domain.AttemptWrong, domain.AttemptTimeout, domain.AttemptGaveUp:
+	default:
+		return domain.CodingAttempt{}, ErrInvalidAttempt
+	}
+	if attempt.TimeSpent < 0 {
+		return domain.CodingAttempt{}, ErrInvalidAttempt
+	}
+	now := time.Now()
+	attempt.Language = strings.ToLower(strings.TrimSpace(attempt.Language))
+	attempt.Ctime = now
+	// 重做计划基于事务中锁定的题目计算，同一道题并发提交时不会互相覆盖
+	return svc.repo.Create(ctx, attempt, func(problem domain.CodingProblem) domain.CodingProblem {
+		return scheduleResolve(problem, attempt, now)
+	})
+}
+
+func (svc *codingAttemptService) List(ctx context.Context, problemId int64) ([]domain.CodingAttempt, error) {
+	return svc.repo.FindByProblem(ctx, problemId)
+}
@@ -13,25 +13,31 @@ type CodingProblemService interface {
 	GetProblemById(ctx context.Context, id int64) (domain.CodingProblem, error)
 	GetProblemsBySource(ctx context.Context, source string) ([]domain.CodingProblem, error)
 	GetProblemsByDifficulty(ctx context.Context, difficulty string) ([]domain.CodingProblem, error)
+	// FindDue 到期需要重做的题目，按到期时间排序
+	FindDue(ctx context.Context, limit int) ([]domain.CodingProblem, error)
 	UpdateProblem(ctx context.Context, problem domain.CodingProblem) error
 	DeleteProblem(ctx context.Context, id int64) error
-	GetDailyProblem(ctx context.Context) (*domain.CodingProblem, error)
+	// GetDailyProblem 来源今天的每日一题，各来源的每日一题分开记录，source 为空时使用默认来源
+	GetDailyProblem(ctx context.Context, source string) (*domain.CodingProblem, error)
 	GetDailyProblemHistory(ctx context.Context) ([]domain.CodingProblem, error)
-	SetDailyProblem(ctx context.Context, problemId int64) error
-	// 爬虫相关方法
-	CrawlDailyProblem(ctx context.Context) error
-	CrawlProblemBySlug(ctx context.Context, titleSlug string) (*domain.CodingProblem, error)
-	StartDailyCrawler(ctx context.Context) error
+	SetDailyProblem(ctx context.Context, source string, problemId int64) error
+	// 爬虫相关方法，source 为题目来源名称，为空时使用默认来源
+	CrawlDailyProblem(ctx context.Context, source string) error
+	CrawlProblemBySlug(ctx context.Context, source, titleSlug string) (*domain.CodingProblem, error)
+	CrawlProblemById(ctx context.Context, source, id string) (*domain.CodingProblem, error)
 }
 
 type codingProblemService struct {
 	repo    repository.CodingProblemRepository
+	sources *ProblemSourceRegistry
 	crawler *LeetCodeCrawler
 }
 
-func NewCodingProblemService(repo repository.CodingProblemRepository, crawler *LeetCodeCrawler) CodingProblemService {
+func NewCodingProblemService(repo repository.CodingProblemRepository, sources *ProblemSourceRegistry,
+	crawler *LeetCodeCrawler) CodingProblemService {
 	return &codingProblemService{
 		repo:    repo,
+		sources: sources,
 		crawler: crawler,
 	}
 }
@@ -55,6 +61,13 @@ func (svc *codingProblemService) GetProblemsBySource(ctx context.Context, source
 	return svc.repo.FindBySource(ctx, source)
 }
 
+func (svc *codingProblemService) FindDue(ctx context.Context, limit int) ([]domain.CodingProblem, error) {
+	if limit <= 0 {
+		limit = defaultDueLimit
+	}
+	return svc.repo.FindDue(ctx, time.Now(), limit)
+}
+
 func (svc *codingProblemService) GetProblemsByDifficulty(ctx context.Context, difficulty string) ([]domain.CodingProblem, error) {
 	return svc.repo.FindByDifficulty(ctx, difficulty)
 }
@@ -69,27 +82,43 @@ func (svc *codingProblemService) DeleteProblem(ctx context.Context, id int64) er
 }
 
 // 每日一题相关方法
-func (svc *codingProblemService) GetDailyProblem(ctx context.Context) (*domain.CodingProblem, error) {
-	return svc.repo.GetDailyProblem(ctx)
+func (svc *codingProblemService) GetDailyProblem(ctx context.Context, source string) (*domain.CodingProblem, error) {
+	src, err := svc.sources.Get(source)
+	if err != nil {
+		return nil, err
+	}
+	return svc.repo.GetDailyProblem(ctx, src.Name(), src.Today())
 }
 
 func (svc *codingProblemService) GetDailyProblemHistory(ctx context.Context) ([]domain.CodingProblem, error) {
 	return svc.repo.GetDailyProblemHistory(ctx)
 }
 
-func (svc *codingProblemService) SetDailyProblem(ctx context.Context, problemId int64) error {
-	return svc.repo.SetDailyProblem(ctx, problemId)
+func (svc *codingProblemService) SetDailyProblem(ctx context.Context, source string, problemId int64) error {
+	src, err := svc.sources.Get(source)
+	if err != nil {
+		return err
+	}
+	return svc.repo.SetDailyProblem(ctx, src.Name(), problemId, src.Today())
 }
 
 // 爬虫相关方法实现
-func (svc *codingProblemService) CrawlDailyProblem(ctx context.Context) error {
-	return svc.crawler.CrawlAndSaveDailyProblem(ctx)
+func (svc *codingProblemService) CrawlDailyProblem(ctx context.Context, source string) error {
+	return svc.crawler.CrawlAndSaveDaily(ctx, source)
 }
 
-func (svc *codingProblemService) CrawlProblemBySlug(ctx context.Context, titleSlug string) (*domain.CodingProblem, error) {
-	return svc.crawler.CrawlProblemBySlug(ctx, titleSlug)
+func (svc *codingProblemService) CrawlProblemBySlug(ctx context.Context, source, titleSlug string) (*domain.CodingProblem, error) {
+	src, err := svc.sources.Get(source)
+	if err != nil {
+		return nil, err
+	}
+	return src.FetchBySlug(ctx, titleSlug)
 }
 
-func (svc *codingProblemService) StartDailyCrawler(ctx context.Context) error {
-	return svc.crawler.StartDailyCrawler(ctx)
+func (svc *codingProblemService) CrawlProblemById(ctx context.Context, source, id string) (*domain.CodingProblem, error) {
+	src, err := svc.sources.Get(source)
+	if err != nil {
+		return nil, err
+	}
+	return src.FetchById(ctx, id)
 }
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"time"
+)
+
+// resolveIntervals 做对后第 n 次重做距上次的天数，超过最后一档后保持最后一档
+var resolveIntervals = []int{1, 3, 7, 14, 30, 60, 120}
+
+// scheduleResolve 根据本次做题结果更新题目的学习状态和重做计划：
+// 做对进入下一档间隔；做错、超时或放弃时，做对过的题目回到第一档，第二天重做，
+// 从没做对过的题目还在学习中，不安排重做
+func scheduleResolve(problem domain.CodingProblem, attempt domain.CodingAttempt, now time.Time) domain.CodingProblem {
+	problem.StudyStatus = attempt.StudyStatus()
+	problem.LastStudied = &now
+
+	if attempt.Result == domain.AttemptAccepted {
+		problem.ReviewStage++
+		idx := min(problem.ReviewStage, len(resolveIntervals)) - 1
+		next := now.AddDate(0, 0, resolveIntervals[idx])
+		problem.NextReviewAt = &next
+		return problem
+	}
+
+	if problem.NextReviewAt != nil {
+		problem.ReviewStage = 0
+		next := now.AddDate(0, 0, 1)
+		problem.NextReviewAt = &next
+	}
+	return problem
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestScheduleResolve(t *testing.T) {
+	now := time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC)
+	earlier := now.AddDate(0, 0, -3)
+	last := len(resolveIntervals)
+	tests := []struct {
+		name      string
+		stage     int
+		scheduled bool // 之前做对过，已经安排了重做
+		result    string
+		wantStage int
+		wantDays  int // 0 表示不安排重做
+	}{
+		{name: "第一次做对", result: domain.AttemptAccepted, wantStage: 1, wantDays: 1},
+		{name: "第二次做对", stage: 1, scheduled: true, result: domain.AttemptAccepted, wantStage: 2, wantDays: 3},
+		{name: "第三次做对", stage: 2, scheduled: true, result: domain.AttemptAccepted, wantStage: 3, wantDays: 7},
+		{name: "进入最后一档", stage: last - 1, scheduled: true, result: domain.AttemptAccepted,
+			wantStage: last, wantDays: resolveIntervals[last-1]},
+		{name: "超过最后一档保持最后一档", stage: last, scheduled: true, result: domain.AttemptAccepted,
+			wantStage: last + 1, wantDays: resolveIntervals[last-1]},
+		{name: "做对过再做错回到第一档", stage: 4, scheduled: true, result: domain.AttemptWrong, wantStage: 0, wantDays: 1},
+		{name: "做对过再超时回到第一档", stage: 2, scheduled: true, result: domain.AttemptTimeout, wantStage: 0, wantDays: 1},
+		{name: "做对过再放弃回到第一档", stage: 1, scheduled: true, result: domain.AttemptGaveUp, wantStage: 0, wantDays: 1},
+		{name: "从没做对过做错不安排重做", result: domain.AttemptWrong},
+		{name: "从没做对过放弃不安排重做", result: domain.AttemptGaveUp},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem := domain.CodingProblem{ReviewStage: tt.stage}
+			if tt.scheduled {
+				problem.NextReviewAt = &earlier
+			}
+			attempt := domain.CodingAttempt{Result: tt.result}
+			got := scheduleResolve(problem, attempt, now)
+
+			if got.ReviewStage != tt.wantStage {
+				t.Errorf("stage = %d, want %d", got.ReviewStage, tt.wantStage)
+			}
+			switch {
+			case tt.wantDays == 0 && got.NextReviewAt != nil:
+				t.Errorf("next review at = %v, want none", *got.NextReviewAt)
+			case tt.wantDays > 0 && (got.NextReviewAt == nil || !got.NextReviewAt.Equal(now.AddDate(0, 0, tt.wantDays))):
+				t.Errorf("next review at = %v, want %d days later", got.NextReviewAt, tt.wantDays)
+			}
+			if got.StudyStatus != attempt.StudyStatus() || got.LastStudied == nil || !got.LastStudied.Equal(now) {
+				t.Errorf("status = %s, last studied = %v", got.StudyStatus, got.LastStudied)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"log"
+	"os"
+	"sync"
+	"time"
+	// 内置时区数据，没有安装 tzdata 的环境也能加载 Asia/Shanghai
+	_ "time/tzdata"
+)
+
+// defaultDailyTimezone leetcode.cn 的每日一题和定时爬取按这个时区划分日期，
+// 可以通过环境变量 DAILY_TZ 修改，例如 UTC、America/New_York；其他站点使用各自的时区
+const defaultDailyTimezone = "Asia/Shanghai"
+
+// DailyLocation 每日一题使用的时区，只在第一次调用时读取 DAILY_TZ
+var DailyLocation = sync.OnceValue(func() *time.Location {
+	name := os.Getenv("DAILY_TZ")
+	if name == "" {
+		name = defaultDailyTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("无效的 DAILY_TZ %q，使用 %s: %v", name, defaultDailyTimezone, err)
+		loc, _ = time.LoadLocation(defaultDailyTimezone)
+	}
+	return loc
+})
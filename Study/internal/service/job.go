@@ -0,0 +1,308 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/pkg/cron"
+	"Training/Study/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 内置的后台任务
+const (
+	JobDailyProblem  = "daily_problem"
+	JobTrashPurge    = "trash_purge"
+	JobProblemEnrich = "problem_enrich"
+)
+
+const (
+	// 失败后重试的等待时间，从 jobRetryBase 开始每次翻倍，最多 jobRetryMax；
+	// 到下一次定时运行时不再重试
+	jobRetryBase = 5 * time.Minute
+	jobRetryMax  = time.Hour
+	// jobRunHistory 查询运行记录时默认返回的条数
+	jobRunHistory = 20
+)
+
+var (
+	ErrUnknownJob = errors.New("未知的后台任务")
+	ErrJobRunning = errors.New("任务正在运行")
+)
+
+// JobFunc 任务的执行函数，ctx 结束时应尽快返回
+type JobFunc func(ctx context.Context) error
+
+type JobRunner interface {
+	// Start 按各任务的 cron 表达式运行任务，直到 ctx 结束
+	Start(ctx context.Context) error
+	// List 所有任务及其最近一次运行
+	List(ctx context.Context) ([]domain.Job, error)
+	// Runs 任务最近的运行记录，按开始时间倒序
+	Runs(ctx context.Context, name string, limit int) ([]domain.JobRun, error)
+	// Trigger 在后台立即运行任务，返回这次运行的记录；任务正在运行时返回 ErrJobRunning
+	Trigger(ctx context.Context, name string) (domain.JobRun, error)
+}
+
+type job struct {
+	name     string
+	schedule cron.Schedule
+	// location cron 表达式使用的时区
+	location *time.Location
+	fn       JobFunc
+	// running 同一个任务同时只运行一次，定时运行和手动触发共用
+	running atomic.Bool
+}
+
+type jobRunner struct {
+	repo   repository.JobRunRepository
+	jobs   []*job
+	byName map[string]*job
+	// retryBase 第一次重试前的等待时间
+	retryBase time.Duration
+}
+
+func NewJobRunner(repo repository.JobRunRepository, crawler *LeetCodeCrawler, trashService TrashService,
+	enrichService ProblemEnrichService) JobRunner {
+	r := newJobRunner(repo)
+	// 每个来源在自己时区的 0 点更新每日一题，晚一分钟避免拿到前一天的题
+	for _, src := range crawler.sources.All() {
+		r.register(dailyJobName(src.Name()), "1 0 * * *", src.DayLocation(), func(ctx context.Context) error {
+			return crawler.CrawlAndSaveDaily(ctx, src.Name())
+		})
+	}
+	r.register(JobTrashPurge, "0 * * * *", time.Local, func(ctx context.Context) error {
+		n, err := trashService.Purge(ctx)
+		if n > 0 {
+			log.Printf("回收站已清理 %d 条过期记录", n)
+		}
+		return err
+	})
+	r.register(JobProblemEnrich, "*/10 * * * *", time.Local, func(ctx context.Context) error {
+		n, err := enrichService.EnrichPending(ctx)
+		if n > 0 {
+			log.Printf("已补全 %d 道题目的信息", n)
+		}
+		return err
+	})
+	return r
+}
+
+// dailyJobName 抓取来源 source 每日一题的任务名，默认来源沿用 JobDailyProblem
+func dailyJobName(source string) string {
+	if source == DefaultProblemSource {
+		return JobDailyProblem
+	}
+	return JobDailyProblem + "_" + source
+}
+
+func newJobRunner(repo repository.JobRunRepository) *jobRunner {
+	return &jobRunner{
+		repo:      repo,
+		byName:    make(map[string]*job),
+		retryBase: jobRetryBase,
+	}
+}
+
+func (r *jobRunner) register(name, spec string, loc *time.Location, fn JobFunc) {
+	j := &job{name: name, schedule: cron.MustParse(spec), location: loc, fn: fn}
+	r.jobs = append(r.jobs, j)
+	r.byName[name] = j
+}
+
+func (r *jobRunner) Start(ctx context.Context) error {
+	// 上次进程退出时还在运行的记录不会再结束了
+	if n, err := r.repo.FailRunning(ctx, "服务重启，运行中断"); err != nil {
+		return err
+	} else if n > 0 {
+		log.Printf("⚠️  %d 个后台任务在上次退出时被中断", n)
+	}
+
+	var wg sync.WaitGroup
+	for _, j := range r.jobs {
+		wg.Add(1)
+		go func(j *job) {
+			defer wg.Done()
+			r.loop(ctx, j)
+		}(j)
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// loop 按 cron 表达式运行一个任务，失败时在下一次定时运行前按退避时间重试
+func (r *jobRunner) loop(ctx context.Context, j *job) {
+	var (
+		pending time.Time // 下一次重试或补跑的时间，为零表示没有
+		trigger string
+		attempt int
+	)
+	if r.missed(ctx, j) {
+		pending, trigger = time.Now(), domain.JobTriggerCatchUp
+	}
+
+	for {
+		at := j.next(time.Now())
+		if pending.IsZero() || !pending.Before(at) {
+			pending, trigger, attempt = time.Time{}, domain.JobTriggerSchedule, 0
+		} else {
+			at = pending
+		}
+
+		timer := time.NewTimer(time.Until(at))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		run, err := r.begin(ctx, j, trigger, attempt+1)
+		if errors.Is(err, ErrJobRunning) {
+			if pending.IsZero() {
+				log.Printf("后台任务 %s 正在运行，跳过这次定时运行", j.name)
+				continue
+			}
+			// 手动触发的运行还没结束，保留这次重试或补跑，等 retryBase 后再试
+			log.Printf("后台任务 %s 正在运行，%v 后再尝试 %s 运行", j.name, r.retryBase, trigger)
+			pending = time.Now().Add(r.retryBase)
+			continue
+		}
+		pending = time.Time{}
+		attempt++
+		if err != nil {
+			log.Printf("记录后台任务 %s 的运行失败: %v", j.name, err)
+			continue
+		}
+		if err := r.run(ctx, j, run); err != nil && ctx.Err() == nil {
+			delay := r.retryDelay(attempt)
+			log.Printf("后台任务 %s 第 %d 次运行失败: %v，%v 后重试", j.name, attempt, err, delay)
+			pending, trigger = time.Now().Add(delay), domain.JobTriggerRetry
+		}
+	}
+}
+
+// missed 最近一次成功运行之后已经到过定时运行的时间，例如服务在那个时间没有运行
+func (r *jobRunner) missed(ctx context.Context, j *job) bool {
+	last, err := r.repo.FindLastByStatus(ctx, j.name, domain.JobSucceeded)
+	if errors.Is(err, repository.ErrJobRunNotFound) {
+		return true
+	}
+	if err != nil {
+		log.Printf("查询后台任务 %s 的运行记录失败: %v", j.name, err)
+		return false
+	}
+	return !j.next(last.StartedAt).After(time.Now())
+}
+
+// next t 之后下一次定时运行的时间
+func (j *job) next(t time.Time) time.Time {
+	return j.schedule.Next(t.In(j.location))
+}
+
+// retryDelay 第 attempt 次运行失败后等待的时间
+func (r *jobRunner) retryDelay(attempt int) time.Duration {
+	delay := r.retryBase
+	for i := 1; i < attempt && delay < jobRetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, jobRetryMax)
+}
+
+// begin 占用任务并保存运行记录，任务正在运行时返回 ErrJobRunning
+func (r *jobRunner) begin(ctx context.Context, j *job, trigger string, attempt int) (domain.JobRun, error) {
+	if !j.running.CompareAndSwap(false, true) {
+		return domain.JobRun{}, ErrJobRunning
+	}
+	run := domain.JobRun{
+		Job:       j.name,
+		Trigger:   trigger,
+		Attempt:   attempt,
+		Status:    domain.JobRunning,
+		StartedAt: time.Now(),
+	}
+	id, err := r.repo.Create(ctx, run)
+	if err != nil {
+		j.running.Store(false)
+		return domain.JobRun{}, err
+	}
+	run.Id = id
+	return run, nil
+}
+
+// run 执行任务并保存结果，结束后释放任务
+func (r *jobRunner) run(ctx context.Context, j *job, run domain.JobRun) (err error) {
+	defer j.running.Store(false)
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+		now := time.Now()
+		run.FinishedAt = &now
+		run.Status = domain.JobSucceeded
+		if err != nil {
+			run.Status = domain.JobFailed
+			run.Error = err.Error()
+		}
+		// ctx 结束时也要保存结果
+		if saveErr := r.repo.Update(context.WithoutCancel(ctx), run); saveErr != nil {
+			log.Printf("保存后台任务 %s 的运行结果失败: %v", j.name, saveErr)
+		}
+	}()
+	return j.fn(ctx)
+}
+
+func (r *jobRunner) List(ctx context.Context) ([]domain.Job, error) {
+	now := time.Now()
+	jobs := make([]domain.Job, 0, len(r.jobs))
+	for _, j := range r.jobs {
+		item := domain.Job{
+			Name:      j.name,
+			Schedule:  j.schedule.String(),
+			Timezone:  j.location.String(),
+			Running:   j.running.Load(),
+			NextRunAt: j.next(now),
+		}
+		runs, err := r.repo.FindByJob(ctx, j.name, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(runs) > 0 {
+			item.LastRun = &runs[0]
+		}
+		jobs = append(jobs, item)
+	}
+	return jobs, nil
+}
+
+func (r *jobRunner) Runs(ctx context.Context, name string, limit int) ([]domain.JobRun, error) {
+	if _, ok := r.byName[name]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	if limit <= 0 {
+		limit = jobRunHistory
+	}
+	return r.repo.FindByJob(ctx, name, limit)
+}
+
+func (r *jobRunner) Trigger(ctx context.Context, name string) (domain.JobRun, error) {
+	j, ok := r.byName[name]
+	if !ok {
+		return domain.JobRun{}, fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	run, err := r.begin(ctx, j, domain.JobTriggerManual, 1)
+	if err != nil {
+		return domain.JobRun{}, err
+	}
+	// 请求结束后任务继续在后台运行
+	go func() {
+		if err := r.run(context.WithoutCancel(ctx), j, run); err != nil {
+			log.Printf("手动运行后台任务 %s 失败: %v", j.name, err)
+		}
+	}()
+	return run, nil
+}
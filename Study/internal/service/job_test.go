@@ -0,0 +1,132 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memJobRunRepo 在内存中保存运行记录，finished 在每次运行结束时收到通知
+type memJobRunRepo struct {
+	mu       sync.Mutex
+	runs     []domain.JobRun
+	finished chan domain.JobRun
+}
+
+func (r *memJobRunRepo) Create(ctx context.Context, run domain.JobRun) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs = append(r.runs, run)
+	return int64(len(r.runs)), nil
+}
+
+func (r *memJobRunRepo) Update(ctx context.Context, run domain.JobRun) error {
+	r.finished <- run
+	return nil
+}
+
+func (r *memJobRunRepo) FindByJob(ctx context.Context, job string, limit int) ([]domain.JobRun, error) {
+	return nil, nil
+}
+
+func (r *memJobRunRepo) FindLastByStatus(ctx context.Context, job, status string) (domain.JobRun, error) {
+	return domain.JobRun{}, repository.ErrJobRunNotFound
+}
+
+func (r *memJobRunRepo) FailRunning(ctx context.Context, reason string) (int64, error) {
+	return 0, nil
+}
+
+// triggers 按创建顺序返回运行的触发方式和第几次运行
+func (r *memJobRunRepo) triggers() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var res []string
+	for _, run := range r.runs {
+		res = append(res, fmt.Sprintf("%s#%d", run.Trigger, run.Attempt))
+	}
+	return res
+}
+
+func TestJobRetryWaitsForManualRun(t *testing.T) {
+	repo := &memJobRunRepo{finished: make(chan domain.JobRun, 10)}
+	r := newJobRunner(repo)
+	r.retryBase = 100 * time.Millisecond
+
+	release := make(chan struct{})
+	calls := 0
+	// 每年运行一次，测试期间只有补跑、手动运行和重试
+	r.register("test", "0 0 1 1 *", time.UTC, func(ctx context.Context) error {
+		calls++
+		switch calls {
+		case 1:
+			return errors.New("第一次失败")
+		case 2:
+			// 手动运行在重试时间之后才结束
+			<-release
+		}
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.loop(ctx, r.byName["test"])
+
+	// 启动时补跑失败，100ms 后重试
+	if run := <-repo.finished; run.Status != domain.JobFailed {
+		t.Fatalf("catch-up run status = %s, want failed", run.Status)
+	}
+	var err error
+	for {
+		if _, err = r.Trigger(ctx, "test"); !errors.Is(err, ErrJobRunning) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 重试时手动运行还没结束，重试应当保留而不是等到下一次定时运行
+	time.Sleep(2*r.retryBase + 50*time.Millisecond)
+	close(release)
+	<-repo.finished
+
+	select {
+	case run := <-repo.finished:
+		if run.Status != domain.JobSucceeded {
+			t.Errorf("retry status = %s, want succeeded", run.Status)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("pending retry was dropped")
+	}
+	want := []string{"catch_up#1", "manual#1", "retry#2"}
+	if got := repo.triggers(); !reflect.DeepEqual(got, want) {
+		t.Errorf("runs = %v, want %v", got, want)
+	}
+}
+
+func TestDailyJobPerSource(t *testing.T) {
+	sources := NewProblemSourceRegistry(NewLeetCodeSource(LeetCodeCN), NewLeetCodeSource(LeetCodeUS))
+	r := NewJobRunner(&memJobRunRepo{}, NewLeetCodeCrawler(sources, nil, nil), nil, nil).(*jobRunner)
+	// 每个来源在自己的时区换日
+	want := map[string]*time.Location{
+		JobDailyProblem:                  DailyLocation(),
+		JobDailyProblem + "_leetcode_us": time.UTC,
+	}
+	for name, loc := range want {
+		j, ok := r.byName[name]
+		if !ok {
+			t.Errorf("job %s not registered", name)
+			continue
+		}
+		if j.location != loc {
+			t.Errorf("job %s location = %v, want %v", name, j.location, loc)
+		}
+	}
+}
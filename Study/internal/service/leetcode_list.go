@@ -0,0 +1,269 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/pkg/graphql"
+	"Training/Study/internal/repository"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 可以导入的 LeetCode 题单类型
+const (
+	LeetCodeStudyPlan = "study_plan"
+	LeetCodeFavorite  = "favorite"
+)
+
+// favoritePageSize 收藏夹每页拉取的题目数
+const favoritePageSize = 100
+
+var ErrInvalidListImport = errors.New("题单类型必须是 study_plan 或 favorite，且 slug 不能为空")
+
+// leetCodeListQuestion 题库、题单中的题目，字段与 GraphQL 返回一致
+type leetCodeListQuestion struct {
+	QuestionFrontendId string             `json:"questionFrontendId"`
+	Title              string             `json:"title"`
+	TranslatedTitle    string             `json:"translatedTitle"`
+	TitleSlug          string             `json:"titleSlug"`
+	Difficulty         string             `json:"difficulty"`
+	PaidOnly           bool               `json:"paidOnly"`
+	AcRate             float64            `json:"acRate"` // 学习计划和收藏夹不返回
+	TopicTags          []leetCodeTopicTag `json:"topicTags"`
+}
+
+type leetCodeStudyPlanData struct {
+	StudyPlanV2Detail *struct {
+		Slug          string `json:"slug"`
+		Name          string `json:"name"`
+		Description   string `json:"description"`
+		PlanSubGroups []struct {
+			Name      string                 `json:"name"`
+			Questions []leetCodeListQuestion `json:"questions"`
+		} `json:"planSubGroups"`
+	} `json:"studyPlanV2Detail"`
+}
+
+type leetCodeFavoriteData struct {
+	FavoriteDetailV2 *struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	} `json:"favoriteDetailV2"`
+	FavoriteQuestionList *struct {
+		Questions   []leetCodeListQuestion `json:"questions"`
+		TotalLength int                    `json:"totalLength"`
+		HasMore     bool                   `json:"hasMore"`
+	} `json:"favoriteQuestionList"`
+}
+
+var studyPlanOp = graphql.Operation{Name: "studyPlanDetail", Query: `
+query studyPlanDetail($slug: String!) {
+	studyPlanV2Detail(planSlug: $slug) {
+		slug
+		name
+		description
+		planSubGroups {
+			name
+			questions {
+				questionFrontendId
+				title
+				translatedTitle
+				titleSlug
+				difficulty
+				paidOnly
+				topicTags {
+					name
+					slug
+				}
+			}
+		}
+	}
+}`}
+
+var favoriteOp = graphql.Operation{Name: "favoriteQuestionList", Query: `
+query favoriteQuestionList($favoriteSlug: String!, $skip: Int, $limit: Int) {
+	favoriteDetailV2(favoriteSlug: $favoriteSlug) {
+		name
+		description
+	}
+	favoriteQuestionList(favoriteSlug: $favoriteSlug, skip: $skip, limit: $limit) {
+		questions {
+			questionFrontendId
+			title
+			translatedTitle
+			titleSlug
+			difficulty
+			paidOnly
+			topicTags {
+				name
+				slug
+			}
+		}
+		totalLength
+		hasMore
+	}
+}`}
+
+// ImportList 导入 LeetCode 学习计划(study_plan)或收藏夹(favorite)：
+// 逐题抓取详情并按来源题号新增或更新题目，再按原顺序保存为题单，重复导入会更新同一个题单
+func (c *LeetCodeCrawler) ImportList(ctx context.Context, listType, slug string) (domain.ProblemListImport, error) {
+	slug = strings.TrimSpace(slug)
+	if slug == "" {
+		return domain.ProblemListImport{}, ErrInvalidListImport
+	}
+	source, err := c.sources.Get(DefaultProblemSource)
+	if err != nil {
+		return domain.ProblemListImport{}, err
+	}
+	src, ok := source.(ProblemListSource)
+	if !ok {
+		return domain.ProblemListImport{}, fmt.Errorf("题目来源 %s 不支持导入题单", source.Name())
+	}
+
+	list, problems, err := src.FetchList(ctx, listType, slug)
+	if err != nil {
+		return domain.ProblemListImport{}, err
+	}
+	c.logger.Printf("开始导入题单: %s，共 %d 题", list.Name, len(problems))
+
+	res := domain.ProblemListImport{
+		Total:   len(problems),
+		Skipped: []domain.ProblemListImportSkip{},
+	}
+	ids := make([]int64, 0, len(problems))
+	for _, p := range problems {
+		if err := ctx.Err(); err != nil {
+			return domain.ProblemListImport{}, err
+		}
+		problem, created, err := c.repository.Upsert(ctx, c.listedProblemDetail(ctx, src, p))
+		switch {
+		case errors.Is(err, repository.ErrProblemTrashed):
+			res.Skipped = append(res.Skipped, domain.ProblemListImportSkip{TitleSlug: p.TitleSlug, Reason: "题目在回收站中"})
+			continue
+		case err != nil:
+			return domain.ProblemListImport{}, fmt.Errorf("保存题目 %s 失败: %w", p.TitleSlug, err)
+		case created:
+			res.Created++
+		default:
+			res.Updated++
+		}
+		ids = append(ids, problem.Id)
+	}
+
+	res.List, err = c.listRepo.SaveImported(ctx, list, ids)
+	if err != nil {
+		return domain.ProblemListImport{}, err
+	}
+	res.List.ProblemCount = int64(len(ids))
+	c.logger.Printf("题单导入完成: %s，新增 %d，更新 %d，跳过 %d", list.Name, res.Created, res.Updated, len(res.Skipped))
+	return res, nil
+}
+
+// listedProblemDetail 抓取题目详情，失败时退回题单中的基本信息（没有描述）
+func (c *LeetCodeCrawler) listedProblemDetail(ctx context.Context, src ProblemSource, p domain.CodingProblem) domain.CodingProblem {
+	if !p.PaidOnly {
+		problem, err := src.FetchBySlug(ctx, p.TitleSlug)
+		if err == nil {
+			return *problem
+		}
+		c.logger.Printf("获取题目 %s 详情失败，使用题单中的信息: %v", p.TitleSlug, err)
+	}
+	return p
+}
+
+// FetchList 获取学习计划(study_plan)或收藏夹(favorite)
+func (s *LeetCodeSource) FetchList(ctx context.Context, listType, slug string) (domain.ProblemList, []domain.CodingProblem, error) {
+	var (
+		list      domain.ProblemList
+		questions []leetCodeListQuestion
+		err       error
+	)
+	switch listType {
+	case LeetCodeStudyPlan:
+		list, questions, err = s.fetchStudyPlan(ctx, slug)
+	case LeetCodeFavorite:
+		list, questions, err = s.fetchFavorite(ctx, slug)
+	default:
+		return domain.ProblemList{}, nil, ErrInvalidListImport
+	}
+	if err != nil {
+		return domain.ProblemList{}, nil, err
+	}
+	problems := make([]domain.CodingProblem, 0, len(questions))
+	for _, q := range questions {
+		problems = append(problems, s.listedProblem(q))
+	}
+	return list, problems, nil
+}
+
+func (s *LeetCodeSource) fetchStudyPlan(ctx context.Context, slug string) (domain.ProblemList, []leetCodeListQuestion, error) {
+	data, err := graphql.Query[leetCodeStudyPlanData](ctx, s.gql, studyPlanOp, map[string]interface{}{"slug": slug})
+	if err != nil {
+		return domain.ProblemList{}, nil, fmt.Errorf("获取学习计划失败: %w", err)
+	}
+	plan := data.StudyPlanV2Detail
+	if plan == nil {
+		return domain.ProblemList{}, nil, fmt.Errorf("学习计划 %s: %w", slug, graphql.ErrNotFound)
+	}
+
+	var questions []leetCodeListQuestion
+	for _, group := range plan.PlanSubGroups {
+		questions = append(questions, group.Questions...)
+	}
+	return domain.ProblemList{
+		Name:        fallbackTitle(plan.Name, slug),
+		Description: plan.Description,
+		Source:      s.site.Name + "_" + LeetCodeStudyPlan,
+		SourceSlug:  slug,
+	}, questions, nil
+}
+
+// fetchFavorite 分页拉取收藏夹中的全部题目
+func (s *LeetCodeSource) fetchFavorite(ctx context.Context, slug string) (domain.ProblemList, []leetCodeListQuestion, error) {
+	list := domain.ProblemList{
+		Name:       slug,
+		Source:     s.site.Name + "_" + LeetCodeFavorite,
+		SourceSlug: slug,
+	}
+	var questions []leetCodeListQuestion
+	for skip := 0; ; {
+		data, err := graphql.Query[leetCodeFavoriteData](ctx, s.gql, favoriteOp, map[string]interface{}{
+			"favoriteSlug": slug,
+			"skip":         skip,
+			"limit":        favoritePageSize,
+		})
+		if err != nil {
+			return domain.ProblemList{}, nil, fmt.Errorf("获取收藏夹失败: %w", err)
+		}
+		page := data.FavoriteQuestionList
+		if page == nil {
+			return domain.ProblemList{}, nil, fmt.Errorf("收藏夹 %s: %w", slug, graphql.ErrNotFound)
+		}
+		if detail := data.FavoriteDetailV2; detail != nil && skip == 0 {
+			list.Name = fallbackTitle(detail.Name, slug)
+			list.Description = detail.Description
+		}
+
+		questions = append(questions, page.Questions...)
+		skip += len(page.Questions)
+		// 没有更多或者本页为空时结束，避免服务端返回异常时死循环
+		if !page.HasMore || len(page.Questions) == 0 {
+			break
+		}
+	}
+	return list, questions, nil
+}
+
+// normalizeDifficulty 题单接口返回 EASY 这样的大写难度，统一为题目详情中的 Easy
+func normalizeDifficulty(difficulty string) string {
+	switch strings.ToUpper(difficulty) {
+	case "EASY":
+		return "Easy"
+	case "MEDIUM":
+		return "Medium"
+	case "HARD":
+		return "Hard"
+	}
+	return difficulty
+}
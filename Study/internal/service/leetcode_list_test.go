@@ -0,0 +1,303 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/pkg/crawlhttp"
+	"Training/Study/internal/repository"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// graphqlRequest 模拟服务收到的 GraphQL 请求
+type graphqlRequest struct {
+	OperationName string                 `json:"operationName"`
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// fakeLeetCode 模拟 LeetCode 的 GraphQL 接口，handle 返回响应中 data 的内容，
+// 返回 nil 时响应 404
+type fakeLeetCode struct {
+	mu       sync.Mutex
+	requests []graphqlRequest
+	handle   func(req graphqlRequest) interface{}
+}
+
+func (f *fakeLeetCode) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req graphqlRequest
+	if r.URL.Path != "/graphql/" || json.NewDecoder(r.Body).Decode(&req) != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	f.mu.Unlock()
+
+	data := f.handle(req)
+	if data == nil {
+		http.NotFound(w, r)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+// operations 按顺序返回收到的请求中名为 name 的操作
+func (f *fakeLeetCode) operations(name string) []graphqlRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var res []graphqlRequest
+	for _, req := range f.requests {
+		if req.OperationName == name {
+			res = append(res, req)
+		}
+	}
+	return res
+}
+
+// newFakeLeetCodeSource 创建指向模拟服务的 LeetCode 来源，测试中不限速也不重试
+func newFakeLeetCodeSource(t *testing.T, site LeetCodeSite, handle func(req graphqlRequest) interface{}) (*LeetCodeSource, *fakeLeetCode) {
+	t.Helper()
+	fake := &fakeLeetCode{handle: handle}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	src := NewLeetCodeSource(site)
+	src.client = crawlhttp.New(crawlhttp.Config{})
+	src.SetBaseURL(srv.URL)
+	return src, fake
+}
+
+func listQuestion(id int, slug string) map[string]interface{} {
+	return map[string]interface{}{
+		"questionFrontendId": fmt.Sprint(id),
+		"title":              slug,
+		"titleSlug":          slug,
+		"difficulty":         "MEDIUM",
+		"topicTags":          []map[string]string{{"name": "数组", "slug": "array"}},
+	}
+}
+
+func TestFetchFavoritePaging(t *testing.T) {
+	const total = 5
+	src, fake := newFakeLeetCodeSource(t, LeetCodeCN, func(req graphqlRequest) interface{} {
+		skip := int(req.Variables["skip"].(float64))
+		// 每页只返回两题，与请求的 limit 无关，应按 hasMore 和已取到的数量继续翻页
+		var questions []map[string]interface{}
+		for i := skip; i < min(skip+2, total); i++ {
+			questions = append(questions, listQuestion(i+1, fmt.Sprintf("q%d", i+1)))
+		}
+		return map[string]interface{}{
+			"favoriteDetailV2": map[string]string{"name": "我的收藏", "description": "desc"},
+			"favoriteQuestionList": map[string]interface{}{
+				"questions":   questions,
+				"totalLength": total,
+				"hasMore":     skip+len(questions) < total,
+			},
+		}
+	})
+
+	list, problems, err := src.FetchList(context.Background(), LeetCodeFavorite, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var skips []float64
+	for _, req := range fake.operations(favoriteOp.Name) {
+		if req.Variables["favoriteSlug"] != "abc" || req.Variables["limit"] != float64(favoritePageSize) {
+			t.Errorf("unexpected variables %v", req.Variables)
+		}
+		skips = append(skips, req.Variables["skip"].(float64))
+	}
+	if want := []float64{0, 2, 4}; !reflect.DeepEqual(skips, want) {
+		t.Errorf("skips = %v, want %v", skips, want)
+	}
+
+	if list.Name != "我的收藏" || list.Description != "desc" ||
+		list.Source != "leetcode_favorite" || list.SourceSlug != "abc" {
+		t.Errorf("unexpected list %+v", list)
+	}
+	var ids []string
+	for _, p := range problems {
+		ids = append(ids, p.SourceId)
+	}
+	if want := []string{"1", "2", "3", "4", "5"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("source ids = %v, want %v", ids, want)
+	}
+	if p := problems[0]; p.Difficulty != "Medium" || p.SourceUrl != "https://leetcode.cn/problems/q1/" ||
+		!reflect.DeepEqual([]string(p.Tags), []string{"数组"}) {
+		t.Errorf("unexpected problem %+v", p)
+	}
+}
+
+func TestFetchFavoriteStopsOnEmptyPage(t *testing.T) {
+	src, fake := newFakeLeetCodeSource(t, LeetCodeCN, func(req graphqlRequest) interface{} {
+		// 服务端异常时 hasMore 一直为 true，空页应当结束翻页
+		return map[string]interface{}{
+			"favoriteQuestionList": map[string]interface{}{
+				"questions": []interface{}{},
+				"hasMore":   true,
+			},
+		}
+	})
+
+	list, problems, err := src.FetchList(context.Background(), LeetCodeFavorite, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(fake.operations(favoriteOp.Name)); n != 1 {
+		t.Errorf("got %d requests, want 1", n)
+	}
+	if list.Name != "abc" || len(problems) != 0 {
+		t.Errorf("unexpected result %+v %+v", list, problems)
+	}
+}
+
+func TestFetchStudyPlanFlattensSubGroups(t *testing.T) {
+	src, fake := newFakeLeetCodeSource(t, LeetCodeCN, func(req graphqlRequest) interface{} {
+		return map[string]interface{}{
+			"studyPlanV2Detail": map[string]interface{}{
+				"slug": "top-interview-150",
+				"name": "面试经典 150 题",
+				"planSubGroups": []map[string]interface{}{
+					{"name": "数组", "questions": []interface{}{listQuestion(88, "merge-sorted-array"), listQuestion(27, "remove-element")}},
+					{"name": "空分组", "questions": []interface{}{}},
+					{"name": "双指针", "questions": []interface{}{listQuestion(125, "valid-palindrome")}},
+				},
+			},
+		}
+	})
+
+	list, problems, err := src.FetchList(context.Background(), LeetCodeStudyPlan, "top-interview-150")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reqs := fake.operations(studyPlanOp.Name)
+	if len(reqs) != 1 || reqs[0].Variables["slug"] != "top-interview-150" {
+		t.Fatalf("unexpected requests %+v", reqs)
+	}
+	if list.Name != "面试经典 150 题" || list.Source != "leetcode_study_plan" || list.SourceSlug != "top-interview-150" {
+		t.Errorf("unexpected list %+v", list)
+	}
+	var slugs []string
+	for _, p := range problems {
+		slugs = append(slugs, p.TitleSlug)
+	}
+	if want := []string{"merge-sorted-array", "remove-element", "valid-palindrome"}; !reflect.DeepEqual(slugs, want) {
+		t.Errorf("slugs = %v, want %v", slugs, want)
+	}
+}
+
+func TestFetchStudyPlanNotFound(t *testing.T) {
+	src, _ := newFakeLeetCodeSource(t, LeetCodeCN, func(req graphqlRequest) interface{} {
+		return map[string]interface{}{"studyPlanV2Detail": nil}
+	})
+	_, _, err := src.FetchList(context.Background(), LeetCodeStudyPlan, "missing")
+	if err == nil {
+		t.Fatal("want error for a missing study plan")
+	}
+}
+
+// listProblemRepo 按来源题号保存题目，trashed 中的题号视为在回收站中
+type listProblemRepo struct {
+	repository.CodingProblemRepository
+	problems map[string]domain.CodingProblem
+	trashed  map[string]bool
+}
+
+func (r *listProblemRepo) Upsert(ctx context.Context, p domain.CodingProblem) (domain.CodingProblem, bool, error) {
+	if r.trashed[p.SourceId] {
+		return domain.CodingProblem{}, false, repository.ErrProblemTrashed
+	}
+	old, ok := r.problems[p.SourceId]
+	if ok {
+		p.Id = old.Id
+	} else {
+		// 编号与来源题号无关，确认题单按来源顺序而不是编号排序
+		p.Id = int64(100 - len(r.problems))
+	}
+	r.problems[p.SourceId] = p
+	return p, !ok, nil
+}
+
+// listRepo 记录保存的题单和题目顺序
+type listRepo struct {
+	repository.ProblemListRepository
+	list domain.ProblemList
+	ids  []int64
+}
+
+func (r *listRepo) SaveImported(ctx context.Context, list domain.ProblemList, problemIds []int64) (domain.ProblemList, error) {
+	r.list, r.ids = list, problemIds
+	list.Id = 1
+	return list, nil
+}
+
+func TestImportListKeepsSourceOrder(t *testing.T) {
+	src, _ := newFakeLeetCodeSource(t, LeetCodeCN, func(req graphqlRequest) interface{} {
+		switch req.OperationName {
+		case studyPlanOp.Name:
+			return map[string]interface{}{
+				"studyPlanV2Detail": map[string]interface{}{
+					"name": "计划",
+					"planSubGroups": []map[string]interface{}{
+						{"questions": []interface{}{listQuestion(3, "c"), listQuestion(1, "a")}},
+						{"questions": []interface{}{listQuestion(4, "d"), listQuestion(2, "b")}},
+					},
+				},
+			}
+		case LeetCodeCN.questionOp.Name:
+			slug := req.Variables["titleSlug"].(string)
+			if slug == "d" {
+				// 详情获取失败时使用题单中的信息
+				return nil
+			}
+			return map[string]interface{}{
+				"question": map[string]interface{}{
+					"questionFrontendId": map[string]string{"a": "1", "b": "2", "c": "3"}[slug],
+					"title":              slug,
+					"titleSlug":          slug,
+					"content":            "<p>" + slug + "</p>",
+					"difficulty":         "Easy",
+				},
+			}
+		}
+		return nil
+	})
+	problems := &listProblemRepo{
+		problems: map[string]domain.CodingProblem{"1": {Id: 7, SourceId: "1"}},
+		trashed:  map[string]bool{"2": true},
+	}
+	lists := &listRepo{}
+	crawler := NewLeetCodeCrawler(NewProblemSourceRegistry(src), problems, lists)
+
+	res, err := crawler.ImportList(context.Background(), LeetCodeStudyPlan, " plan ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []int64{problems.problems["3"].Id, 7, problems.problems["4"].Id}
+	if !reflect.DeepEqual(lists.ids, want) {
+		t.Errorf("saved ids = %v, want %v", lists.ids, want)
+	}
+	if lists.list.SourceSlug != "plan" {
+		t.Errorf("source slug = %q, want %q", lists.list.SourceSlug, "plan")
+	}
+	if res.Total != 4 || res.Created != 2 || res.Updated != 1 || res.List.ProblemCount != 3 {
+		t.Errorf("unexpected result %+v", res)
+	}
+	if len(res.Skipped) != 1 || res.Skipped[0].TitleSlug != "b" {
+		t.Errorf("skipped = %+v, want b", res.Skipped)
+	}
+	if p := problems.problems["3"]; p.Difficulty != "Easy" || p.Description == "" {
+		t.Errorf("problem 3 should use the fetched detail: %+v", p)
+	}
+	if p := problems.problems["4"]; p.Difficulty != "Medium" || p.Description != "" {
+		t.Errorf("problem 4 should fall back to the list entry: %+v", p)
+	}
+}
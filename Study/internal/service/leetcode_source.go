@@ -0,0 +1,436 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/pkg/crawlhttp"
+	"Training/Study/internal/pkg/graphql"
+	"Training/Study/internal/pkg/htmlmd"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// LeetCodeSite LeetCode 站点，CN 和 US 的接口基本一致，只有部分字段和查询不同
+type LeetCodeSite struct {
+	Name    string // 来源名称
+	BaseURL string
+	// baseURLEnv 可以用这个环境变量把接口指向其他地址，例如代理或本地的模拟服务
+	baseURLEnv string
+	// acRateScale 把题库接口返回的通过率换算成百分比，CN 返回的是小数
+	acRateScale float64
+	// dayLocation 站点的每日一题在这个时区的 0 点更新
+	dayLocation func() *time.Location
+	dailyOp     graphql.Operation
+	questionOp  graphql.Operation
+	listOp      graphql.Operation
+}
+
+var (
+	LeetCodeCN = LeetCodeSite{
+		Name:        "leetcode",
+		BaseURL:     "https://leetcode.cn",
+		baseURLEnv:  "LEETCODE_CN_BASE_URL",
+		acRateScale: 100,
+		dayLocation: DailyLocation,
+		dailyOp: graphql.Operation{Name: "questionOfToday", Query: `
+	query questionOfToday {
+		todayRecord {
+			date
+			question {
+				questionFrontendId
+				questionTitleSlug
+				title
+				translatedTitle
+				difficulty
+			}
+		}
+	}`},
+		questionOp: graphql.Operation{Name: "questionData", Query: `
+	query questionData($titleSlug: String!) {
+		question(titleSlug: $titleSlug) {
+			questionId
+			questionFrontendId
+			title
+			translatedTitle
+			titleSlug
+			content
+			difficulty
+			isPaidOnly
+			topicTags {
+				name
+				slug
+			}
+		}
+	}`},
+		listOp: graphql.Operation{Name: "problemsetQuestionList", Query: `
+	query problemsetQuestionList($skip: Int, $limit: Int, $filters: QuestionListFilterInput) {
+		problemsetQuestionList(categorySlug: "", limit: $limit, skip: $skip, filters: $filters) {
+			total
+			questions {
+				questionFrontendId: frontendQuestionId
+				title
+				translatedTitle: titleCn
+				titleSlug
+				difficulty
+				paidOnly
+				acRate
+				topicTags {
+					name
+					slug
+				}
+			}
+		}
+	}`},
+	}
+	LeetCodeUS = LeetCodeSite{
+		Name:        "leetcode_us",
+		BaseURL:     "https://leetcode.com",
+		baseURLEnv:  "LEETCODE_US_BASE_URL",
+		acRateScale: 1,
+		// leetcode.com 在 UTC 0 点更新每日一题
+		dayLocation: func() *time.Location { return time.UTC },
+		dailyOp: graphql.Operation{Name: "questionOfToday", Query: `
+	query questionOfToday {
+		activeDailyCodingChallengeQuestion {
+			date
+			question {
+				questionFrontendId
+				questionTitleSlug: titleSlug
+				title
+				difficulty
+			}
+		}
+	}`},
+		questionOp: graphql.Operation{Name: "questionData", Query: `
+	query questionData($titleSlug: String!) {
+		question(titleSlug: $titleSlug) {
+			questionId
+			questionFrontendId
+			title
+			titleSlug
+			content
+			difficulty
+			isPaidOnly
+			topicTags {
+				name
+				slug
+			}
+		}
+	}`},
+		listOp: graphql.Operation{Name: "problemsetQuestionList", Query: `
+	query problemsetQuestionList($skip: Int, $limit: Int, $filters: QuestionListFilterInput) {
+		problemsetQuestionList: questionList(categorySlug: "", limit: $limit, skip: $skip, filters: $filters) {
+			total: totalNum
+			questions: data {
+				questionFrontendId
+				title
+				titleSlug
+				difficulty
+				paidOnly: isPaidOnly
+				acRate
+				topicTags {
+					name
+					slug
+				}
+			}
+		}
+	}`},
+	}
+)
+
+// LeetCodeSource 基于 GraphQL 接口的 LeetCode 题目来源
+type LeetCodeSource struct {
+	client *crawlhttp.Client
+	gql    *graphql.Client
+	site   LeetCodeSite
+	logger *log.Logger
+}
+
+// LeetCode GraphQL 响应中 data 的结构，CN 和 US 通过查询中的别名共用同一套结构
+
+type leetCodeTopicTag struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type leetCodeDailyQuestion struct {
+	QuestionFrontendId string `json:"questionFrontendId"`
+	QuestionTitleSlug  string `json:"questionTitleSlug"`
+	Title              string `json:"title"`
+	TranslatedTitle    string `json:"translatedTitle"`
+	Difficulty         string `json:"difficulty"`
+}
+
+// leetCodeDailyRecord 每日一题及其日期，日期是网站时区中的日期
+type leetCodeDailyRecord struct {
+	Date     domain.CivilDate      `json:"date"`
+	Question leetCodeDailyQuestion `json:"question"`
+}
+
+type leetCodeDailyData struct {
+	// CN
+	TodayRecord []leetCodeDailyRecord `json:"todayRecord"`
+	// US
+	ActiveDailyCodingChallengeQuestion *leetCodeDailyRecord `json:"activeDailyCodingChallengeQuestion"`
+}
+
+type leetCodeQuestionData struct {
+	// 题目不存在时为 null
+	Question *struct {
+		QuestionId         string             `json:"questionId"`
+		QuestionFrontendId string             `json:"questionFrontendId"`
+		Title              string             `json:"title"`
+		TranslatedTitle    string             `json:"translatedTitle"`
+		TitleSlug          string             `json:"titleSlug"`
+		Content            string             `json:"content"`
+		Difficulty         string             `json:"difficulty"`
+		IsPaidOnly         bool               `json:"isPaidOnly"`
+		TopicTags          []leetCodeTopicTag `json:"topicTags"`
+	} `json:"question"`
+}
+
+type leetCodeProblemsetData struct {
+	ProblemsetQuestionList *struct {
+		Total     int                    `json:"total"`
+		Questions []leetCodeListQuestion `json:"questions"`
+	} `json:"problemsetQuestionList"`
+}
+
+// NewLeetCodeSource 创建 LeetCode 题目来源，同一站点的所有请求共用一个限速、重试的客户端
+func NewLeetCodeSource(site LeetCodeSite) *LeetCodeSource {
+	logger := log.New(log.Writer(), fmt.Sprintf("[LeetCode %s] ", site.Name), log.LstdFlags)
+	cfg := crawlhttp.DefaultConfig()
+	cfg.Logger = logger
+
+	s := &LeetCodeSource{
+		client: crawlhttp.New(cfg),
+		site:   site,
+		logger: logger,
+	}
+	baseURL := site.BaseURL
+	if value := os.Getenv(site.baseURLEnv); site.baseURLEnv != "" && value != "" {
+		baseURL = value
+	}
+	s.SetBaseURL(baseURL)
+	return s
+}
+
+func (s *LeetCodeSource) Name() string {
+	return s.site.Name
+}
+
+func (s *LeetCodeSource) DayLocation() *time.Location {
+	return s.site.dayLocation()
+}
+
+// Today 站点每日一题时区中的今天
+func (s *LeetCodeSource) Today() domain.CivilDate {
+	return domain.CivilDateOf(time.Now().In(s.DayLocation()))
+}
+
+// SetBaseURL 修改接口地址，例如指向 httptest 模拟的服务，GraphQL 请求发送到 baseURL/graphql/，
+// 题目链接始终使用站点的地址
+func (s *LeetCodeSource) SetBaseURL(baseURL string) {
+	header := http.Header{}
+	// 设置请求头，模拟浏览器请求
+	header.Set("Content-Type", "application/json")
+	header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	header.Set("Accept", "*/*")
+	header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
+	header.Set("Referer", s.site.BaseURL+"/problemset/all/")
+	header.Set("Origin", s.site.BaseURL)
+	header.Set("X-Requested-With", "XMLHttpRequest")
+
+	// 限速、重试和熔断都由 client 处理，日志中不记录请求和响应的内容
+	s.gql = graphql.NewClient(s.client, strings.TrimRight(baseURL, "/")+"/graphql/", header)
+}
+
+// FetchDaily 获取今日每日一题，获取详情失败时只返回基本信息
+func (s *LeetCodeSource) FetchDaily(ctx context.Context) (*domain.DailyProblem, error) {
+	s.logger.Println("开始通过 GraphQL 接口爬取每日一题...")
+
+	data, err := graphql.Query[leetCodeDailyData](ctx, s.gql, s.site.dailyOp, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取每日一题失败: %w", err)
+	}
+
+	var record leetCodeDailyRecord
+	switch {
+	case len(data.TodayRecord) > 0:
+		record = data.TodayRecord[0]
+	case data.ActiveDailyCodingChallengeQuestion != nil:
+		record = *data.ActiveDailyCodingChallengeQuestion
+	default:
+		return nil, fmt.Errorf("未获取到每日一题: %w", graphql.ErrNotFound)
+	}
+	q, date := record.Question, record.Date
+	// 刚过 0 点时网站可能还是前一天的题，按网站给出的日期保存
+	if date.IsZero() {
+		date = s.Today()
+	}
+
+	// 获取题目详细信息
+	problem, err := s.FetchBySlug(ctx, q.QuestionTitleSlug)
+	if err != nil {
+		s.logger.Printf("获取题目详情失败，使用基本信息: %v", err)
+		// 如果获取详情失败，使用基本信息
+		return &domain.DailyProblem{
+			Date:       date,
+			Title:      fallbackTitle(q.TranslatedTitle, q.Title),
+			Difficulty: q.Difficulty,
+			Tags:       []string{"算法"},
+			Source:     s.site.Name,
+			SourceId:   q.QuestionFrontendId,
+			SourceUrl:  s.problemURL(q.QuestionTitleSlug),
+			TitleSlug:  q.QuestionTitleSlug,
+			Ctime:      time.Now(),
+			Utime:      time.Now(),
+		}, nil
+	}
+
+	// 转换为 DailyProblem
+	dailyProblem := &domain.DailyProblem{
+		Date:        date,
+		Title:       problem.Title,
+		Description: problem.Description,
+		Difficulty:  problem.Difficulty,
+		Tags:        problem.Tags,
+		Source:      problem.Source,
+		SourceId:    problem.SourceId,
+		SourceUrl:   problem.SourceUrl,
+		TitleSlug:   problem.TitleSlug,
+		Ctime:       time.Now(),
+		Utime:       time.Now(),
+	}
+
+	s.logger.Printf("成功获取每日一题: %s (%s)", dailyProblem.Title, dailyProblem.SourceUrl)
+	return dailyProblem, nil
+}
+
+// FetchBySlug 根据题目slug爬取题目详情，题目不存在时返回 graphql.ErrNotFound
+func (s *LeetCodeSource) FetchBySlug(ctx context.Context, titleSlug string) (*domain.CodingProblem, error) {
+	s.logger.Printf("开始爬取题目: %s", titleSlug)
+
+	data, err := graphql.Query[leetCodeQuestionData](ctx, s.gql, s.site.questionOp,
+		map[string]interface{}{"titleSlug": titleSlug})
+	if err != nil {
+		s.logger.Printf("爬取题目失败: %v", err)
+		return nil, fmt.Errorf("爬取题目 %s 失败: %w", titleSlug, err)
+	}
+	question := data.Question
+	if question == nil || question.Title == "" {
+		return nil, fmt.Errorf("题目 %s: %w", titleSlug, graphql.ErrNotFound)
+	}
+
+	// 提取标签
+	tags := make([]string, 0, len(question.TopicTags))
+	for _, tag := range question.TopicTags {
+		tags = append(tags, tag.Name)
+	}
+
+	// LeetCode 返回的描述是 HTML，转换为 Markdown 保存
+	description, err := htmlmd.Convert(question.Content)
+	if err != nil {
+		s.logger.Printf("转换题目描述失败: %v", err)
+	}
+
+	problem := &domain.CodingProblem{
+		Title:       fallbackTitle(question.TranslatedTitle, question.Title),
+		Description: description,
+		Difficulty:  question.Difficulty,
+		Tags:        tags,
+		Source:      s.site.Name,
+		SourceId:    question.QuestionFrontendId,
+		SourceUrl:   s.problemURL(question.TitleSlug),
+		TitleSlug:   question.TitleSlug,
+		PaidOnly:    question.IsPaidOnly,
+		StudyStatus: domain.StudyNotStarted,
+		Ctime:       time.Now(),
+		Utime:       time.Now(),
+	}
+
+	s.logger.Printf("成功爬取题目: %s [%s]", problem.Title, problem.Difficulty)
+	return problem, nil
+}
+
+// FetchById 按题号获取题目详情。GraphQL 没有按题号查询的接口，先在题库中搜索题号找到 slug
+func (s *LeetCodeSource) FetchById(ctx context.Context, id string) (*domain.CodingProblem, error) {
+	page, err := s.listProblems(ctx, 0, 50, map[string]interface{}{"searchKeywords": id})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range page.Problems {
+		if p.SourceId == id {
+			return s.FetchBySlug(ctx, p.TitleSlug)
+		}
+	}
+	return nil, fmt.Errorf("题目 %s: %w", id, graphql.ErrNotFound)
+}
+
+// ListProblems 分页列出题库中的题目
+func (s *LeetCodeSource) ListProblems(ctx context.Context, skip, limit int) (ProblemPage, error) {
+	return s.listProblems(ctx, skip, limit, map[string]interface{}{})
+}
+
+func (s *LeetCodeSource) listProblems(ctx context.Context, skip, limit int, filters map[string]interface{}) (ProblemPage, error) {
+	data, err := graphql.Query[leetCodeProblemsetData](ctx, s.gql, s.site.listOp, map[string]interface{}{
+		"skip":    skip,
+		"limit":   limit,
+		"filters": filters,
+	})
+	if err != nil {
+		return ProblemPage{}, fmt.Errorf("获取题库失败: %w", err)
+	}
+	list := data.ProblemsetQuestionList
+	if list == nil {
+		return ProblemPage{}, errors.New("获取的题库数据为空")
+	}
+
+	page := ProblemPage{
+		Problems: make([]domain.CodingProblem, 0, len(list.Questions)),
+		Total:    list.Total,
+		HasMore:  skip+len(list.Questions) < list.Total && len(list.Questions) > 0,
+	}
+	for _, q := range list.Questions {
+		page.Problems = append(page.Problems, s.listedProblem(q))
+	}
+	return page, nil
+}
+
+// listedProblem 题库、题单中的题目只有基本信息，没有描述
+func (s *LeetCodeSource) listedProblem(q leetCodeListQuestion) domain.CodingProblem {
+	tags := make([]string, 0, len(q.TopicTags))
+	for _, tag := range q.TopicTags {
+		tags = append(tags, tag.Name)
+	}
+	return domain.CodingProblem{
+		Title:       fallbackTitle(q.TranslatedTitle, q.Title),
+		Difficulty:  normalizeDifficulty(q.Difficulty),
+		Tags:        tags,
+		Source:      s.site.Name,
+		SourceId:    q.QuestionFrontendId,
+		SourceUrl:   s.problemURL(q.TitleSlug),
+		TitleSlug:   q.TitleSlug,
+		PaidOnly:    q.PaidOnly,
+		AcRate:      math.Round(q.AcRate*s.site.acRateScale*100) / 100,
+		StudyStatus: domain.StudyNotStarted,
+	}
+}
+
+func (s *LeetCodeSource) problemURL(titleSlug string) string {
+	return fmt.Sprintf("%s/problems/%s/", s.site.BaseURL, titleSlug)
+}
+
+// fallbackTitle 选择标题（优先使用翻译后的标题）
+func fallbackTitle(translatedTitle, originalTitle string) string {
+	if translatedTitle != "" {
+		return translatedTitle
+	}
+	return originalTitle
+}
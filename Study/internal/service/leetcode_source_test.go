@@ -0,0 +1,165 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/pkg/crawlhttp"
+	"Training/Study/internal/repository"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestFetchBySlugEndToEnd 通过带重试的 HTTP 客户端请求模拟的 LeetCode 接口，
+// 第一次请求返回 503，客户端重试后得到题目
+func TestFetchBySlugEndToEnd(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var req graphqlRequest
+		if r.Method != http.MethodPost || r.URL.Path != "/graphql/" ||
+			r.Header.Get("Content-Type") != "application/json" ||
+			r.Header.Get("Referer") != "https://leetcode.com/problemset/all/" ||
+			json.NewDecoder(r.Body).Decode(&req) != nil ||
+			req.OperationName != "questionData" || req.Variables["titleSlug"] != "two-sum" ||
+			!strings.Contains(req.Query, "question(titleSlug: $titleSlug)") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = io.WriteString(w, `{"data":{"question":{
+			"questionId": "1",
+			"questionFrontendId": "1",
+			"title": "Two Sum",
+			"titleSlug": "two-sum",
+			"content": "<p>Given an array <code>nums</code>.</p>",
+			"difficulty": "Easy",
+			"isPaidOnly": false,
+			"topicTags": [{"name": "Array", "slug": "array"}, {"name": "Hash Table", "slug": "hash-table"}]
+		}}}`)
+	}))
+	defer srv.Close()
+
+	src := NewLeetCodeSource(LeetCodeUS)
+	cfg := crawlhttp.DefaultConfig()
+	cfg.Rate = 0
+	cfg.BaseDelay = time.Millisecond
+	cfg.Logger = log.New(io.Discard, "", 0)
+	src.client = crawlhttp.New(cfg)
+	src.SetBaseURL(srv.URL + "/")
+
+	problem, err := src.FetchBySlug(context.Background(), "two-sum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hits.Load() != 2 {
+		t.Errorf("hits = %d, want 2", hits.Load())
+	}
+	if problem.Title != "Two Sum" || problem.Difficulty != "Easy" || problem.Source != "leetcode_us" ||
+		problem.SourceId != "1" || problem.TitleSlug != "two-sum" ||
+		problem.SourceUrl != "https://leetcode.com/problems/two-sum/" ||
+		!reflect.DeepEqual(problem.Tags, []string{"Array", "Hash Table"}) {
+		t.Errorf("unexpected problem %+v", problem)
+	}
+	// 描述由 HTML 转换为 Markdown
+	if !strings.Contains(problem.Description, "`nums`") || strings.Contains(problem.Description, "<p>") {
+		t.Errorf("description = %q, want markdown", problem.Description)
+	}
+}
+
+func TestFetchDailyUsesSiteTimezone(t *testing.T) {
+	tests := []struct {
+		site LeetCodeSite
+		loc  *time.Location
+		data map[string]interface{}
+	}{
+		{LeetCodeCN, DailyLocation(), map[string]interface{}{
+			"todayRecord": []interface{}{map[string]interface{}{
+				"question": map[string]interface{}{"questionFrontendId": "1", "questionTitleSlug": "two-sum", "title": "Two Sum"},
+			}},
+		}},
+		{LeetCodeUS, time.UTC, map[string]interface{}{
+			"activeDailyCodingChallengeQuestion": map[string]interface{}{
+				"question": map[string]interface{}{"questionFrontendId": "1", "questionTitleSlug": "two-sum", "title": "Two Sum"},
+			},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.site.Name, func(t *testing.T) {
+			src, _ := newFakeLeetCodeSource(t, tt.site, func(req graphqlRequest) interface{} {
+				if req.OperationName == tt.site.dailyOp.Name {
+					return tt.data
+				}
+				// 详情获取失败时使用每日一题中的基本信息
+				return nil
+			})
+
+			before := domain.CivilDateOf(time.Now().In(tt.loc))
+			daily, err := src.FetchDaily(context.Background())
+			after := domain.CivilDateOf(time.Now().In(tt.loc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// 测试恰好跨过 0 点时两个日期不同
+			if daily.Date != before && daily.Date != after {
+				t.Errorf("date = %v, want %v", daily.Date, after)
+			}
+			if daily.Source != tt.site.Name || daily.SourceId != "1" || daily.TitleSlug != "two-sum" {
+				t.Errorf("unexpected daily problem %+v", daily)
+			}
+		})
+	}
+}
+
+// dailyProblemRepo 记录标记的每日一题
+type dailyProblemRepo struct {
+	repository.CodingProblemRepository
+	marked map[domain.CivilDate]int64
+}
+
+func (r *dailyProblemRepo) Upsert(ctx context.Context, p domain.CodingProblem) (domain.CodingProblem, bool, error) {
+	p.Id = 1
+	return p, true, nil
+}
+
+func (r *dailyProblemRepo) MarkAsDailyProblem(ctx context.Context, source string, problemId int64, date domain.CivilDate) error {
+	r.marked[date] = problemId
+	return nil
+}
+
+func TestCrawlDailyUsesPublishedDate(t *testing.T) {
+	yesterday := domain.CivilDateOf(time.Now().UTC().AddDate(0, 0, -1))
+	src, _ := newFakeLeetCodeSource(t, LeetCodeUS, func(req graphqlRequest) interface{} {
+		if req.OperationName != LeetCodeUS.dailyOp.Name {
+			return nil
+		}
+		// 网站还没有换日，返回的是前一天的题
+		return map[string]interface{}{
+			"activeDailyCodingChallengeQuestion": map[string]interface{}{
+				"date":     yesterday.String(),
+				"question": map[string]interface{}{"questionFrontendId": "1", "questionTitleSlug": "two-sum", "title": "Two Sum"},
+			},
+		}
+	})
+	repo := &dailyProblemRepo{marked: make(map[domain.CivilDate]int64)}
+	crawler := NewLeetCodeCrawler(NewProblemSourceRegistry(src), repo, nil)
+
+	err := crawler.CrawlAndSaveDaily(context.Background(), LeetCodeUS.Name)
+	// 题目仍按网站给出的日期保存，并返回错误让后台任务稍后重试
+	if !errors.Is(err, ErrDailyNotUpdated) {
+		t.Fatalf("err = %v, want ErrDailyNotUpdated", err)
+	}
+	if len(repo.marked) != 1 || repo.marked[yesterday] != 1 {
+		t.Errorf("marked = %v, want only %v", repo.marked, yesterday)
+	}
+}
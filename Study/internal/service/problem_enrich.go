@@ -0,0 +1,123 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/repository"
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+const (
+	// enrichBatchSize 每次从数据库取出的待补全题目数
+	enrichBatchSize = 20
+	// enrichDelay 两道题之间额外等待的时间。题目来源本身有限速，这里再放慢一些，
+	// 给导入题单、每日一题等用户触发的请求留出余量
+	enrichDelay = 2 * time.Second
+	// 失败后重试的等待时间，从 enrichRetryBase 开始每次翻倍，最多 enrichRetryMax
+	enrichRetryBase = 10 * time.Minute
+	enrichRetryMax  = 24 * time.Hour
+)
+
+type ProblemEnrichService interface {
+	// EnrichPending 补全当前所有需要补全的题目，返回成功补全的题目数
+	EnrichPending(ctx context.Context) (int, error)
+}
+
+type problemEnrichService struct {
+	repo        repository.ProblemEnrichmentRepository
+	problemRepo repository.CodingProblemRepository
+	sources     *ProblemSourceRegistry
+	delay       time.Duration
+}
+
+func NewProblemEnrichService(repo repository.ProblemEnrichmentRepository, problemRepo repository.CodingProblemRepository,
+	sources *ProblemSourceRegistry) ProblemEnrichService {
+	return &problemEnrichService{
+		repo:        repo,
+		problemRepo: problemRepo,
+		sources:     sources,
+		delay:       enrichDelay,
+	}
+}
+
+func (svc *problemEnrichService) EnrichPending(ctx context.Context) (int, error) {
+	enriched := 0
+	for {
+		// 处理过的题目会写入补全记录，失败的要等到重试时间，所以每次都从头查询
+		problems, err := svc.repo.FindPending(ctx, time.Now(), enrichBatchSize)
+		if err != nil {
+			return enriched, err
+		}
+		if len(problems) == 0 {
+			return enriched, nil
+		}
+		for _, problem := range problems {
+			if err := svc.enrich(ctx, problem); err != nil {
+				if ctx.Err() != nil {
+					return enriched, ctx.Err()
+				}
+				log.Printf("补全题目 %s(%s) 失败: %v", problem.Title, problem.TitleSlug, err)
+				if err := svc.fail(ctx, problem.Id, err); err != nil {
+					return enriched, err
+				}
+			} else {
+				enriched++
+			}
+
+			select {
+			case <-ctx.Done():
+				return enriched, ctx.Err()
+			case <-time.After(svc.delay):
+			}
+		}
+	}
+}
+
+// enrich 按 slug 抓取题目详情并更新题目，学习进度保持不变
+func (svc *problemEnrichService) enrich(ctx context.Context, problem domain.CodingProblem) error {
+	source, err := svc.sources.Get(problem.Source)
+	if err != nil {
+		return err
+	}
+	detail, err := source.FetchBySlug(ctx, problem.TitleSlug)
+	if err != nil {
+		return err
+	}
+	// 按编号更新原来的题目：早期保存的题目可能没有来源题号，按来源和题号写入会新增一道重复的题
+	if err := svc.problemRepo.UpdateDetail(ctx, problem.Id, *detail); err != nil {
+		return err
+	}
+	// 会员题等情况下详情中没有描述，也记为完成，不再重复抓取
+	return svc.repo.Save(ctx, domain.ProblemEnrichment{
+		ProblemId: problem.Id,
+		Status:    domain.EnrichDone,
+	})
+}
+
+// fail 记录失败，按失败次数推迟下次重试
+func (svc *problemEnrichService) fail(ctx context.Context, problemId int64, cause error) error {
+	state, err := svc.repo.FindByProblemId(ctx, problemId)
+	if err != nil && !errors.Is(err, repository.ErrProblemEnrichmentNotFound) {
+		return err
+	}
+	attempts := state.Attempts + 1
+	next := time.Now().Add(enrichRetryDelay(attempts))
+	return svc.repo.Save(ctx, domain.ProblemEnrichment{
+		ProblemId:     problemId,
+		Status:        domain.EnrichFailed,
+		Attempts:      attempts,
+		Error:         cause.Error(),
+		NextAttemptAt: &next,
+	})
+}
+
+// enrichRetryDelay 第 attempts 次失败后等待的时间
+func enrichRetryDelay(attempts int) time.Duration {
+	delay := enrichRetryBase
+	for i := 1; i < attempts && delay < enrichRetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, enrichRetryMax)
+}
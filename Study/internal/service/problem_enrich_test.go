@@ -0,0 +1,88 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/repository"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// detailProblemRepo 记录按编号更新的题目详情，调用 Upsert 时测试失败
+type detailProblemRepo struct {
+	repository.CodingProblemRepository
+	t       *testing.T
+	updated map[int64]domain.CodingProblem
+}
+
+func (r *detailProblemRepo) Upsert(ctx context.Context, p domain.CodingProblem) (domain.CodingProblem, bool, error) {
+	r.t.Errorf("unexpected upsert of %+v", p)
+	return p, true, nil
+}
+
+func (r *detailProblemRepo) UpdateDetail(ctx context.Context, id int64, detail domain.CodingProblem) error {
+	r.updated[id] = detail
+	return nil
+}
+
+// enrichmentRepo 第一次查询返回 pending，之后没有待补全的题目
+type enrichmentRepo struct {
+	repository.ProblemEnrichmentRepository
+	pending []domain.CodingProblem
+	saved   []domain.ProblemEnrichment
+}
+
+func (r *enrichmentRepo) FindPending(ctx context.Context, now time.Time, limit int) ([]domain.CodingProblem, error) {
+	res := r.pending
+	r.pending = nil
+	return res, nil
+}
+
+func (r *enrichmentRepo) Save(ctx context.Context, enrichment domain.ProblemEnrichment) error {
+	r.saved = append(r.saved, enrichment)
+	return nil
+}
+
+func TestEnrichUpdatesLegacyProblemById(t *testing.T) {
+	src, _ := newFakeLeetCodeSource(t, LeetCodeCN, func(req graphqlRequest) interface{} {
+		return map[string]interface{}{
+			"question": map[string]interface{}{
+				"questionFrontendId": "1",
+				"title":              "Two Sum",
+				"translatedTitle":    "两数之和",
+				"titleSlug":          "two-sum",
+				"content":            "<p>desc</p>",
+				"difficulty":         "Easy",
+				"isPaidOnly":         true,
+				"topicTags":          []map[string]string{{"name": "Array", "translatedName": "数组"}},
+			},
+		}
+	})
+	problems := &detailProblemRepo{t: t, updated: make(map[int64]domain.CodingProblem)}
+	// 早期保存的题目只有 slug，没有来源题号
+	enrichments := &enrichmentRepo{pending: []domain.CodingProblem{
+		{Id: 7, Source: LeetCodeCN.Name, TitleSlug: "two-sum"},
+	}}
+	svc := NewProblemEnrichService(enrichments, problems, NewProblemSourceRegistry(src)).(*problemEnrichService)
+	svc.delay = 0
+
+	n, err := svc.EnrichPending(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("enriched = %d, want 1", n)
+	}
+	detail, ok := problems.updated[7]
+	if !ok || len(problems.updated) != 1 {
+		t.Fatalf("updated = %+v, want problem 7", problems.updated)
+	}
+	if detail.Title == "" || detail.Description == "" || !detail.PaidOnly || len(detail.Tags) == 0 {
+		t.Errorf("unexpected detail %+v", detail)
+	}
+	want := []domain.ProblemEnrichment{{ProblemId: 7, Status: domain.EnrichDone}}
+	if !reflect.DeepEqual(enrichments.saved, want) {
+		t.Errorf("saved = %+v, want %+v", enrichments.saved, want)
+	}
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/repository"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidProblemList = errors.New("题单名称不能为空")
+	ErrBuiltInProblemList = errors.New("内置题单不能删除或改名")
+	ErrListImportRunning  = errors.New("题单正在导入中")
+	ErrListImportNotFound = errors.New("没有该题单的导入任务")
+)
+
+type ProblemListService interface {
+	List(ctx context.Context) ([]domain.ProblemList, error)
+	Create(ctx context.Context, name, description string) (domain.ProblemList, error)
+	// Detail 题单信息及按顺序排列的题目
+	Detail(ctx context.Context, id int64) (domain.ProblemListDetail, error)
+	Update(ctx context.Context, id int64, name, description string) error
+	Delete(ctx context.Context, id int64) error
+	// SetProblems 按给定顺序整体替换题单中的题目
+	SetProblems(ctx context.Context, id int64, problemIds []int64) error
+	// AddProblems 把题目追加到题单末尾
+	AddProblems(ctx context.Context, id int64, problemIds []int64) error
+	RemoveProblem(ctx context.Context, id, problemId int64) error
+	Progress(ctx context.Context, id int64) (domain.ProblemListProgress, error)
+	// Import 在后台导入 LeetCode 学习计划或收藏夹，listType 为 study_plan 或 favorite，
+	// 返回开始时的任务；同一个题单正在导入时返回 ErrListImportRunning
+	Import(ctx context.Context, listType, slug string) (domain.ProblemListImportTask, error)
+	// ImportStatus 题单最近一次导入任务的状态，服务重启后不再保留
+	ImportStatus(ctx context.Context, listType, slug string) (domain.ProblemListImportTask, error)
+}
+
+type problemListService struct {
+	repo    repository.ProblemListRepository
+	crawler *LeetCodeCrawler
+
+	mu sync.Mutex
+	// imports 每个题单最近一次的导入任务，键为 type/slug
+	imports map[string]domain.ProblemListImportTask
+}
+
+func NewProblemListService(repo repository.ProblemListRepository, crawler *LeetCodeCrawler) ProblemListService {
+	return &problemListService{
+		repo:    repo,
+		crawler: crawler,
+		imports: make(map[string]domain.ProblemListImportTask),
+	}
+}
+
+func (svc *problemListService) List(ctx context.Context) ([]domain.ProblemList, error) {
+	return svc.repo.FindAll(ctx)
+}
+
+func (svc *problemListService) Create(ctx context.Context, name, description string) (domain.ProblemList, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return domain.ProblemList{}, ErrInvalidProblemList
+	}
+	return svc.repo.Create(ctx, domain.ProblemList{
+		Name:        name,
+		Description: strings.TrimSpace(description),
+	})
+}
+
+func (svc *problemListService) Detail(ctx context.Context, id int64) (domain.ProblemListDetail, error) {
+	list, err := svc.repo.FindById(ctx, id)
+	if err != nil {
+		return domain.ProblemListDetail{}, err
+	}
+	problems, err := svc.repo.FindProblems(ctx, id)
+	if err != nil {
+		return domain.ProblemListDetail{}, err
+	}
+	list.ProblemCount = int64(len(problems))
+	return domain.ProblemListDetail{ProblemList: list, Problems: problems}, nil
+}
+
+func (svc *problemListService) Update(ctx context.Context, id int64, name, description string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrInvalidProblemList
+	}
+	list, err := svc.repo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if list.BuiltIn && list.Name != name {
+		return ErrBuiltInProblemList
+	}
+	list.Name = name
+	list.Description = strings.TrimSpace(description)
+	return svc.repo.Update(ctx, list)
+}
+
+func (svc *problemListService) Delete(ctx context.Context, id int64) error {
+	list, err := svc.repo.FindById(ctx, id)
+	if err != nil {
+		return err
+	}
+	if list.BuiltIn {
+		return ErrBuiltInProblemList
+	}
+	return svc.repo.Delete(ctx, id)
+}
+
+func (svc *problemListService) SetProblems(ctx context.Context, id int64, problemIds []int64) error {
+	return svc.repo.SetProblems(ctx, id, problemIds)
+}
+
+func (svc *problemListService) AddProblems(ctx context.Context, id int64, problemIds []int64) error {
+	return svc.repo.AddProblems(ctx, id, problemIds)
+}
+
+func (svc *problemListService) RemoveProblem(ctx context.Context, id, problemId int64) error {
+	return svc.repo.RemoveProblem(ctx, id, problemId)
+}
+
+func (svc *problemListService) Progress(ctx context.Context, id int64) (domain.ProblemListProgress, error) {
+	if _, err := svc.repo.FindById(ctx, id); err != nil {
+		return domain.ProblemListProgress{}, err
+	}
+	return svc.repo.Progress(ctx, id)
+}
+
+func (svc *problemListService) Import(ctx context.Context, listType, slug string) (domain.ProblemListImportTask, error) {
+	slug = strings.TrimSpace(slug)
+	if slug == "" || (listType != LeetCodeStudyPlan && listType != LeetCodeFavorite) {
+		return domain.ProblemListImportTask{}, ErrInvalidListImport
+	}
+	key := listType + "/" + slug
+
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if svc.imports[key].Status == domain.ListImportRunning {
+		return domain.ProblemListImportTask{}, ErrListImportRunning
+	}
+	task := domain.ProblemListImportTask{
+		Type:      listType,
+		Slug:      slug,
+		Status:    domain.ListImportRunning,
+		StartedAt: time.Now(),
+	}
+	svc.imports[key] = task
+	// 逐题抓取详情耗时较长，请求结束后导入继续在后台进行
+	go svc.runImport(context.WithoutCancel(ctx), key, task)
+	return task, nil
+}
+
+func (svc *problemListService) runImport(ctx context.Context, key string, task domain.ProblemListImportTask) {
+	res, err := svc.crawler.ImportList(ctx, task.Type, task.Slug)
+	now := time.Now()
+	task.FinishedAt = &now
+	if err != nil {
+		log.Printf("导入题单 %s 失败: %v", key, err)
+		task.Status = domain.ListImportFailed
+		task.Error = err.Error()
+	} else {
+		task.Status = domain.ListImportDone
+		task.Result = &res
+	}
+	svc.mu.Lock()
+	svc.imports[key] = task
+	svc.mu.Unlock()
+}
+
+func (svc *problemListService) ImportStatus(ctx context.Context, listType, slug string) (domain.ProblemListImportTask, error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	task, ok := svc.imports[listType+"/"+strings.TrimSpace(slug)]
+	if !ok {
+		return domain.ProblemListImportTask{}, ErrListImportNotFound
+	}
+	return task, nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultProblemSource 默认的题目来源，每日一题和题单导入都使用它
+const DefaultProblemSource = "leetcode"
+
+var ErrUnknownProblemSource = errors.New("未知的题目来源")
+
+// ProblemSource 题目来源，例如 LeetCode CN、LeetCode US
+type ProblemSource interface {
+	// Name 来源名称，保存在题目的 Source 字段中
+	Name() string
+	// FetchBySlug 按题目 slug 获取题目详情
+	FetchBySlug(ctx context.Context, slug string) (*domain.CodingProblem, error)
+	// FetchById 按原网站的题号获取题目详情
+	FetchById(ctx context.Context, id string) (*domain.CodingProblem, error)
+	// DayLocation 来源的每日一题在这个时区的 0 点更新
+	DayLocation() *time.Location
+	// Today 来源的每日一题按自己的时区换日，返回 DayLocation 中的今天
+	Today() domain.CivilDate
+	// FetchDaily 获取网站当前的每日一题，日期为网站给出的日期，网站没有给出时为 Today
+	FetchDaily(ctx context.Context) (*domain.DailyProblem, error)
+	// ListProblems 分页列出题库中的题目，只有基本信息
+	ListProblems(ctx context.Context, skip, limit int) (ProblemPage, error)
+}
+
+// ProblemListSource 支持导入题单的题目来源
+type ProblemListSource interface {
+	ProblemSource
+	// FetchList 获取题单信息和其中按顺序排列的题目，listType 由来源自己定义
+	FetchList(ctx context.Context, listType, slug string) (domain.ProblemList, []domain.CodingProblem, error)
+}
+
+// ProblemPage 题库中的一页题目，题目只有基本信息，没有描述
+type ProblemPage struct {
+	Problems []domain.CodingProblem
+	Total    int
+	HasMore  bool
+}
+
+// ProblemSourceRegistry 按名称管理所有题目来源
+type ProblemSourceRegistry struct {
+	sources map[string]ProblemSource
+	names   []string
+}
+
+func NewProblemSourceRegistry(sources ...ProblemSource) *ProblemSourceRegistry {
+	r := &ProblemSourceRegistry{sources: make(map[string]ProblemSource, len(sources))}
+	for _, source := range sources {
+		r.Register(source)
+	}
+	return r
+}
+
+// NewDefaultProblemSources 注册 LeetCode CN 和 LeetCode US
+func NewDefaultProblemSources() *ProblemSourceRegistry {
+	return NewProblemSourceRegistry(NewLeetCodeSource(LeetCodeCN), NewLeetCodeSource(LeetCodeUS))
+}
+
+// Register 注册来源，同名的来源会被替换
+func (r *ProblemSourceRegistry) Register(source ProblemSource) {
+	if _, ok := r.sources[source.Name()]; !ok {
+		r.names = append(r.names, source.Name())
+	}
+	r.sources[source.Name()] = source
+}
+
+// Get 按名称查找来源，name 为空时返回默认来源
+func (r *ProblemSourceRegistry) Get(name string) (ProblemSource, error) {
+	if name == "" {
+		name = DefaultProblemSource
+	}
+	source, ok := r.sources[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProblemSource, name)
+	}
+	return source, nil
+}
+
+// All 按注册顺序返回所有来源
+func (r *ProblemSourceRegistry) All() []ProblemSource {
+	res := make([]ProblemSource, 0, len(r.names))
+	for _, name := range r.names {
+		res = append(res, r.sources[name])
+	}
+	return res
+}
+
+// Names 按注册顺序返回所有来源名称
+func (r *ProblemSourceRegistry) Names() []string {
+	return append([]string(nil), r.names...)
+}
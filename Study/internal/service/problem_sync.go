@@ -0,0 +1,181 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/repository"
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// syncPageSize 同步题库时每页拉取的题目数，请求频率由题目来源自己限制
+const syncPageSize = 100
+
+var ErrSyncRunning = errors.New("题库正在同步中")
+
+type ProblemSyncService interface {
+	// Start 在后台同步来源的题库元数据，上次没有完成时从断点继续，返回开始时的进度
+	Start(ctx context.Context, source string) (domain.ProblemSync, error)
+	// Progress 同步进度，从未同步过时状态为 idle
+	Progress(ctx context.Context, source string) (domain.ProblemSync, error)
+	// Resume 继续服务重启前正在进行的同步
+	Resume(ctx context.Context) error
+}
+
+type problemSyncService struct {
+	repo        repository.ProblemSyncRepository
+	problemRepo repository.CodingProblemRepository
+	sources     *ProblemSourceRegistry
+
+	mu sync.Mutex
+	// running 正在本进程中同步的来源
+	running map[string]bool
+}
+
+func NewProblemSyncService(repo repository.ProblemSyncRepository, problemRepo repository.CodingProblemRepository,
+	sources *ProblemSourceRegistry) ProblemSyncService {
+	return &problemSyncService{
+		repo:        repo,
+		problemRepo: problemRepo,
+		sources:     sources,
+		running:     make(map[string]bool),
+	}
+}
+
+func (svc *problemSyncService) Start(ctx context.Context, source string) (domain.ProblemSync, error) {
+	src, err := svc.sources.Get(source)
+	if err != nil {
+		return domain.ProblemSync{}, err
+	}
+	state, err := svc.load(ctx, src.Name())
+	if err != nil {
+		return domain.ProblemSync{}, err
+	}
+	// 上次已经完成时重新从头同步，否则从断点继续
+	if state.Status == domain.SyncIdle || state.Status == domain.SyncDone {
+		now := time.Now()
+		state = domain.ProblemSync{Source: src.Name(), StartedAt: &now}
+	}
+	// 请求结束后同步继续在后台进行
+	return svc.start(context.WithoutCancel(ctx), src, state)
+}
+
+func (svc *problemSyncService) Progress(ctx context.Context, source string) (domain.ProblemSync, error) {
+	src, err := svc.sources.Get(source)
+	if err != nil {
+		return domain.ProblemSync{}, err
+	}
+	return svc.load(ctx, src.Name())
+}
+
+func (svc *problemSyncService) Resume(ctx context.Context) error {
+	syncs, err := svc.repo.FindByStatus(ctx, domain.SyncRunning)
+	if err != nil {
+		return err
+	}
+	for _, state := range syncs {
+		src, err := svc.sources.Get(state.Source)
+		if err != nil {
+			log.Printf("无法继续同步题库 %s: %v", state.Source, err)
+			continue
+		}
+		if _, err := svc.start(ctx, src, state); err != nil && !errors.Is(err, ErrSyncRunning) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (svc *problemSyncService) load(ctx context.Context, source string) (domain.ProblemSync, error) {
+	state, err := svc.repo.FindBySource(ctx, source)
+	if errors.Is(err, repository.ErrProblemSyncNotFound) {
+		return domain.ProblemSync{Source: source, Status: domain.SyncIdle}, nil
+	}
+	return state, err
+}
+
+// start 保存为同步中并启动后台同步，同一个来源同时只能有一个同步
+func (svc *problemSyncService) start(ctx context.Context, src ProblemSource, state domain.ProblemSync) (domain.ProblemSync, error) {
+	svc.mu.Lock()
+	defer svc.mu.Unlock()
+	if svc.running[src.Name()] {
+		return domain.ProblemSync{}, ErrSyncRunning
+	}
+
+	state.Status = domain.SyncRunning
+	state.Error = ""
+	state.FinishedAt = nil
+	if err := svc.repo.Save(ctx, state); err != nil {
+		return domain.ProblemSync{}, err
+	}
+	svc.running[src.Name()] = true
+	go svc.run(ctx, src, state)
+	return state, nil
+}
+
+// run 逐页同步题库，每页处理完后保存断点；ctx 结束时保持同步中状态，重启后继续
+func (svc *problemSyncService) run(ctx context.Context, src ProblemSource, state domain.ProblemSync) {
+	defer func() {
+		svc.mu.Lock()
+		delete(svc.running, src.Name())
+		svc.mu.Unlock()
+	}()
+	log.Printf("开始同步题库 %s，从第 %d 题开始", src.Name(), state.Offset)
+
+	for {
+		page, err := src.ListProblems(ctx, state.Offset, syncPageSize)
+		if err != nil {
+			svc.fail(ctx, state, err)
+			return
+		}
+
+		// 一页全部保存成功后才更新进度，失败时整页重新同步
+		next := state
+		next.Total = page.Total
+		for _, problem := range page.Problems {
+			_, created, err := svc.problemRepo.Upsert(ctx, problem)
+			switch {
+			case errors.Is(err, repository.ErrProblemTrashed):
+				next.Skipped++
+			case err != nil:
+				svc.fail(ctx, state, err)
+				return
+			case created:
+				next.Created++
+			default:
+				next.Updated++
+			}
+		}
+		next.Offset += len(page.Problems)
+		state = next
+
+		if !page.HasMore {
+			now := time.Now()
+			state.Status = domain.SyncDone
+			state.FinishedAt = &now
+		}
+		if err := svc.repo.Save(ctx, state); err != nil {
+			log.Printf("保存题库 %s 同步进度失败: %v", src.Name(), err)
+			return
+		}
+		if state.Status == domain.SyncDone {
+			log.Printf("题库 %s 同步完成，新增 %d，更新 %d，跳过 %d",
+				src.Name(), state.Created, state.Updated, state.Skipped)
+			return
+		}
+	}
+}
+
+func (svc *problemSyncService) fail(ctx context.Context, state domain.ProblemSync, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	log.Printf("同步题库 %s 失败: %v", state.Source, err)
+	state.Status = domain.SyncFailed
+	state.Error = err.Error()
+	if err := svc.repo.Save(ctx, state); err != nil {
+		log.Printf("保存题库 %s 同步进度失败: %v", state.Source, err)
+	}
+}
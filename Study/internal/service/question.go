@@ -4,30 +4,97 @@ import (
 	"Training/Study/internal/domain"
 	"Training/Study/internal/repository"
 	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidGrade = errors.New("评分必须在 0 到 5 之间")
+	ErrEmptyKeyword = errors.New("搜索关键词不能为空")
+)
+
+const (
+	// defaultDueLimit 复习队列默认返回的题目数
+	defaultDueLimit = 50
+	// defaultHistoryLimit 复习记录默认返回的条数
+	defaultHistoryLimit = 100
+	// defaultSearchLimit 搜索默认返回的结果数
+	defaultSearchLimit = 20
 )
 
 type QuestService interface {
-	Insert(ctx context.Context, quest domain.Question) error
+	// Insert 创建题目并返回相似的已有题目；存在高度相似的题目时返回 ErrDuplicateQuestion，force 为 true 时仍然创建
+	Insert(ctx context.Context, quest domain.Question, force bool) ([]domain.SimilarQuestion, error)
 	FindByCategory(ctx context.Context, category string) ([]domain.Question, error)
 	FindAll(ctx context.Context) ([]domain.Question, error)
-	UpdateById(ctx context.Context, quest domain.Question) error
+	// List 按条件分页查询题目，cursor 为上一页返回的 next_cursor
+	List(ctx context.Context, query domain.QuestionQuery, cursor string) (domain.QuestionPage, error)
+	// FindDue 获取到期需要复习的题目，按到期时间排序
+	FindDue(ctx context.Context, category string, limit int) ([]domain.Question, error)
+	// UpdateById 修改题目，author 记录在新的修订版本中
+	UpdateById(ctx context.Context, quest domain.Question, author string) error
 	UpdateMasteryLevel(ctx context.Context, id int64, masteryLevel int) error
+	// Review 提交一次复习评分(0-5)，按 SM-2 更新复习计划并记录复习事件
+	Review(ctx context.Context, id int64, grade int, duration time.Duration) (domain.Question, error)
 	DeleteById(ctx context.Context, id int64) error
-	DeleteByCategory(ctx context.Context, category string) error
+	// DeleteByCategory 删除分类及其子分类下的全部题目，confirm 必须与分类名一致，
+	// 否则返回 ErrDeleteNotConfirmed 和将被删除的题目数
+	DeleteByCategory(ctx context.Context, category, confirm string) (int64, error)
+	// RenameCategory 重命名分类，子分类随之改名，返回涉及的题目数
+	RenameCategory(ctx context.Context, from, to string) (int64, error)
+	// MergeCategory 将 from 分类的题目并入已存在的 into 分类，返回涉及的题目数
+	MergeCategory(ctx context.Context, from, into string) (int64, error)
+	// RestoreFromTrash 从回收站恢复题目
+	RestoreFromTrash(ctx context.Context, id int64) error
+	// InvalidateSearch 题目在 QuestService 之外被修改后调用，下次检索时重建进程内索引
+	InvalidateSearch()
 	FindAllCategories(ctx context.Context) ([]string, error)
 	GetMasteryStats(ctx context.Context) (map[string]int, error)
+	// Search 检索题目内容和答案，数据库不支持全文检索时使用进程内索引
+	Search(ctx context.Context, keyword string, limit int) ([]domain.QuestionSearchResult, error)
+	// Import 批量导入题目，按内容去重并返回逐条结果
+	Import(ctx context.Context, quests []domain.Question, opts ImportOptions) (domain.QuestionImportReport, error)
+	History(ctx context.Context, id int64, limit int) ([]domain.QuestionReview, error)
+	Activity(ctx context.Context, before time.Time, limit int) ([]domain.QuestionActivity, error)
+	// Revisions 题目的修订历史，按版本号倒序
+	Revisions(ctx context.Context, id int64) ([]domain.QuestionRevision, error)
+	// RevisionDiff 对比两个版本，from 或 to 为 0 时分别取上一个版本和最新版本
+	RevisionDiff(ctx context.Context, id int64, from, to int) (domain.QuestionRevisionDiff, error)
+	// Restore 将题目恢复到指定版本，恢复本身也会产生一个新版本
+	Restore(ctx context.Context, id int64, rev int, author string) (domain.Question, error)
+	// Duplicates 列出疑似重复的题目分组，similarity 为组内题目之间的最低相似度
+	Duplicates(ctx context.Context, similarity float64) ([]domain.DuplicateCluster, error)
+	// Merge 将多道重复题目合并到 keepId，保留掌握度和复习记录
+	Merge(ctx context.Context, keepId int64, mergeIds []int64) (domain.Question, error)
 }
 
 type questService struct {
-	repo repository.QuestRepository
+	repo  repository.QuestRepository
+	index *questionIndex
 }
 
 func NewQuestService(repo repository.QuestRepository) QuestService {
-	return &questService{repo: repo}
+	return &questService{
+		repo:  repo,
+		index: newQuestionIndex(),
+	}
 }
 
-func (svc *questService) Insert(ctx context.Context, quest domain.Question) error {
-	return svc.repo.Insert(ctx, quest)
+func (svc *questService) Insert(ctx context.Context, quest domain.Question, force bool) ([]domain.SimilarQuestion, error) {
+	res, err := svc.findSimilar(ctx, []string{quest.Content})
+	if err != nil {
+		return nil, err
+	}
+	similar := res[0]
+	if similar == nil {
+		similar = []domain.SimilarQuestion{}
+	}
+	if !force && len(similar) > 0 && similar[0].Similarity >= duplicateRejectSimilarity {
+		return similar, ErrDuplicateQuestion
+	}
+	defer svc.index.Invalidate()
+	return similar, svc.repo.Insert(ctx, quest)
 }
 
 func (svc *questService) FindByCategory(ctx context.Context, category string) ([]domain.Question, error) {
@@ -38,20 +105,47 @@ func (svc *questService) FindAll(ctx context.Context) ([]domain.Question, error)
 	return svc.repo.FindAll(ctx)
 }
 
-func (svc *questService) UpdateById(ctx context.Context, quest domain.Question) error {
-	return svc.repo.UpdateById(ctx, quest)
+func (svc *questService) FindDue(ctx context.Context, category string, limit int) ([]domain.Question, error) {
+	if limit <= 0 {
+		limit = defaultDueLimit
+	}
+	return svc.repo.FindDue(ctx, category, time.Now(), limit)
+}
+
+func (svc *questService) UpdateById(ctx context.Context, quest domain.Question, author string) error {
+	defer svc.index.Invalidate()
+	return svc.repo.UpdateById(ctx, quest, author, 0)
 }
 
 func (svc *questService) UpdateMasteryLevel(ctx context.Context, id int64, masteryLevel int) error {
+	defer svc.index.Invalidate()
 	return svc.repo.UpdateMasteryLevel(ctx, id, masteryLevel)
 }
 
+func (svc *questService) Review(ctx context.Context, id int64, grade int, duration time.Duration) (domain.Question, error) {
+	if grade < MinReviewGrade || grade > MaxReviewGrade {
+		return domain.Question{}, ErrInvalidGrade
+	}
+	now := time.Now()
+	defer svc.index.Invalidate()
+	// 复习计划基于事务中锁定的题目计算，并发评分不会覆盖彼此的结果
+	return svc.repo.UpdateSchedule(ctx, id, grade, duration, func(quest domain.Question) domain.Question {
+		return scheduleReview(quest, grade, now)
+	})
+}
+
 func (svc *questService) DeleteById(ctx context.Context, id int64) error {
+	defer svc.index.Invalidate()
 	return svc.repo.DeleteById(ctx, id)
 }
 
-func (svc *questService) DeleteByCategory(ctx context.Context, category string) error {
-	return svc.repo.DeleteByCategory(ctx, category)
+func (svc *questService) RestoreFromTrash(ctx context.Context, id int64) error {
+	defer svc.index.Invalidate()
+	return svc.repo.Restore(ctx, id)
+}
+
+func (svc *questService) InvalidateSearch() {
+	svc.index.Invalidate()
 }
 
 func (svc *questService) FindAllCategories(ctx context.Context) ([]string, error) {
@@ -61,3 +155,50 @@ func (svc *questService) FindAllCategories(ctx context.Context) ([]string, error
 func (svc *questService) GetMasteryStats(ctx context.Context) (map[string]int, error) {
 	return svc.repo.GetMasteryStats(ctx)
 }
+
+func (svc *questService) Search(ctx context.Context, keyword string, limit int) ([]domain.QuestionSearchResult, error) {
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
+		return nil, ErrEmptyKeyword
+	}
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+
+	results, err := svc.repo.Search(ctx, keyword, limit)
+	if errors.Is(err, repository.ErrSearchUnsupported) {
+		results, err = svc.searchIndex(ctx, keyword, limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Snippet = buildSnippet(results[i].Question, keyword)
+	}
+	return results, nil
+}
+
+func (svc *questService) searchIndex(ctx context.Context, keyword string, limit int) ([]domain.QuestionSearchResult, error) {
+	if gen, built := svc.index.Generation(); !built {
+		questions, err := svc.repo.FindAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		svc.index.Build(gen, questions)
+	}
+	return svc.index.Search(keyword, limit), nil
+}
+
+func (svc *questService) History(ctx context.Context, id int64, limit int) ([]domain.QuestionReview, error) {
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	return svc.repo.FindReviews(ctx, id, limit)
+}
+
+func (svc *questService) Activity(ctx context.Context, before time.Time, limit int) ([]domain.QuestionActivity, error) {
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	return svc.repo.FindActivity(ctx, before, limit)
+}
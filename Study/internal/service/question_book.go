@@ -0,0 +1,189 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// masteryNames 掌握度的中文名称
+var masteryNames = map[int]string{
+	domain.MasteryUnlearned: "未学习",
+	domain.MasteryLearning:  "学习中",
+	domain.MasteryMastered:  "已掌握",
+}
+
+// tocEscaper 转义目录链接文字中的方括号
+var tocEscaper = strings.NewReplacer("[", "\\[", "]", "\\]")
+
+// unsafeFilename 文件名中不允许出现的字符
+var unsafeFilename = strings.NewReplacer("/", "_", "\\", "_", ":", "_", "*", "_",
+	"?", "_", "\"", "_", "<", "_", ">", "_", "|", "_")
+
+func (svc *questExportService) ExportMarkdown(ctx context.Context, categories []string, onlyUnmastered bool) (ExportFile, error) {
+	switch len(categories) {
+	case 0:
+		questions, err := svc.findBookQuestions(ctx, "", onlyUnmastered)
+		if err != nil {
+			return ExportFile{}, err
+		}
+		return ExportFile{
+			Name:        "八股题库.md",
+			ContentType: "text/markdown; charset=utf-8",
+			Data:        renderBook("八股题库", questions),
+		}, nil
+	case 1:
+		questions, err := svc.findBookQuestions(ctx, categories[0], onlyUnmastered)
+		if err != nil {
+			return ExportFile{}, err
+		}
+		return ExportFile{
+			Name:        unsafeFilename.Replace(categories[0]) + ".md",
+			ContentType: "text/markdown; charset=utf-8",
+			Data:        renderBook(categories[0], questions),
+		}, nil
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	used := make(map[string]struct{}, len(categories))
+	for _, category := range categories {
+		questions, err := svc.findBookQuestions(ctx, category, onlyUnmastered)
+		if err != nil {
+			return ExportFile{}, err
+		}
+		w, err := zw.Create(uniqueFilename(used, unsafeFilename.Replace(category), ".md"))
+		if err != nil {
+			return ExportFile{}, err
+		}
+		if _, err := w.Write(renderBook(category, questions)); err != nil {
+			return ExportFile{}, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return ExportFile{}, err
+	}
+	return ExportFile{
+		Name:        "八股题库.zip",
+		ContentType: "application/zip",
+		Data:        buf.Bytes(),
+	}, nil
+}
+
+// uniqueFilename 替换字符后不同分类可能同名，例如 "a/b" 和 "a_b"，重名时加上数字后缀。
+// 解压到不区分大小写的文件系统时大小写不同也会冲突，因此忽略大小写比较
+func uniqueFilename(used map[string]struct{}, base, ext string) string {
+	name := base + ext
+	for i := 2; ; i++ {
+		key := strings.ToLower(name)
+		if _, ok := used[key]; !ok {
+			used[key] = struct{}{}
+			return name
+		}
+		name = fmt.Sprintf("%s (%d)%s", base, i, ext)
+	}
+}
+
+func (svc *questExportService) findBookQuestions(ctx context.Context, category string, onlyUnmastered bool) ([]domain.Question, error) {
+	questions, err := svc.findQuestions(ctx, category)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Question, 0, len(questions))
+	for _, quest := range questions {
+		if onlyUnmastered && quest.MasteryLevel == domain.MasteryMastered {
+			continue
+		}
+		res = append(res, quest)
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Category != res[j].Category {
+			return res[i].Category < res[j].Category
+		}
+		return res[i].Id < res[j].Id
+	})
+	return res, nil
+}
+
+// renderBook 生成 Markdown 文档：标题、目录，以及每道题一节。
+// 题目涉及多个分类时按分类分章，题目标题下降一级
+func renderBook(title string, questions []domain.Question) []byte {
+	groups := make([][]domain.Question, 0)
+	for i, quest := range questions {
+		if i == 0 || quest.Category != questions[i-1].Category {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], quest)
+	}
+	byCategory := len(groups) > 1
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", title)
+	fmt.Fprintf(&sb, "共 %d 道题\n\n", len(questions))
+
+	sb.WriteString("## 目录\n\n")
+	for gi, group := range groups {
+		indent := ""
+		if byCategory {
+			fmt.Fprintf(&sb, "- [%s](#category-%d)\n", tocEscaper.Replace(group[0].Category), gi+1)
+			indent = "  "
+		}
+		for _, quest := range group {
+			fmt.Fprintf(&sb, "%s- [%s](#question-%d)\n", indent, tocEscaper.Replace(headingText(quest.Content)), quest.Id)
+		}
+	}
+	sb.WriteString("\n")
+
+	level := 2
+	if byCategory {
+		level = 3
+	}
+	for gi, group := range groups {
+		if byCategory {
+			fmt.Fprintf(&sb, "<a id=\"category-%d\"></a>\n\n## %s\n\n", gi+1, group[0].Category)
+		}
+		for i, quest := range group {
+			fmt.Fprintf(&sb, "<a id=\"question-%d\"></a>\n\n", quest.Id)
+			fmt.Fprintf(&sb, "%s %d. %s\n\n", strings.Repeat("#", level), i+1, headingText(quest.Content))
+			fmt.Fprintf(&sb, "> 分类：%s ｜ 掌握度：%s\n\n", quest.Category, masteryNames[quest.MasteryLevel])
+			if strings.Contains(strings.TrimSpace(quest.Content), "\n") {
+				sb.WriteString(strings.TrimSpace(quest.Content))
+				sb.WriteString("\n\n")
+			}
+			sb.WriteString(demoteHeadings(strings.TrimSpace(quest.Answer), level))
+			sb.WriteString("\n\n---\n\n")
+		}
+	}
+	return []byte(sb.String())
+}
+
+// headingText 将题目内容压成一行，作为标题和目录项
+func headingText(content string) string {
+	return strings.Join(strings.Fields(content), " ")
+}
+
+// demoteHeadings 将答案中的标题整体下降 level 级，使其位于题目标题之下，代码块内的内容保持不变
+func demoteHeadings(markdown string, level int) string {
+	lines := strings.Split(markdown, "\n")
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || !strings.HasPrefix(line, "#") {
+			continue
+		}
+		depth := len(line) - len(strings.TrimLeft(line, "#"))
+		if depth > 6 || (len(line) > depth && line[depth] != ' ') {
+			continue
+		}
+		lines[i] = strings.Repeat("#", min(depth+level, 6)) + line[depth:]
+	}
+	return strings.Join(lines, "\n")
+}
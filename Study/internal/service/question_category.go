@@ -0,0 +1,71 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrCategoryNotFound   = errors.New("分类不存在")
+	ErrCategoryExists     = errors.New("目标分类已存在，请使用合并")
+	ErrInvalidCategory    = errors.New("无效的分类：不能为空、不能与原分类相同，也不能移动到自己的子分类下")
+	ErrDeleteNotConfirmed = errors.New("删除分类需要确认，confirm 参数必须与分类名一致")
+)
+
+func (svc *questService) DeleteByCategory(ctx context.Context, category, confirm string) (int64, error) {
+	category = domain.NormalizeTagPath(category)
+	if category == "" {
+		return 0, ErrInvalidCategory
+	}
+	count, err := svc.repo.CountByCategory(ctx, category)
+	if err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 0, ErrCategoryNotFound
+	}
+	if domain.NormalizeTagPath(confirm) != category {
+		return count, ErrDeleteNotConfirmed
+	}
+	defer svc.index.Invalidate()
+	return svc.repo.DeleteByCategory(ctx, category)
+}
+
+func (svc *questService) RenameCategory(ctx context.Context, from, to string) (int64, error) {
+	return svc.moveCategory(ctx, from, to, false)
+}
+
+func (svc *questService) MergeCategory(ctx context.Context, from, into string) (int64, error) {
+	return svc.moveCategory(ctx, from, into, true)
+}
+
+// moveCategory 重命名和合并都是把 from 整体移动到 to，区别在于 to 是否必须已经存在
+func (svc *questService) moveCategory(ctx context.Context, from, to string, merge bool) (int64, error) {
+	from, to = domain.NormalizeTagPath(from), domain.NormalizeTagPath(to)
+	if from == "" || to == "" || from == to || strings.HasPrefix(to, from+"/") ||
+		utf8.RuneCountInString(to) > maxCategoryLen {
+		return 0, ErrInvalidCategory
+	}
+	count, err := svc.repo.CountByCategory(ctx, from)
+	if err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 0, ErrCategoryNotFound
+	}
+	existing, err := svc.repo.CountByCategory(ctx, to)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case merge && existing == 0:
+		return 0, ErrCategoryNotFound
+	case !merge && existing > 0:
+		return 0, ErrCategoryExists
+	}
+	defer svc.index.Invalidate()
+	return svc.repo.MoveCategory(ctx, from, to)
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/repository"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// masteryTags 掌握度对应的 Anki 标签
+var masteryTags = map[int]string{
+	domain.MasteryUnlearned: "mastery::unlearned",
+	domain.MasteryLearning:  "mastery::learning",
+	domain.MasteryMastered:  "mastery::mastered",
+}
+
+// ExportFile 导出的文件
+type ExportFile struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+type QuestExportService interface {
+	// ExportAnki 导出为 Anki 可直接导入的 TSV，category 为空时导出全部题目
+	ExportAnki(ctx context.Context, category string) ([]byte, error)
+	// ExportMarkdown 导出为带目录的 Markdown 文档。不指定分类时导出整个题库，
+	// 指定一个分类时导出该分类，指定多个分类时每个分类一个文件打包为 zip
+	ExportMarkdown(ctx context.Context, categories []string, onlyUnmastered bool) (ExportFile, error)
+}
+
+type questExportService struct {
+	repo     repository.QuestRepository
+	markdown goldmark.Markdown
+}
+
+func NewQuestExportService(repo repository.QuestRepository) QuestExportService {
+	return &questExportService{
+		repo:     repo,
+		markdown: goldmark.New(goldmark.WithExtensions(extension.GFM)),
+	}
+}
+
+func (svc *questExportService) findQuestions(ctx context.Context, category string) ([]domain.Question, error) {
+	if category == "" {
+		return svc.repo.FindAll(ctx)
+	}
+	return svc.repo.FindByCategory(ctx, category)
+}
+
+// ExportAnki 每道题一行：GUID、正面(题目)、背面(答案)、标签。
+// GUID 由题目ID派生，重复导入时 Anki 会更新已有卡片而不是新建
+func (svc *questExportService) ExportAnki(ctx context.Context, category string) ([]byte, error) {
+	questions, err := svc.findQuestions(ctx, category)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("#separator:tab\n")
+	buf.WriteString("#html:true\n")
+	buf.WriteString("#notetype:Basic\n")
+	buf.WriteString("#guid column:1\n")
+	buf.WriteString("#tags column:4\n")
+
+	w := csv.NewWriter(&buf)
+	w.Comma = '\t'
+	for _, quest := range questions {
+		front, err := svc.renderHTML(quest.Content)
+		if err != nil {
+			return nil, err
+		}
+		back, err := svc.renderHTML(quest.Answer)
+		if err != nil {
+			return nil, err
+		}
+		if err := w.Write([]string{ankiGUID(quest.Id), front, back, ankiTags(quest)}); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// renderHTML 将 Markdown 渲染为 HTML，原始 HTML 会被过滤
+func (svc *questExportService) renderHTML(markdown string) (string, error) {
+	var buf bytes.Buffer
+	if err := svc.markdown.Convert([]byte(markdown), &buf); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func ankiGUID(id int64) string {
+	sum := sha1.Sum([]byte("study-question:" + strconv.FormatInt(id, 10)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ankiTags 分类和掌握度标签，Anki 标签不能含空格，分类中的 / 转为层级标签分隔符 ::
+func ankiTags(quest domain.Question) string {
+	category := strings.Join(strings.Fields(quest.Category), "_")
+	category = strings.ReplaceAll(category, "/", "::")
+	tags := []string{masteryTags[quest.MasteryLevel]}
+	if category != "" {
+		tags = append([]string{category}, tags...)
+	}
+	return strings.Join(tags, " ")
+}
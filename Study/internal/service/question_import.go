@@ -0,0 +1,129 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrEmptyImport   = errors.New("导入的题目列表不能为空")
+	ErrImportTooMany = fmt.Errorf("单次最多导入 %d 道题目", maxImportSize)
+)
+
+const (
+	// maxImportSize 单次导入的题目上限
+	maxImportSize = 2000
+	// maxCategoryLen 分类名长度上限，与 questions.category 列一致
+	maxCategoryLen = 100
+	// importContentPreview 报告中回显的题目内容长度
+	importContentPreview = 50
+)
+
+// ImportOptions 批量导入选项
+type ImportOptions struct {
+	// DryRun 只校验并给出结果，不写入数据库
+	DryRun bool
+	// Overwrite 遇到重复题目时用新的答案和分类覆盖，否则跳过
+	Overwrite bool
+	// Force 与已有题目高度相似时仍然导入，否则跳过
+	Force bool
+}
+
+func (svc *questService) Import(ctx context.Context, quests []domain.Question, opts ImportOptions) (domain.QuestionImportReport, error) {
+	if len(quests) == 0 {
+		return domain.QuestionImportReport{}, ErrEmptyImport
+	}
+	if len(quests) > maxImportSize {
+		return domain.QuestionImportReport{}, ErrImportTooMany
+	}
+
+	report := domain.QuestionImportReport{
+		DryRun: opts.DryRun,
+		Total:  len(quests),
+		Items:  make([]domain.QuestionImportResult, len(quests)),
+	}
+	valid := make([]domain.Question, 0, len(quests))
+	validIdx := make([]int, 0, len(quests))
+	for i, quest := range quests {
+		quest.Content = strings.TrimSpace(quest.Content)
+		quest.Answer = strings.TrimSpace(quest.Answer)
+		quest.Category = strings.TrimSpace(quest.Category)
+		report.Items[i] = domain.QuestionImportResult{
+			Index:   i,
+			Content: preview(quest.Content),
+		}
+		if msg := validateImport(quest); msg != "" {
+			report.Items[i].Status = domain.ImportError
+			report.Items[i].Error = msg
+			continue
+		}
+		valid = append(valid, quest)
+		validIdx = append(validIdx, i)
+	}
+
+	valid, validIdx, err := svc.checkImportSimilar(ctx, &report, valid, validIdx, opts.Force)
+	if err != nil {
+		return domain.QuestionImportReport{}, err
+	}
+
+	if len(valid) > 0 {
+		results, err := svc.repo.Import(ctx, valid, opts.Overwrite, opts.DryRun)
+		if err != nil {
+			return domain.QuestionImportReport{}, err
+		}
+		for j, res := range results {
+			item := &report.Items[validIdx[j]]
+			item.Status = res.Status
+			item.Id = res.Id
+			// 预演模式下新建的题目已回滚，ID 没有意义
+			if opts.DryRun && res.Status == domain.ImportCreated {
+				item.Id = 0
+			}
+		}
+		if !opts.DryRun {
+			svc.index.Invalidate()
+		}
+	}
+
+	for _, item := range report.Items {
+		switch item.Status {
+		case domain.ImportCreated:
+			report.Created++
+		case domain.ImportUpdated:
+			report.Updated++
+		case domain.ImportSkipped:
+			report.Skipped++
+		case domain.ImportTrashed:
+			report.Trashed++
+		case domain.ImportError:
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+func validateImport(quest domain.Question) string {
+	switch {
+	case quest.Content == "":
+		return "content 不能为空"
+	case quest.Answer == "":
+		return "answer 不能为空"
+	case quest.Category == "":
+		return "category 不能为空"
+	case utf8.RuneCountInString(quest.Category) > maxCategoryLen:
+		return "category 不能超过 100 个字符"
+	}
+	return ""
+}
+
+func preview(content string) string {
+	runes := []rune(content)
+	if len(runes) <= importContentPreview {
+		return content
+	}
+	return string(runes[:importContentPreview]) + "…"
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+var (
+	ErrInvalidCursor = errors.New("无效的分页游标")
+	ErrInvalidSort   = errors.New("不支持的排序字段")
+)
+
+const (
+	// defaultPageSize 题目列表默认每页条数
+	defaultPageSize = 50
+	// maxPageSize 题目列表每页条数上限
+	maxPageSize = 200
+)
+
+// questionSortFields 题目列表支持的排序字段
+var questionSortFields = map[string]bool{
+	"id":             true,
+	"ctime":          true,
+	"utime":          true,
+	"mastery_level":  true,
+	"next_review_at": true,
+	"review_count":   true,
+}
+
+func (svc *questService) List(ctx context.Context, query domain.QuestionQuery, cursor string) (domain.QuestionPage, error) {
+	if query.SortBy == "" {
+		query.SortBy = "id"
+	}
+	if !questionSortFields[query.SortBy] {
+		return domain.QuestionPage{}, ErrInvalidSort
+	}
+	if query.Limit <= 0 {
+		query.Limit = defaultPageSize
+	}
+	query.Limit = min(query.Limit, maxPageSize)
+	if cursor != "" {
+		after, err := decodeCursor(cursor)
+		// 游标只能用于生成它的排序方式
+		if err != nil || after.SortBy != query.SortBy || after.Desc != query.Desc {
+			return domain.QuestionPage{}, ErrInvalidCursor
+		}
+		query.After = &after
+	}
+
+	page, err := svc.repo.FindPage(ctx, query)
+	if err != nil {
+		return domain.QuestionPage{}, err
+	}
+	if page.Next != nil {
+		page.NextCursor = encodeCursor(*page.Next)
+	}
+	return page, nil
+}
+
+func encodeCursor(cursor domain.QuestionCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(token string) (domain.QuestionCursor, error) {
+	var cursor domain.QuestionCursor
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, err
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, err
+	}
+	if cursor.Id <= 0 {
+		return cursor, ErrInvalidCursor
+	}
+	return cursor, nil
+}
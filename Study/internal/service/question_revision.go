@@ -0,0 +1,165 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/repository"
+	"context"
+	"errors"
+	"strings"
+)
+
+var ErrRevisionNotFound = errors.New("题目版本不存在")
+
+// maxDiffCells LCS 表的上限，超过时直接按整体替换输出，避免超长答案占用过多内存
+const maxDiffCells = 4_000_000
+
+func (svc *questService) Revisions(ctx context.Context, id int64) ([]domain.QuestionRevision, error) {
+	if _, err := svc.repo.FindById(ctx, id); err != nil {
+		return nil, err
+	}
+	return svc.repo.FindRevisions(ctx, id)
+}
+
+func (svc *questService) RevisionDiff(ctx context.Context, id int64, from, to int) (domain.QuestionRevisionDiff, error) {
+	if to <= 0 {
+		revisions, err := svc.Revisions(ctx, id)
+		if err != nil {
+			return domain.QuestionRevisionDiff{}, err
+		}
+		if len(revisions) > 0 {
+			to = revisions[0].Rev
+		}
+	}
+	if from <= 0 {
+		from = max(to-1, 1)
+	}
+	older, err := svc.findRevision(ctx, id, from)
+	if err != nil {
+		return domain.QuestionRevisionDiff{}, err
+	}
+	newer, err := svc.findRevision(ctx, id, to)
+	if err != nil {
+		return domain.QuestionRevisionDiff{}, err
+	}
+	return domain.QuestionRevisionDiff{
+		From:     from,
+		To:       to,
+		Category: diffLines(older.Category, newer.Category),
+		Content:  diffLines(older.Content, newer.Content),
+		Answer:   diffLines(older.Answer, newer.Answer),
+	}, nil
+}
+
+func (svc *questService) Restore(ctx context.Context, id int64, rev int, author string) (domain.Question, error) {
+	if _, err := svc.repo.FindById(ctx, id); err != nil {
+		return domain.Question{}, err
+	}
+	revision, err := svc.findRevision(ctx, id, rev)
+	if err != nil {
+		return domain.Question{}, err
+	}
+	defer svc.index.Invalidate()
+	err = svc.repo.UpdateById(ctx, domain.Question{
+		Id:       id,
+		Category: revision.Category,
+		Content:  revision.Content,
+		Answer:   revision.Answer,
+	}, author, rev)
+	if err != nil {
+		return domain.Question{}, err
+	}
+	return svc.repo.FindById(ctx, id)
+}
+
+func (svc *questService) findRevision(ctx context.Context, id int64, rev int) (domain.QuestionRevision, error) {
+	revision, err := svc.repo.FindRevision(ctx, id, rev)
+	if errors.Is(err, repository.ErrRevisionNotFound) {
+		return domain.QuestionRevision{}, ErrRevisionNotFound
+	}
+	return revision, err
+}
+
+// diffLines 基于最长公共子序列的逐行对比
+func diffLines(before, after string) []domain.DiffLine {
+	a := splitLines(before)
+	b := splitLines(after)
+
+	// 公共前后缀不参与 LCS 计算
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	res := make([]domain.DiffLine, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		res = append(res, domain.DiffLine{Op: domain.DiffEqual, Text: line})
+	}
+	res = append(res, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		res = append(res, domain.DiffLine{Op: domain.DiffEqual, Text: line})
+	}
+	return res
+}
+
+func diffMiddle(a, b []string) []domain.DiffLine {
+	res := make([]domain.DiffLine, 0, len(a)+len(b))
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		for _, line := range a {
+			res = append(res, domain.DiffLine{Op: domain.DiffDelete, Text: line})
+		}
+		for _, line := range b {
+			res = append(res, domain.DiffLine{Op: domain.DiffInsert, Text: line})
+		}
+		return res
+	}
+
+	// lcs[i][j] 为 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			res = append(res, domain.DiffLine{Op: domain.DiffEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			res = append(res, domain.DiffLine{Op: domain.DiffDelete, Text: a[i]})
+			i++
+		default:
+			res = append(res, domain.DiffLine{Op: domain.DiffInsert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		res = append(res, domain.DiffLine{Op: domain.DiffDelete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		res = append(res, domain.DiffLine{Op: domain.DiffInsert, Text: b[j]})
+	}
+	return res
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
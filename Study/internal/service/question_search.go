@@ -0,0 +1,289 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"html"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	// BM25 参数
+	bm25K1 = 1.2
+	bm25B  = 0.75
+	// contentBoost 题目内容相对答案的权重
+	contentBoost = 2
+	// 摘要窗口，命中位置前后保留的字符数
+	snippetBefore = 30
+	snippetAfter  = 90
+)
+
+// tokenize 分词：连续汉字切成二元组（单字保留原字），英文和数字按单词切分，
+// 驼峰单词额外拆出各部分，使 useEffect 同时能被 useeffect、use、effect 命中
+func tokenize(text string) []string {
+	var tokens []string
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.Is(unicode.Han, r):
+			j := i
+			for j < len(runes) && unicode.Is(unicode.Han, runes[j]) {
+				j++
+			}
+			tokens = append(tokens, hanBigrams(runes[i:j])...)
+			i = j
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && !unicode.Is(unicode.Han, runes[j]) &&
+				(unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) {
+				j++
+			}
+			tokens = append(tokens, wordTokens(runes[i:j])...)
+			i = j
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+func hanBigrams(run []rune) []string {
+	if len(run) == 1 {
+		return []string{string(run)}
+	}
+	grams := make([]string, 0, len(run)-1)
+	for i := 0; i+1 < len(run); i++ {
+		grams = append(grams, string(run[i:i+2]))
+	}
+	return grams
+}
+
+func wordTokens(word []rune) []string {
+	tokens := []string{strings.ToLower(string(word))}
+	start := 0
+	for i := 1; i < len(word); i++ {
+		if unicode.IsUpper(word[i]) && !unicode.IsUpper(word[i-1]) {
+			tokens = append(tokens, strings.ToLower(string(word[start:i])))
+			start = i
+		}
+	}
+	if start > 0 {
+		tokens = append(tokens, strings.ToLower(string(word[start:])))
+	}
+	return tokens
+}
+
+// questionIndex 进程内倒排索引，数据库不支持全文检索时使用
+type questionIndex struct {
+	mu    sync.RWMutex
+	built bool
+	// gen 每次失效加一，重建期间发生失效时，重建结果不会被标记为最新
+	gen      uint64
+	postings map[string]map[int64]int
+	docLen   map[int64]int
+	docs     map[int64]domain.Question
+	avgLen   float64
+}
+
+func newQuestionIndex() *questionIndex {
+	return &questionIndex{}
+}
+
+// Invalidate 题库变更后标记索引失效，下次检索时重建
+func (idx *questionIndex) Invalidate() {
+	idx.mu.Lock()
+	idx.built = false
+	idx.gen++
+	idx.mu.Unlock()
+}
+
+// Generation 返回当前代数和索引是否可用，重建前先取代数再读取题库
+func (idx *questionIndex) Generation() (uint64, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.gen, idx.built
+}
+
+// Build 用 gen 代时读取的题库重建索引，读取之后又失效过时下次检索会再次重建
+func (idx *questionIndex) Build(gen uint64, questions []domain.Question) {
+	postings := make(map[string]map[int64]int)
+	docLen := make(map[int64]int, len(questions))
+	docs := make(map[int64]domain.Question, len(questions))
+	total := 0
+	for _, quest := range questions {
+		tokens := tokenize(quest.Content)
+		for i := 1; i < contentBoost; i++ {
+			tokens = append(tokens, tokens...)
+		}
+		tokens = append(tokens, tokenize(quest.Answer)...)
+		for _, token := range tokens {
+			if postings[token] == nil {
+				postings[token] = make(map[int64]int)
+			}
+			postings[token][quest.Id]++
+		}
+		docLen[quest.Id] = len(tokens)
+		docs[quest.Id] = quest
+		total += len(tokens)
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.postings = postings
+	idx.docLen = docLen
+	idx.docs = docs
+	idx.avgLen = 0
+	if len(questions) > 0 {
+		idx.avgLen = float64(total) / float64(len(questions))
+	}
+	idx.built = idx.gen == gen
+}
+
+// Search 按 BM25 打分，要求命中全部查询词元
+func (idx *questionIndex) Search(keyword string, limit int) []domain.QuestionSearchResult {
+	tokens := uniqueTokens(tokenize(keyword))
+	if len(tokens) == 0 {
+		return []domain.QuestionSearchResult{}
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	scores := make(map[int64]float64)
+	matched := make(map[int64]int)
+	n := float64(len(idx.docs))
+	for _, token := range tokens {
+		posting := idx.postings[token]
+		if len(posting) == 0 {
+			return []domain.QuestionSearchResult{}
+		}
+		idf := math.Log(1 + (n-float64(len(posting))+0.5)/(float64(len(posting))+0.5))
+		for id, tf := range posting {
+			norm := bm25K1 * (1 - bm25B + bm25B*float64(idx.docLen[id])/idx.avgLen)
+			scores[id] += idf * float64(tf) * (bm25K1 + 1) / (float64(tf) + norm)
+			matched[id]++
+		}
+	}
+
+	results := make([]domain.QuestionSearchResult, 0)
+	for id, score := range scores {
+		if matched[id] < len(tokens) {
+			continue
+		}
+		results = append(results, domain.QuestionSearchResult{
+			Question: idx.docs[id],
+			Score:    score,
+		})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Question.Id < results[j].Question.Id
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+func uniqueTokens(tokens []string) []string {
+	seen := make(map[string]struct{}, len(tokens))
+	res := tokens[:0]
+	for _, token := range tokens {
+		if _, ok := seen[token]; ok {
+			continue
+		}
+		seen[token] = struct{}{}
+		res = append(res, token)
+	}
+	return res
+}
+
+var markdownSyntax = regexp.MustCompile("(?m)^\\s*(#{1,6}|>|[-*+]|\\d+\\.)\\s+|```[a-zA-Z]*|[*_`~]+|!?\\[([^\\]]*)\\]\\([^)]*\\)")
+
+// plainText 去掉常见 Markdown 标记，用于生成摘要
+func plainText(markdown string) string {
+	text := markdownSyntax.ReplaceAllStringFunc(markdown, func(m string) string {
+		if sub := markdownSyntax.FindStringSubmatch(m); sub[2] != "" {
+			return sub[2]
+		}
+		return " "
+	})
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// buildSnippet 在题目和答案中截取第一个命中附近的文本，命中的关键词用 <mark> 包裹，其余内容做 HTML 转义
+func buildSnippet(quest domain.Question, keyword string) string {
+	text := []rune(plainText(quest.Content + "\n" + quest.Answer))
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		lower[i] = unicode.ToLower(r)
+	}
+	terms := strings.Fields(strings.ToLower(keyword))
+
+	first := -1
+	for _, term := range terms {
+		if pos := runeIndex(lower, []rune(term), 0); pos >= 0 && (first < 0 || pos < first) {
+			first = pos
+		}
+	}
+	if first < 0 {
+		first = 0
+	}
+	start := max(first-snippetBefore, 0)
+	end := min(first+snippetAfter, len(text))
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("…")
+	}
+	for i := start; i < end; {
+		matchLen := 0
+		for _, term := range terms {
+			termRunes := []rune(term)
+			if runeHasPrefix(lower[i:end], termRunes) && len(termRunes) > matchLen {
+				matchLen = len(termRunes)
+			}
+		}
+		if matchLen > 0 {
+			sb.WriteString("<mark>")
+			sb.WriteString(html.EscapeString(string(text[i : i+matchLen])))
+			sb.WriteString("</mark>")
+			i += matchLen
+			continue
+		}
+		sb.WriteString(html.EscapeString(string(text[i])))
+		i++
+	}
+	if end < len(text) {
+		sb.WriteString("…")
+	}
+	return sb.String()
+}
+
+func runeIndex(s, sub []rune, from int) int {
+	for i := from; i+len(sub) <= len(s); i++ {
+		if runeHasPrefix(s[i:], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+func runeHasPrefix(s, prefix []rune) bool {
+	if len(prefix) == 0 || len(prefix) > len(s) {
+		return false
+	}
+	for i := range prefix {
+		if s[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/pkg/textsim"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrDuplicateQuestion = errors.New("已存在高度相似的题目")
+	ErrInvalidMerge      = errors.New("需要指定保留的题目和至少一道被合并的题目")
+)
+
+const (
+	// candidateDistance SimHash 指纹海明距离不超过该值的题目才进一步计算相似度。
+	// 短文本的指纹波动较大，这里放宽筛选，由 Jaccard 相似度做最终判断
+	candidateDistance = 22
+	// duplicateRejectSimilarity 相似度不低于该值视为重复，默认拒绝写入
+	duplicateRejectSimilarity = 0.8
+	// duplicateWarnSimilarity 相似度不低于该值视为相似，写入时给出提示
+	duplicateWarnSimilarity = 0.5
+	// minClusterSimilarity 查询重复题目时允许的最低相似度
+	minClusterSimilarity = 0.3
+)
+
+// findSimilar 为每段内容找出相似度不低于 duplicateWarnSimilarity 的已有题目，按相似度从高到低排序
+func (svc *questService) findSimilar(ctx context.Context, contents []string) ([][]domain.SimilarQuestion, error) {
+	fingerprints, err := svc.repo.FindFingerprints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([][]int64, len(contents))
+	var ids []int64
+	seen := make(map[int64]bool)
+	for i, content := range contents {
+		hash := textsim.Simhash(content)
+		if hash == 0 {
+			continue
+		}
+		for _, fp := range fingerprints {
+			if fp.Simhash != 0 && textsim.Distance(hash, fp.Simhash) <= candidateDistance {
+				candidates[i] = append(candidates[i], fp.Id)
+				if !seen[fp.Id] {
+					seen[fp.Id] = true
+					ids = append(ids, fp.Id)
+				}
+			}
+		}
+	}
+
+	res := make([][]domain.SimilarQuestion, len(contents))
+	if len(ids) == 0 {
+		return res, nil
+	}
+	quests, err := svc.repo.FindByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	questMap := make(map[int64]domain.Question, len(quests))
+	for _, quest := range quests {
+		questMap[quest.Id] = quest
+	}
+	for i, content := range contents {
+		for _, id := range candidates[i] {
+			quest, ok := questMap[id]
+			if !ok {
+				continue
+			}
+			if sim := textsim.Jaccard(content, quest.Content); sim >= duplicateWarnSimilarity {
+				res[i] = append(res[i], domain.SimilarQuestion{Question: quest, Similarity: sim})
+			}
+		}
+		sort.Slice(res[i], func(a, b int) bool {
+			if res[i][a].Similarity != res[i][b].Similarity {
+				return res[i][a].Similarity > res[i][b].Similarity
+			}
+			return res[i][a].Id < res[i][b].Id
+		})
+	}
+	return res, nil
+}
+
+// checkImportSimilar 检查导入的题目与已有题目、以及同批次前面的题目是否近似。
+// 高度相似的题目标记为跳过（force 时保留），相似的题目给出提示；内容完全相同的交给按内容指纹的去重处理
+func (svc *questService) checkImportSimilar(ctx context.Context, report *domain.QuestionImportReport,
+	valid []domain.Question, validIdx []int, force bool) ([]domain.Question, []int, error) {
+	if len(valid) == 0 {
+		return valid, validIdx, nil
+	}
+	contents := make([]string, 0, len(valid))
+	for _, quest := range valid {
+		contents = append(contents, quest.Content)
+	}
+	similar, err := svc.findSimilar(ctx, contents)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	type accepted struct {
+		index   int
+		content string
+		hash    uint64
+	}
+	batch := make([]accepted, 0, len(valid))
+	resValid := valid[:0]
+	resIdx := validIdx[:0]
+	for j, quest := range valid {
+		item := &report.Items[validIdx[j]]
+		if len(similar[j]) > 0 {
+			top := similar[j][0]
+			duplicate := top.Similarity >= duplicateRejectSimilarity && !sameContent(top.Content, quest.Content)
+			switch {
+			case duplicate && !force:
+				item.Status = domain.ImportSkipped
+				item.SimilarTo = top.Id
+				item.Warning = fmt.Sprintf("与题目 #%d 高度相似，已跳过", top.Id)
+				continue
+			case duplicate:
+				item.SimilarTo = top.Id
+				item.Warning = fmt.Sprintf("与题目 #%d 高度相似", top.Id)
+			case top.Similarity < duplicateRejectSimilarity:
+				item.SimilarTo = top.Id
+				item.Warning = fmt.Sprintf("与题目 #%d 相似", top.Id)
+			}
+		}
+
+		hash := textsim.Simhash(quest.Content)
+		if !force {
+			dup := -1
+			for _, prev := range batch {
+				if textsim.Distance(hash, prev.hash) <= candidateDistance &&
+					textsim.Jaccard(prev.content, quest.Content) >= duplicateRejectSimilarity &&
+					!sameContent(prev.content, quest.Content) {
+					dup = prev.index
+					break
+				}
+			}
+			if dup >= 0 {
+				item.Status = domain.ImportSkipped
+				item.Warning = fmt.Sprintf("与第 %d 条高度相似，已跳过", dup+1)
+				continue
+			}
+		}
+		batch = append(batch, accepted{index: validIdx[j], content: quest.Content, hash: hash})
+		resValid = append(resValid, quest)
+		resIdx = append(resIdx, validIdx[j])
+	}
+	return resValid, resIdx, nil
+}
+
+func (svc *questService) Duplicates(ctx context.Context, similarity float64) ([]domain.DuplicateCluster, error) {
+	if similarity <= 0 {
+		similarity = duplicateWarnSimilarity
+	}
+	similarity = min(max(similarity, minClusterSimilarity), 1)
+	quests, err := svc.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]uint64, len(quests))
+	for i, quest := range quests {
+		hashes[i] = textsim.Simhash(quest.Content)
+	}
+
+	// 并查集，把两两相似的题目连成一组
+	parent := make([]int, len(quests))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(x int) int {
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+	// 与 findSimilar 一样先用指纹的海明距离筛选，只对候选计算 Jaccard 相似度。
+	// 按指纹分段分桶只能保证找到距离小于段数的题目，会漏掉距离较大的相似短文本
+	minSim := make(map[int]float64)
+	for i := range quests {
+		if hashes[i] == 0 {
+			continue
+		}
+		for j := i + 1; j < len(quests); j++ {
+			if hashes[j] == 0 || textsim.Distance(hashes[i], hashes[j]) > candidateDistance {
+				continue
+			}
+			sim := textsim.Jaccard(quests[i].Content, quests[j].Content)
+			if sim < similarity {
+				continue
+			}
+			ri, rj := find(i), find(j)
+			if ri != rj {
+				parent[rj] = ri
+				if s, ok := minSim[rj]; ok {
+					sim = min(sim, s)
+				}
+			}
+			if s, ok := minSim[ri]; ok {
+				sim = min(sim, s)
+			}
+			minSim[ri] = sim
+		}
+	}
+
+	groups := make(map[int][]domain.Question)
+	var roots []int
+	for i, quest := range quests {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], quest)
+	}
+	res := make([]domain.DuplicateCluster, 0)
+	for _, root := range roots {
+		if len(groups[root]) < 2 {
+			continue
+		}
+		res = append(res, domain.DuplicateCluster{
+			Questions:     groups[root],
+			MinSimilarity: minSim[root],
+		})
+	}
+	return res, nil
+}
+
+func (svc *questService) Merge(ctx context.Context, keepId int64, mergeIds []int64) (domain.Question, error) {
+	seen := map[int64]bool{keepId: true}
+	ids := make([]int64, 0, len(mergeIds))
+	for _, id := range mergeIds {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if keepId <= 0 || len(ids) == 0 {
+		return domain.Question{}, ErrInvalidMerge
+	}
+	defer svc.index.Invalidate()
+	if err := svc.repo.Merge(ctx, keepId, ids); err != nil {
+		return domain.Question{}, err
+	}
+	return svc.repo.FindById(ctx, keepId)
+}
+
+// sameContent 与内容指纹相同的归一化规则：忽略首尾空白、连续空白和大小写
+func sameContent(a, b string) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(a), " "), strings.Join(strings.Fields(b), " "))
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/pkg/textsim"
+	"Training/Study/internal/repository"
+	"context"
+	"testing"
+)
+
+// allQuestRepo 只实现 FindAll 的题目仓库
+type allQuestRepo struct {
+	repository.QuestRepository
+	quests []domain.Question
+}
+
+func (r *allQuestRepo) FindAll(ctx context.Context) ([]domain.Question, error) {
+	return r.quests, nil
+}
+
+func TestDuplicatesFindsDistantFingerprints(t *testing.T) {
+	a, b := "MySQL 的索引为什么使用 B+ 树", "为什么 MySQL 索引使用 B+ 树"
+	// 两个指纹距离 18，按 8 位分段时没有一段相同，但仍在候选距离内
+	if d := textsim.Distance(textsim.Simhash(a), textsim.Simhash(b)); d < 8 || d > candidateDistance {
+		t.Fatalf("distance = %d, want between 8 and %d", d, candidateDistance)
+	}
+	svc := &questService{repo: &allQuestRepo{quests: []domain.Question{
+		{Id: 1, Content: a},
+		{Id: 2, Content: "Redis 的持久化方式有哪些"},
+		{Id: 3, Content: b},
+	}}}
+
+	clusters, err := svc.Duplicates(context.Background(), duplicateWarnSimilarity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters) != 1 {
+		t.Fatalf("got %d clusters, want 1: %+v", len(clusters), clusters)
+	}
+	cluster := clusters[0]
+	if len(cluster.Questions) != 2 || cluster.Questions[0].Id != 1 || cluster.Questions[1].Id != 3 {
+		t.Errorf("cluster = %+v, want questions 1 and 3", cluster.Questions)
+	}
+	if sim := textsim.Jaccard(a, b); cluster.MinSimilarity != sim {
+		t.Errorf("min similarity = %v, want %v", cluster.MinSimilarity, sim)
+	}
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/repository"
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+var (
+	ErrSessionFinished  = errors.New("复习会话已结束")
+	ErrSessionEmpty     = errors.New("没有可复习的题目")
+	ErrQuestionMismatch = errors.New("提交的题目不是当前待复习的题目")
+	// ErrSessionQuestionGone 当前题目已被删除，会话已跳过这道题，重新获取下一题即可
+	ErrSessionQuestionGone = errors.New("题目已被删除，已跳过")
+)
+
+const (
+	defaultSessionSize = 20
+	maxSessionSize     = 200
+)
+
+// ReviewSessionNext 会话中的下一道题
+type ReviewSessionNext struct {
+	SessionId int64            `json:"session_id"`
+	Done      bool             `json:"done"`
+	Position  int              `json:"position"`
+	Total     int              `json:"total"`
+	Question  *domain.Question `json:"question,omitempty"`
+}
+
+type ReviewSessionService interface {
+	// Start 按分类和数量创建复习会话，优先选择到期题目，不足时补充掌握度低的题目
+	Start(ctx context.Context, category string, size int) (domain.ReviewSession, error)
+	Get(ctx context.Context, id int64) (domain.ReviewSession, error)
+	// Active 获取最近一个未结束的会话，用于刷新页面后恢复
+	Active(ctx context.Context) (domain.ReviewSession, error)
+	Next(ctx context.Context, id int64) (ReviewSessionNext, error)
+	// Submit 对当前题目提交评分，questionId 为 0 时不校验题目
+	Submit(ctx context.Context, id int64, questionId int64, grade int, duration time.Duration) (ReviewSessionNext, error)
+	Finish(ctx context.Context, id int64) (domain.ReviewSessionSummary, error)
+	Summary(ctx context.Context, id int64) (domain.ReviewSessionSummary, error)
+}
+
+type reviewSessionService struct {
+	repo      repository.ReviewSessionRepository
+	questRepo repository.QuestRepository
+	questSvc  QuestService
+}
+
+func NewReviewSessionService(repo repository.ReviewSessionRepository,
+	questRepo repository.QuestRepository, questSvc QuestService) ReviewSessionService {
+	return &reviewSessionService{
+		repo:      repo,
+		questRepo: questRepo,
+		questSvc:  questSvc,
+	}
+}
+
+func (svc *reviewSessionService) Start(ctx context.Context, category string, size int) (domain.ReviewSession, error) {
+	if size <= 0 {
+		size = defaultSessionSize
+	}
+	if size > maxSessionSize {
+		size = maxSessionSize
+	}
+
+	questions, err := svc.pickQuestions(ctx, category, size)
+	if err != nil {
+		return domain.ReviewSession{}, err
+	}
+	if len(questions) == 0 {
+		return domain.ReviewSession{}, ErrSessionEmpty
+	}
+
+	items := make([]domain.ReviewSessionItem, 0, len(questions))
+	for i, quest := range questions {
+		items = append(items, domain.ReviewSessionItem{
+			Position:      i,
+			QuestionId:    quest.Id,
+			MasteryBefore: quest.MasteryLevel,
+			MasteryAfter:  quest.MasteryLevel,
+		})
+	}
+	id, err := svc.repo.Create(ctx, domain.ReviewSession{
+		Category: category,
+		Size:     len(items),
+		Items:    items,
+	})
+	if err != nil {
+		return domain.ReviewSession{}, err
+	}
+	return svc.repo.FindById(ctx, id)
+}
+
+// pickQuestions 先取到期题目，再按掌握度从低到高补足
+func (svc *reviewSessionService) pickQuestions(ctx context.Context, category string, size int) ([]domain.Question, error) {
+	picked, err := svc.questRepo.FindDue(ctx, category, time.Now(), size)
+	if err != nil {
+		return nil, err
+	}
+	if len(picked) >= size {
+		return picked, nil
+	}
+
+	var candidates []domain.Question
+	if category != "" {
+		candidates, err = svc.questRepo.FindByCategory(ctx, category)
+	} else {
+		candidates, err = svc.questRepo.FindAll(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].MasteryLevel != candidates[j].MasteryLevel {
+			return candidates[i].MasteryLevel < candidates[j].MasteryLevel
+		}
+		return candidates[i].NextReviewAt.Before(candidates[j].NextReviewAt)
+	})
+
+	seen := make(map[int64]struct{}, len(picked))
+	for _, quest := range picked {
+		seen[quest.Id] = struct{}{}
+	}
+	for _, quest := range candidates {
+		if len(picked) >= size {
+			break
+		}
+		if _, ok := seen[quest.Id]; ok {
+			continue
+		}
+		picked = append(picked, quest)
+	}
+	return picked, nil
+}
+
+func (svc *reviewSessionService) Get(ctx context.Context, id int64) (domain.ReviewSession, error) {
+	return svc.repo.FindById(ctx, id)
+}
+
+func (svc *reviewSessionService) Active(ctx context.Context) (domain.ReviewSession, error) {
+	return svc.repo.FindLatestActive(ctx)
+}
+
+func (svc *reviewSessionService) Next(ctx context.Context, id int64) (ReviewSessionNext, error) {
+	session, err := svc.repo.FindById(ctx, id)
+	if err != nil {
+		return ReviewSessionNext{}, err
+	}
+	return svc.next(ctx, session)
+}
+
+func (svc *reviewSessionService) next(ctx context.Context, session domain.ReviewSession) (ReviewSessionNext, error) {
+	res := ReviewSessionNext{
+		SessionId: session.Id,
+		Total:     len(session.Items),
+		Done:      true,
+	}
+	if session.Status != domain.ReviewSessionActive {
+		return res, nil
+	}
+	for {
+		item, ok := svc.currentItem(session)
+		if !ok {
+			// 题目被彻底删除时会话题目会被直接标记为跳过，这里补上结束会话
+			if _, err := svc.finishIfDone(ctx, session); err != nil {
+				return ReviewSessionNext{}, err
+			}
+			return res, nil
+		}
+		quest, err := svc.questRepo.FindById(ctx, item.QuestionId)
+		if errors.Is(err, repository.ErrQuestionNotFound) {
+			// 题目在会话开始后被删除，跳过它，否则会话永远停在这道题上
+			if session, err = svc.skip(ctx, session, item); err != nil {
+				return ReviewSessionNext{}, err
+			}
+			if session.Status != domain.ReviewSessionActive {
+				return res, nil
+			}
+			continue
+		}
+		if err != nil {
+			return ReviewSessionNext{}, err
+		}
+		res.Done = false
+		res.Position = item.Position
+		res.Question = &quest
+		return res, nil
+	}
+}
+
+// skip 跳过题目已被删除的会话题目，没有剩余题目时结束会话
+func (svc *reviewSessionService) skip(ctx context.Context, session domain.ReviewSession,
+	item domain.ReviewSessionItem) (domain.ReviewSession, error) {
+	err := svc.repo.SkipItem(ctx, session.Id, item.Position)
+	if err != nil && !errors.Is(err, repository.ErrReviewItemAnswered) {
+		return domain.ReviewSession{}, err
+	}
+	now := time.Now()
+	item.Skipped = true
+	item.ReviewedAt = &now
+	session.Items[item.Position] = item
+	return svc.finishIfDone(ctx, session)
+}
+
+// finishIfDone 所有题目都已作答或跳过时结束会话
+func (svc *reviewSessionService) finishIfDone(ctx context.Context, session domain.ReviewSession) (domain.ReviewSession, error) {
+	if _, ok := svc.currentItem(session); ok {
+		return session, nil
+	}
+	if err := svc.repo.Finish(ctx, session.Id); err != nil {
+		return domain.ReviewSession{}, err
+	}
+	session.Status = domain.ReviewSessionFinished
+	return session, nil
+}
+
+func (svc *reviewSessionService) currentItem(session domain.ReviewSession) (domain.ReviewSessionItem, bool) {
+	for _, item := range session.Items {
+		if item.ReviewedAt == nil {
+			return item, true
+		}
+	}
+	return domain.ReviewSessionItem{}, false
+}
+
+func (svc *reviewSessionService) Submit(ctx context.Context, id int64, questionId int64,
+	grade int, duration time.Duration) (ReviewSessionNext, error) {
+	session, err := svc.repo.FindById(ctx, id)
+	if err != nil {
+		return ReviewSessionNext{}, err
+	}
+	if session.Status != domain.ReviewSessionActive {
+		return ReviewSessionNext{}, ErrSessionFinished
+	}
+	item, ok := svc.currentItem(session)
+	if !ok {
+		return ReviewSessionNext{}, ErrSessionFinished
+	}
+	if questionId != 0 && questionId != item.QuestionId {
+		return ReviewSessionNext{}, ErrQuestionMismatch
+	}
+
+	if grade < MinReviewGrade || grade > MaxReviewGrade {
+		return ReviewSessionNext{}, ErrInvalidGrade
+	}
+
+	now := time.Now()
+	item.Grade = &grade
+	item.DurationMs = duration.Milliseconds()
+	item.ReviewedAt = &now
+	// 复习计划基于事务中锁定的题目计算，和会话题目的作答结果在同一事务中保存
+	answered, err := svc.repo.Answer(ctx, id, item, func(quest domain.Question) domain.Question {
+		return scheduleReview(quest, grade, now)
+	})
+	svc.questSvc.InvalidateSearch()
+	switch {
+	case errors.Is(err, repository.ErrReviewItemAnswered):
+		// 同一道题被并发提交了两次
+		return ReviewSessionNext{}, ErrQuestionMismatch
+	case errors.Is(err, repository.ErrQuestionNotFound):
+		return ReviewSessionNext{}, svc.questionGone(ctx, session, item)
+	case err != nil:
+		return ReviewSessionNext{}, err
+	}
+
+	session.Items[item.Position] = answered
+	if session, err = svc.finishIfDone(ctx, session); err != nil {
+		return ReviewSessionNext{}, err
+	}
+	return svc.next(ctx, session)
+}
+
+// questionGone 跳过已被删除的当前题目并返回 ErrSessionQuestionGone
+func (svc *reviewSessionService) questionGone(ctx context.Context, session domain.ReviewSession,
+	item domain.ReviewSessionItem) error {
+	if _, err := svc.skip(ctx, session, item); err != nil {
+		return err
+	}
+	return ErrSessionQuestionGone
+}
+
+func (svc *reviewSessionService) Finish(ctx context.Context, id int64) (domain.ReviewSessionSummary, error) {
+	if err := svc.repo.Finish(ctx, id); err != nil {
+		return domain.ReviewSessionSummary{}, err
+	}
+	return svc.Summary(ctx, id)
+}
+
+func (svc *reviewSessionService) Summary(ctx context.Context, id int64) (domain.ReviewSessionSummary, error) {
+	session, err := svc.repo.FindById(ctx, id)
+	if err != nil {
+		return domain.ReviewSessionSummary{}, err
+	}
+	summary := domain.ReviewSessionSummary{
+		SessionId:      session.Id,
+		Status:         session.Status,
+		Total:          len(session.Items),
+		MasteryChanges: []domain.MasteryChange{},
+	}
+	for _, item := range session.Items {
+		if item.Skipped {
+			summary.Skipped++
+			continue
+		}
+		if item.ReviewedAt == nil {
+			continue
+		}
+		summary.Reviewed++
+		summary.DurationMs += item.DurationMs
+		if *item.Grade >= passGrade {
+			summary.Correct++
+		}
+		if item.MasteryBefore != item.MasteryAfter {
+			summary.MasteryChanges = append(summary.MasteryChanges, domain.MasteryChange{
+				QuestionId: item.QuestionId,
+				Before:     item.MasteryBefore,
+				After:      item.MasteryAfter,
+			})
+		}
+	}
+	return summary, nil
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"math"
+	"time"
+)
+
+const (
+	// MinReviewGrade / MaxReviewGrade 复习评分范围，0 表示完全想不起来，5 表示轻松答出
+	MinReviewGrade = 0
+	MaxReviewGrade = 5
+	// passGrade 评分达到该值视为答对
+	passGrade = 3
+	// minEaseFactor SM-2 难度因子下限
+	minEaseFactor = 1.3
+	// masteredInterval 复习间隔达到该天数视为已掌握
+	masteredInterval = 21
+)
+
+// scheduleReview 按 SM-2 算法根据本次评分计算下一次复习计划
+func scheduleReview(quest domain.Question, grade int, now time.Time) domain.Question {
+	if quest.EaseFactor == 0 {
+		quest.EaseFactor = domain.DefaultEaseFactor
+	}
+
+	if grade < passGrade {
+		// 答错从头开始，第二天再复习
+		quest.Repetitions = 0
+		quest.Interval = 1
+	} else {
+		switch quest.Repetitions {
+		case 0:
+			quest.Interval = 1
+		case 1:
+			quest.Interval = 6
+		default:
+			quest.Interval = int(math.Round(float64(quest.Interval) * quest.EaseFactor))
+		}
+		quest.Repetitions++
+	}
+
+	diff := float64(MaxReviewGrade - grade)
+	quest.EaseFactor += 0.1 - diff*(0.08+diff*0.02)
+	if quest.EaseFactor < minEaseFactor {
+		quest.EaseFactor = minEaseFactor
+	}
+
+	quest.NextReviewAt = now.AddDate(0, 0, quest.Interval)
+	quest.MasteryLevel = masteryOf(quest, grade)
+	return quest
+}
+
+// masteryOf 根据复习计划折算三档掌握度
+func masteryOf(quest domain.Question, grade int) int {
+	if grade >= passGrade && quest.Interval >= masteredInterval {
+		return domain.MasteryMastered
+	}
+	return domain.MasteryLearning
+}
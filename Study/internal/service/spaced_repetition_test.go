@@ -0,0 +1,83 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestScheduleReviewIntervals(t *testing.T) {
+	now := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	quest := domain.Question{}
+	// 第一次和第二次答对分别间隔 1 天和 6 天，之后按上次间隔乘以难度因子
+	for i, want := range []int{1, 6, 15, 38} {
+		quest = scheduleReview(quest, 4, now)
+		if quest.Interval != want || quest.Repetitions != i+1 {
+			t.Fatalf("review %d: interval = %d, repetitions = %d, want %d, %d",
+				i+1, quest.Interval, quest.Repetitions, want, i+1)
+		}
+		// 评分 4 时难度因子不变
+		if math.Abs(quest.EaseFactor-domain.DefaultEaseFactor) > 1e-9 {
+			t.Fatalf("review %d: ease factor = %v, want %v", i+1, quest.EaseFactor, domain.DefaultEaseFactor)
+		}
+	}
+	if want := now.AddDate(0, 0, 38); !quest.NextReviewAt.Equal(want) {
+		t.Errorf("next review at = %v, want %v", quest.NextReviewAt, want)
+	}
+	if quest.MasteryLevel != domain.MasteryMastered {
+		t.Errorf("mastery = %d, want mastered", quest.MasteryLevel)
+	}
+}
+
+func TestScheduleReviewIntervalUsesEaseFactor(t *testing.T) {
+	quest := domain.Question{EaseFactor: 2.0, Interval: 10, Repetitions: 3}
+	quest = scheduleReview(quest, 5, time.Now())
+	// 间隔用更新前的难度因子计算
+	if quest.Interval != 20 || quest.Repetitions != 4 {
+		t.Errorf("interval = %d, repetitions = %d, want 20, 4", quest.Interval, quest.Repetitions)
+	}
+	if math.Abs(quest.EaseFactor-2.1) > 1e-9 {
+		t.Errorf("ease factor = %v, want 2.1", quest.EaseFactor)
+	}
+}
+
+func TestScheduleReviewFailureResets(t *testing.T) {
+	for grade := MinReviewGrade; grade < passGrade; grade++ {
+		quest := domain.Question{
+			EaseFactor:   2.5,
+			Interval:     30,
+			Repetitions:  5,
+			MasteryLevel: domain.MasteryMastered,
+		}
+		now := time.Now()
+		quest = scheduleReview(quest, grade, now)
+		if quest.Repetitions != 0 || quest.Interval != 1 {
+			t.Errorf("grade %d: interval = %d, repetitions = %d, want 1, 0", grade, quest.Interval, quest.Repetitions)
+		}
+		if !quest.NextReviewAt.Equal(now.AddDate(0, 0, 1)) {
+			t.Errorf("grade %d: next review at = %v, want tomorrow", grade, quest.NextReviewAt)
+		}
+		if quest.MasteryLevel != domain.MasteryLearning {
+			t.Errorf("grade %d: mastery = %d, want learning", grade, quest.MasteryLevel)
+		}
+	}
+}
+
+func TestScheduleReviewEaseFactorFloor(t *testing.T) {
+	quest := domain.Question{}
+	for i := 0; i < 10; i++ {
+		quest = scheduleReview(quest, MinReviewGrade, time.Now())
+		if quest.EaseFactor < minEaseFactor {
+			t.Fatalf("review %d: ease factor = %v, below %v", i+1, quest.EaseFactor, minEaseFactor)
+		}
+	}
+	if quest.EaseFactor != minEaseFactor {
+		t.Errorf("ease factor = %v, want %v", quest.EaseFactor, minEaseFactor)
+	}
+	// 答对后从下限开始回升
+	quest = scheduleReview(quest, MaxReviewGrade, time.Now())
+	if math.Abs(quest.EaseFactor-(minEaseFactor+0.1)) > 1e-9 {
+		t.Errorf("ease factor = %v, want %v", quest.EaseFactor, minEaseFactor+0.1)
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/repository"
+	"context"
+	"strings"
+)
+
+type TagService interface {
+	// Tree 返回标签树，每个节点的题目数包含子标签
+	Tree(ctx context.Context) ([]domain.Tag, error)
+	// Create 按路径创建标签，缺失的上级标签会一并创建
+	Create(ctx context.Context, path string) (domain.Tag, error)
+	// SetQuestionTags 整体替换题目的标签，题目分类始终保留为标签之一
+	SetQuestionTags(ctx context.Context, questionId int64, paths []string) error
+}
+
+type tagService struct {
+	repo      repository.TagRepository
+	questRepo repository.QuestRepository
+	questSvc  QuestService
+}
+
+func NewTagService(repo repository.TagRepository, questRepo repository.QuestRepository, questSvc QuestService) TagService {
+	return &tagService{
+		repo:      repo,
+		questRepo: questRepo,
+		questSvc:  questSvc,
+	}
+}
+
+func (svc *tagService) Tree(ctx context.Context) ([]domain.Tag, error) {
+	tags, err := svc.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	children := make(map[int64][]domain.Tag, len(tags))
+	for _, tag := range tags {
+		children[tag.ParentId] = append(children[tag.ParentId], tag)
+	}
+	var build func(parentId int64) ([]domain.Tag, int64)
+	build = func(parentId int64) ([]domain.Tag, int64) {
+		nodes := children[parentId]
+		var total int64
+		for i := range nodes {
+			var sub int64
+			nodes[i].Children, sub = build(nodes[i].Id)
+			nodes[i].QuestionCount += sub
+			total += nodes[i].QuestionCount
+		}
+		if nodes == nil {
+			nodes = []domain.Tag{}
+		}
+		return nodes, total
+	}
+	tree, _ := build(0)
+	return tree, nil
+}
+
+func (svc *tagService) Create(ctx context.Context, path string) (domain.Tag, error) {
+	return svc.repo.Create(ctx, path)
+}
+
+func (svc *tagService) SetQuestionTags(ctx context.Context, questionId int64, paths []string) error {
+	quest, err := svc.questRepo.FindById(ctx, questionId)
+	if err != nil {
+		return err
+	}
+	res := make([]string, 0, len(paths)+1)
+	res = append(res, quest.Category)
+	for _, path := range paths {
+		if strings.TrimSpace(path) != "" {
+			res = append(res, path)
+		}
+	}
+	// 检索结果带有题目的标签
+	defer svc.questSvc.InvalidateSearch()
+	return svc.repo.SetQuestionTags(ctx, questionId, res)
+}
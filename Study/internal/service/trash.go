@@ -0,0 +1,110 @@
+package service
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/repository"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var ErrUnknownTrashType = errors.New("未知的回收站类型")
+
+// defaultTrashRetentionDays 回收站默认保留天数，可通过环境变量 TRASH_RETENTION_DAYS 修改
+const defaultTrashRetentionDays = 30
+
+type TrashService interface {
+	// List 回收站中的八股题和刷题，按删除时间倒序，typ 为空时返回全部
+	List(ctx context.Context, typ string) ([]domain.TrashItem, error)
+	Restore(ctx context.Context, typ string, id int64) error
+	// Purge 彻底删除超过保留期的题目，返回删除的条数
+	Purge(ctx context.Context) (int64, error)
+}
+
+type trashService struct {
+	questRepo  repository.QuestRepository
+	codingRepo repository.CodingProblemRepository
+	questSvc   QuestService
+	retention  time.Duration
+}
+
+func NewTrashService(questRepo repository.QuestRepository, codingRepo repository.CodingProblemRepository,
+	questSvc QuestService) TrashService {
+	return &trashService{
+		questRepo:  questRepo,
+		codingRepo: codingRepo,
+		questSvc:   questSvc,
+		retention:  trashRetention(),
+	}
+}
+
+// trashRetention 读取 TRASH_RETENTION_DAYS，未设置或无效时使用默认值
+func trashRetention() time.Duration {
+	days := defaultTrashRetentionDays
+	if value := os.Getenv("TRASH_RETENTION_DAYS"); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n > 0 {
+			days = n
+		} else {
+			log.Printf("TRASH_RETENTION_DAYS=%q 无效，使用默认值 %d 天", value, defaultTrashRetentionDays)
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+func (svc *trashService) List(ctx context.Context, typ string) ([]domain.TrashItem, error) {
+	if typ != "" && typ != domain.TrashQuestion && typ != domain.TrashCodingProblem {
+		return nil, ErrUnknownTrashType
+	}
+	var items []domain.TrashItem
+	if typ == "" || typ == domain.TrashQuestion {
+		quests, err := svc.questRepo.FindDeleted(ctx)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, quests...)
+	}
+	if typ == "" || typ == domain.TrashCodingProblem {
+		problems, err := svc.codingRepo.FindDeleted(ctx)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, problems...)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	for i := range items {
+		items[i].PurgeAt = items[i].DeletedAt.Add(svc.retention)
+	}
+	if items == nil {
+		items = []domain.TrashItem{}
+	}
+	return items, nil
+}
+
+func (svc *trashService) Restore(ctx context.Context, typ string, id int64) error {
+	switch typ {
+	case domain.TrashQuestion:
+		return svc.questSvc.RestoreFromTrash(ctx, id)
+	case domain.TrashCodingProblem:
+		return svc.codingRepo.Restore(ctx, id)
+	default:
+		return ErrUnknownTrashType
+	}
+}
+
+func (svc *trashService) Purge(ctx context.Context) (int64, error) {
+	before := time.Now().Add(-svc.retention)
+	defer svc.questSvc.InvalidateSearch()
+	quests, err := svc.questRepo.PurgeDeleted(ctx, before)
+	if err != nil {
+		return 0, err
+	}
+	problems, err := svc.codingRepo.PurgeDeleted(ctx, before)
+	return quests + problems, err
+}
@@ -0,0 +1,90 @@
+package web
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/repository"
+	"Training/Study/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CodingAttemptHandler struct {
+	service service.CodingAttemptService
+}
+
+func NewCodingAttemptHandler(service service.CodingAttemptService) *CodingAttemptHandler {
+	return &CodingAttemptHandler{
+		service: service,
+	}
+}
+
+func (h *CodingAttemptHandler) RegisterRoutes(server *gin.Engine) {
+	codingGroup := server.Group("/api/coding")
+
+	// 做题记录，学习状态由最近一次做题结果决定
+	codingGroup.POST("/problems/:id/attempts", h.Record)
+	codingGroup.GET("/problems/:id/attempts", h.List)
+}
+
+// Record 记录一次做题，result 为 accepted、wrong、timeout 或 gave_up，time_spent 单位为秒
+func (h *CodingAttemptHandler) Record(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid problem ID"})
+		return
+	}
+
+	var req struct {
+		Language  string `json:"language"`
+		Code      string `json:"code"`
+		Result    string `json:"result" binding:"required"`
+		TimeSpent int64  `json:"time_spent"`
+		Notes     string `json:"notes"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	attempt, err := h.service.Record(c.Request.Context(), domain.CodingAttempt{
+		ProblemId: id,
+		Language:  req.Language,
+		Code:      req.Code,
+		Result:    req.Result,
+		TimeSpent: req.TimeSpent,
+		Notes:     req.Notes,
+	})
+	switch {
+	case errors.Is(err, service.ErrInvalidAttempt):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, repository.ErrCodingProblemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Problem not found"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, attempt)
+}
+
+func (h *CodingAttemptHandler) List(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid problem ID"})
+		return
+	}
+
+	attempts, err := h.service.List(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"attempts": attempts,
+		"total":    len(attempts),
+	})
+}
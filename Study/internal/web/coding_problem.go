@@ -1,7 +1,9 @@
 package web
 
 import (
+	"Training/Study/internal/repository"
 	"Training/Study/internal/service"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -31,9 +33,7 @@ func (h *CodingProblemHandler) RegisterRoutes(server *gin.Engine) {
 	codingGroup.GET("/daily/history", h.GetDailyProblemHistory)
 	codingGroup.GET("/random", h.GetRandomProblem)
 	codingGroup.GET("/stats", h.GetStats)
-
-	// 学习状态管理
-	codingGroup.PUT("/problems/:id/study-status", h.UpdateStudyStatus)
+	codingGroup.GET("/review/due", h.FindDue)
 
 	// 管理功能
 }
@@ -99,6 +99,10 @@ func (h *CodingProblemHandler) GetProblemById(c *gin.Context) {
 	}
 
 	problem, err := h.service.GetProblemById(c.Request.Context(), id)
+	if errors.Is(err, repository.ErrCodingProblemNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Problem not found"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -133,69 +137,41 @@ func (h *CodingProblemHandler) GetProblemsByDifficulty(c *gin.Context) {
 	})
 }
 
+// GetDailyProblem 获取今天的每日一题，?source= 指定来源，默认为 leetcode
 func (h *CodingProblemHandler) GetDailyProblem(c *gin.Context) {
-	problem, err := h.service.GetDailyProblem(c.Request.Context())
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	problem, err := h.service.GetDailyProblem(c.Request.Context(), c.Query("source"))
+	if errors.Is(err, service.ErrUnknownProblemSource) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
-	if problem == nil {
+	if errors.Is(err, repository.ErrCodingProblemNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "No daily problem found"})
 		return
 	}
-	c.JSON(http.StatusOK, problem)
-}
-
-func (h *CodingProblemHandler) UpdateStudyStatus(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid problem ID"})
-		return
-	}
-
-	var req struct {
-		StudyStatus string `json:"study_status" binding:"required"`
-	}
-
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// 验证学习状态值
-	validStatuses := map[string]bool{
-		"not_started": true,
-		"in_progress": true,
-		"completed":   true,
-	}
-
-	if !validStatuses[req.StudyStatus] {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid study status"})
-		return
-	}
-
-	// 获取当前题目
-	problem, err := h.service.GetProblemById(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-
-	// 更新学习状态
-	problem.StudyStatus = req.StudyStatus
-	if req.StudyStatus != "not_started" {
-		now := time.Now()
-		problem.LastStudied = &now
+	if problem == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No daily problem found"})
+		return
 	}
+	c.JSON(http.StatusOK, problem)
+}
 
-	err = h.service.UpdateProblem(c.Request.Context(), problem)
+// FindDue 到期需要重做的题目，limit 默认 50
+func (h *CodingProblemHandler) FindDue(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	problems, err := h.service.FindDue(c.Request.Context(), limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Study status updated successfully"})
+	c.JSON(http.StatusOK, gin.H{
+		"problems": problems,
+		"total":    len(problems),
+	})
 }
 
 // GetDailyProblemHistory 获取每日一题历史
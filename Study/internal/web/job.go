@@ -0,0 +1,79 @@
+package web
+
+import (
+	"Training/Study/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JobHandler struct {
+	runner service.JobRunner
+}
+
+func NewJobHandler(runner service.JobRunner) *JobHandler {
+	return &JobHandler{
+		runner: runner,
+	}
+}
+
+func (h *JobHandler) RegisterRoutes(server *gin.Engine) {
+	jobGroup := server.Group("/admin/jobs")
+
+	jobGroup.GET("", h.List)
+	// :name 为任务名称，例如 daily_problem、trash_purge、problem_enrich
+	jobGroup.GET("/:name/runs", h.Runs)
+	jobGroup.POST("/:name/run", h.Trigger)
+}
+
+// List 所有后台任务、下次运行时间和最近一次运行
+func (h *JobHandler) List(c *gin.Context) {
+	jobs, err := h.runner.List(c.Request.Context())
+	if err != nil {
+		handleJobErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, jobs)
+}
+
+// Runs 任务的运行记录，limit 默认 20
+func (h *JobHandler) Runs(c *gin.Context) {
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		limit = n
+	}
+	runs, err := h.runner.Runs(c.Request.Context(), c.Param("name"), limit)
+	if err != nil {
+		handleJobErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, runs)
+}
+
+// Trigger 立即在后台运行任务，返回这次运行的记录
+func (h *JobHandler) Trigger(c *gin.Context) {
+	run, err := h.runner.Trigger(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		handleJobErr(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, run)
+}
+
+func handleJobErr(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUnknownJob):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrJobRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
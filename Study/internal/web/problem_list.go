@@ -0,0 +1,245 @@
+package web
+
+import (
+	"Training/Study/internal/pkg/graphql"
+	"Training/Study/internal/repository"
+	"Training/Study/internal/service"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProblemListHandler struct {
+	service service.ProblemListService
+}
+
+func NewProblemListHandler(service service.ProblemListService) *ProblemListHandler {
+	return &ProblemListHandler{
+		service: service,
+	}
+}
+
+func (h *ProblemListHandler) RegisterRoutes(server *gin.Engine) {
+	listGroup := server.Group("/api/coding/lists")
+
+	listGroup.GET("", h.List)
+	listGroup.POST("", h.Create)
+	// 从 LeetCode 导入学习计划或收藏夹，导入在后台进行，GET 查询导入状态
+	listGroup.POST("/import", h.Import)
+	listGroup.GET("/import", h.ImportStatus)
+	listGroup.GET("/:id", h.Detail)
+	listGroup.PUT("/:id", h.Update)
+	listGroup.DELETE("/:id", h.Delete)
+	listGroup.GET("/:id/progress", h.Progress)
+
+	// 题单中的题目，PUT 按给定顺序整体替换，POST 追加到末尾
+	listGroup.PUT("/:id/problems", h.SetProblems)
+	listGroup.POST("/:id/problems", h.AddProblems)
+	listGroup.DELETE("/:id/problems/:problemId", h.RemoveProblem)
+}
+
+type problemListRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type problemIdsRequest struct {
+	ProblemIds []int64 `json:"problem_ids"`
+}
+
+func (h *ProblemListHandler) List(c *gin.Context) {
+	lists, err := h.service.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"lists": lists,
+		"total": len(lists),
+	})
+}
+
+func (h *ProblemListHandler) Create(c *gin.Context) {
+	var req problemListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	list, err := h.service.Create(c.Request.Context(), req.Name, req.Description)
+	if err != nil {
+		handleProblemListErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// Import 在后台导入 LeetCode 题单，立即返回导入任务。type 为 study_plan 或 favorite，
+// slug 为题单链接中的标识，例如 https://leetcode.cn/studyplan/top-interview-150/ 的 slug 为 top-interview-150
+func (h *ProblemListHandler) Import(c *gin.Context) {
+	var req struct {
+		Type string `json:"type" binding:"required"`
+		Slug string `json:"slug" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	task, err := h.service.Import(c.Request.Context(), req.Type, req.Slug)
+	if err != nil {
+		handleProblemListErr(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, task)
+}
+
+// ImportStatus 查询题单最近一次导入的状态，参数与 Import 相同，通过 query 传入
+func (h *ProblemListHandler) ImportStatus(c *gin.Context) {
+	task, err := h.service.ImportStatus(c.Request.Context(), c.Query("type"), c.Query("slug"))
+	if err != nil {
+		handleProblemListErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, task)
+}
+
+func (h *ProblemListHandler) Detail(c *gin.Context) {
+	id, ok := parseListId(c)
+	if !ok {
+		return
+	}
+	detail, err := h.service.Detail(c.Request.Context(), id)
+	if err != nil {
+		handleProblemListErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+func (h *ProblemListHandler) Update(c *gin.Context) {
+	id, ok := parseListId(c)
+	if !ok {
+		return
+	}
+	var req problemListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.service.Update(c.Request.Context(), id, req.Name, req.Description); err != nil {
+		handleProblemListErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "success"})
+}
+
+func (h *ProblemListHandler) Delete(c *gin.Context) {
+	id, ok := parseListId(c)
+	if !ok {
+		return
+	}
+	if err := h.service.Delete(c.Request.Context(), id); err != nil {
+		handleProblemListErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "success"})
+}
+
+// Progress 题单进度，按学习状态和难度统计题目数
+func (h *ProblemListHandler) Progress(c *gin.Context) {
+	id, ok := parseListId(c)
+	if !ok {
+		return
+	}
+	progress, err := h.service.Progress(c.Request.Context(), id)
+	if err != nil {
+		handleProblemListErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, progress)
+}
+
+func (h *ProblemListHandler) SetProblems(c *gin.Context) {
+	id, ok := parseListId(c)
+	if !ok {
+		return
+	}
+	var req problemIdsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.service.SetProblems(c.Request.Context(), id, req.ProblemIds); err != nil {
+		handleProblemListErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "success"})
+}
+
+func (h *ProblemListHandler) AddProblems(c *gin.Context) {
+	id, ok := parseListId(c)
+	if !ok {
+		return
+	}
+	var req problemIdsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.service.AddProblems(c.Request.Context(), id, req.ProblemIds); err != nil {
+		handleProblemListErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "success"})
+}
+
+func (h *ProblemListHandler) RemoveProblem(c *gin.Context) {
+	id, ok := parseListId(c)
+	if !ok {
+		return
+	}
+	problemId, err := strconv.ParseInt(c.Param("problemId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid problem ID"})
+		return
+	}
+	if err := h.service.RemoveProblem(c.Request.Context(), id, problemId); err != nil {
+		handleProblemListErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "success"})
+}
+
+func parseListId(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid list ID"})
+		return 0, false
+	}
+	return id, true
+}
+
+func handleProblemListErr(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidProblemList), errors.Is(err, service.ErrBuiltInProblemList),
+		errors.Is(err, service.ErrInvalidListImport):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrProblemListExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "Problem list already exists"})
+	case errors.Is(err, service.ErrListImportRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrProblemListNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Problem list or problem not found"})
+	case errors.Is(err, service.ErrListImportNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, graphql.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, graphql.ErrRateLimited):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case errors.Is(err, graphql.ErrAuthRequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,57 @@
+package web
+
+import (
+	"Training/Study/internal/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProblemSyncHandler struct {
+	service service.ProblemSyncService
+}
+
+func NewProblemSyncHandler(service service.ProblemSyncService) *ProblemSyncHandler {
+	return &ProblemSyncHandler{
+		service: service,
+	}
+}
+
+func (h *ProblemSyncHandler) RegisterRoutes(server *gin.Engine) {
+	syncGroup := server.Group("/api/coding/sync")
+
+	// :source 为题目来源，例如 leetcode、leetcode_us
+	syncGroup.POST("/:source", h.Start)
+	syncGroup.GET("/:source", h.Progress)
+}
+
+// Start 在后台同步整个题库的元数据，立即返回当前进度
+func (h *ProblemSyncHandler) Start(c *gin.Context) {
+	progress, err := h.service.Start(c.Request.Context(), c.Param("source"))
+	if err != nil {
+		handleProblemSyncErr(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, progress)
+}
+
+func (h *ProblemSyncHandler) Progress(c *gin.Context) {
+	progress, err := h.service.Progress(c.Request.Context(), c.Param("source"))
+	if err != nil {
+		handleProblemSyncErr(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, progress)
+}
+
+func handleProblemSyncErr(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUnknownProblemSource):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSyncRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
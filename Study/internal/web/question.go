@@ -2,8 +2,12 @@ package web
 
 import (
 	"Training/Study/internal/domain"
+	"Training/Study/internal/repository"
 	"Training/Study/internal/service"
+	"errors"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,13 +24,28 @@ func NewQuestHandler(svc service.QuestService) *QuestHandler {
 
 func (q *QuestHandler) RegisterRoutes(server *gin.Engine) {
 	g := server.Group("/question")
-	g.GET("/", q.FindAll)
+	g.GET("/", q.List)
 	g.GET("/categories", q.FindAllCategories)
 	g.GET("/mastery-stats", q.GetMasteryStats)
+	g.GET("/due", q.FindDue)
+	g.GET("/activity", q.Activity)
+	g.GET("/search", q.Search)
+	g.GET("/duplicates", q.Duplicates)
 	g.GET("/:category", q.FindByCategory)
+	// gin 要求同一层级的通配符同名，这里的 :category 实际是题目ID
+	g.GET("/:category/history", q.History)
+	g.GET("/:category/revisions", q.Revisions)
+	g.GET("/:category/revisions/diff", q.RevisionDiff)
 	g.POST("/", q.Insert)
+	g.POST("/import", q.Import)
+	g.POST("/categories/rename", q.RenameCategory)
+	g.POST("/categories/merge", q.MergeCategory)
+	g.POST("/duplicates/merge", q.Merge)
+	g.POST("/:id/review", q.Review)
+	g.POST("/:id/revisions/:rev/restore", q.Restore)
 	g.PUT("/:id", q.UpdateById)
 	g.PUT("/:id/mastery", q.UpdateMasteryLevel)
+	g.DELETE("/categories", q.DeleteCategory)
 	g.DELETE("/:id", q.DeleteById)
 }
 
@@ -35,30 +54,74 @@ func (q *QuestHandler) Insert(ctx *gin.Context) {
 		Content  string `json:"content" binding:"required"`
 		Answer   string `json:"answer" binding:"required"`
 		Category string `json:"category" binding:"required"`
+		// Force 存在高度相似的题目时仍然创建
+		Force bool `json:"force"`
 	}
 	var req Request
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
-	if err := q.svc.Insert(ctx, domain.Question{
+	similar, err := q.svc.Insert(ctx, domain.Question{
 		Content:  req.Content,
 		Answer:   req.Answer,
 		Category: req.Category,
-	}); err != nil {
+	}, req.Force)
+	switch {
+	case errors.Is(err, service.ErrDuplicateQuestion):
+		ctx.JSON(409, gin.H{"error": err.Error(), "similar": similar})
+		return
+	case err != nil:
 		ctx.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
-	ctx.JSON(200, gin.H{"message": "success"})
+	ctx.JSON(200, gin.H{"message": "success", "similar": similar})
 }
 
-func (q *QuestHandler) FindAll(ctx *gin.Context) {
-	questions, err := q.svc.FindAll(ctx)
-	if err != nil {
+// List 分页查询题目。查询参数：category、mastery(0-2)、updated_since(毫秒时间戳)、q(关键词)、
+// sort(id, ctime, utime, mastery_level, next_review_at, review_count)、order(asc/desc)、cursor、limit
+func (q *QuestHandler) List(ctx *gin.Context) {
+	query := domain.QuestionQuery{
+		Category: ctx.Query("category"),
+		Keyword:  strings.TrimSpace(ctx.Query("q")),
+		SortBy:   ctx.Query("sort"),
+	}
+	if mastery := ctx.Query("mastery"); mastery != "" {
+		level, err := strconv.Atoi(mastery)
+		if err != nil || level < domain.MasteryUnlearned || level > domain.MasteryMastered {
+			ctx.JSON(400, gin.H{"error": "invalid mastery"})
+			return
+		}
+		query.MasteryLevel = &level
+	}
+	if since := ctx.Query("updated_since"); since != "" {
+		ms, err := strconv.ParseInt(since, 10, 64)
+		if err != nil {
+			ctx.JSON(400, gin.H{"error": "invalid updated_since"})
+			return
+		}
+		query.UpdatedSince = time.UnixMilli(ms)
+	}
+	switch ctx.DefaultQuery("order", "asc") {
+	case "asc":
+	case "desc":
+		query.Desc = true
+	default:
+		ctx.JSON(400, gin.H{"error": "order must be asc or desc"})
+		return
+	}
+	query.Limit, _ = strconv.Atoi(ctx.Query("limit"))
+
+	page, err := q.svc.List(ctx, query, ctx.Query("cursor"))
+	switch {
+	case errors.Is(err, service.ErrInvalidSort), errors.Is(err, service.ErrInvalidCursor):
+		ctx.JSON(400, gin.H{"error": err.Error()})
+		return
+	case err != nil:
 		ctx.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
-	ctx.JSON(200, questions)
+	ctx.JSON(200, page)
 }
 
 func (q *QuestHandler) FindByCategory(ctx *gin.Context) {
@@ -87,18 +150,25 @@ func (q *QuestHandler) UpdateById(ctx *gin.Context) {
 		Content  string `json:"content" binding:"required"`
 		Answer   string `json:"answer" binding:"required"`
 		Category string `json:"category" binding:"required"`
+		// Author 修改人，记录在修订历史中
+		Author string `json:"author"`
 	}
 	var bodyReq BodyRequest
 	if err := ctx.ShouldBindJSON(&bodyReq); err != nil {
 		ctx.JSON(400, gin.H{"error": err.Error()})
 		return
 	}
-	if err := q.svc.UpdateById(ctx, domain.Question{
+	err = q.svc.UpdateById(ctx, domain.Question{
 		Id:       id,
 		Category: bodyReq.Category,
 		Content:  bodyReq.Content,
 		Answer:   bodyReq.Answer,
-	}); err != nil {
+	}, bodyReq.Author)
+	switch {
+	case errors.Is(err, repository.ErrQuestionNotFound):
+		ctx.JSON(404, gin.H{"error": "question not found"})
+		return
+	case err != nil:
 		ctx.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
@@ -129,6 +199,98 @@ func (q *QuestHandler) UpdateMasteryLevel(ctx *gin.Context) {
 	ctx.JSON(200, gin.H{"message": "success"})
 }
 
+func (q *QuestHandler) FindDue(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+	questions, err := q.svc.FindDue(ctx, ctx.Query("category"), limit)
+	if err != nil {
+		ctx.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(200, questions)
+}
+
+func (q *QuestHandler) Review(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		ctx.JSON(400, gin.H{"error": "invalid id"})
+		return
+	}
+
+	type Request struct {
+		Grade      *int  `json:"grade" binding:"required,min=0,max=5"`
+		DurationMs int64 `json:"duration_ms" binding:"min=0"`
+	}
+	var req Request
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	quest, err := q.svc.Review(ctx, id, *req.Grade, time.Duration(req.DurationMs)*time.Millisecond)
+	switch {
+	case errors.Is(err, repository.ErrQuestionNotFound):
+		ctx.JSON(404, gin.H{"error": "question not found"})
+		return
+	case errors.Is(err, service.ErrInvalidGrade):
+		ctx.JSON(400, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		ctx.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(200, quest)
+}
+
+func (q *QuestHandler) Search(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+	results, err := q.svc.Search(ctx, ctx.Query("q"), limit)
+	if errors.Is(err, service.ErrEmptyKeyword) {
+		ctx.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(200, results)
+}
+
+func (q *QuestHandler) History(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("category"), 10, 64)
+	if err != nil {
+		ctx.JSON(400, gin.H{"error": "invalid id"})
+		return
+	}
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+	reviews, err := q.svc.History(ctx, id, limit)
+	if err != nil {
+		ctx.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(200, reviews)
+}
+
+// Activity 全局复习动态，before 为毫秒时间戳，用于向前翻页
+func (q *QuestHandler) Activity(ctx *gin.Context) {
+	var before time.Time
+	if beforeStr := ctx.Query("before"); beforeStr != "" {
+		ms, err := strconv.ParseInt(beforeStr, 10, 64)
+		if err != nil {
+			ctx.JSON(400, gin.H{"error": "invalid before"})
+			return
+		}
+		before = time.UnixMilli(ms)
+	}
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+	activities, err := q.svc.Activity(ctx, before, limit)
+	if err != nil {
+		ctx.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(200, activities)
+}
+
 func (q *QuestHandler) DeleteById(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
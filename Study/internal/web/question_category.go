@@ -0,0 +1,79 @@
+package web
+
+import (
+	"Training/Study/internal/service"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 分类名可能包含表示层级的 /，因此分类名都放在请求体或查询参数中，而不是路径里
+
+func (q *QuestHandler) RenameCategory(ctx *gin.Context) {
+	type Request struct {
+		From string `json:"from" binding:"required"`
+		To   string `json:"to" binding:"required"`
+	}
+	var req Request
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	affected, err := q.svc.RenameCategory(ctx, req.From, req.To)
+	if err != nil {
+		q.handleCategoryErr(ctx, err)
+		return
+	}
+	ctx.JSON(200, gin.H{"message": "success", "affected": affected})
+}
+
+func (q *QuestHandler) MergeCategory(ctx *gin.Context) {
+	type Request struct {
+		From string `json:"from" binding:"required"`
+		Into string `json:"into" binding:"required"`
+	}
+	var req Request
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	affected, err := q.svc.MergeCategory(ctx, req.From, req.Into)
+	if err != nil {
+		q.handleCategoryErr(ctx, err)
+		return
+	}
+	ctx.JSON(200, gin.H{"message": "success", "affected": affected})
+}
+
+// DeleteCategory 删除分类及其子分类下的全部题目。
+// 未带 confirm 或 confirm 与分类名不一致时不会删除，返回 400 和将被删除的题目数
+func (q *QuestHandler) DeleteCategory(ctx *gin.Context) {
+	name := ctx.Query("name")
+	if name == "" {
+		ctx.JSON(400, gin.H{"error": "name is required"})
+		return
+	}
+	affected, err := q.svc.DeleteByCategory(ctx, name, ctx.Query("confirm"))
+	if errors.Is(err, service.ErrDeleteNotConfirmed) {
+		ctx.JSON(400, gin.H{"error": err.Error(), "affected": affected})
+		return
+	}
+	if err != nil {
+		q.handleCategoryErr(ctx, err)
+		return
+	}
+	ctx.JSON(200, gin.H{"message": "success", "affected": affected})
+}
+
+func (q *QuestHandler) handleCategoryErr(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCategory):
+		ctx.JSON(400, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrCategoryNotFound):
+		ctx.JSON(404, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrCategoryExists):
+		ctx.JSON(409, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(500, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,48 @@
+package web
+
+import (
+	"Training/Study/internal/service"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QuestExportHandler struct {
+	svc service.QuestExportService
+}
+
+func NewQuestExportHandler(svc service.QuestExportService) *QuestExportHandler {
+	return &QuestExportHandler{
+		svc: svc,
+	}
+}
+
+func (h *QuestExportHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/question/export")
+	g.GET("/anki", h.ExportAnki)
+	g.GET("/markdown", h.ExportMarkdown)
+}
+
+func (h *QuestExportHandler) ExportAnki(ctx *gin.Context) {
+	data, err := h.svc.ExportAnki(ctx, ctx.Query("category"))
+	if err != nil {
+		ctx.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Header("Content-Disposition", `attachment; filename="questions-anki.txt"`)
+	ctx.Data(200, "text/tab-separated-values; charset=utf-8", data)
+}
+
+// ExportMarkdown 查询参数 category 可以重复传入多个；unmastered=true 只导出未掌握的题目
+func (h *QuestExportHandler) ExportMarkdown(ctx *gin.Context) {
+	onlyUnmastered, _ := strconv.ParseBool(ctx.Query("unmastered"))
+	file, err := h.svc.ExportMarkdown(ctx, ctx.QueryArray("category"), onlyUnmastered)
+	if err != nil {
+		ctx.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename*=UTF-8''%s", url.PathEscape(file.Name)))
+	ctx.Data(200, file.ContentType, file.Data)
+}
@@ -0,0 +1,149 @@
+package web
+
+import (
+	"Training/Study/internal/domain"
+	"Training/Study/internal/service"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportBody 导入请求体大小上限
+const maxImportBody = 20 << 20
+
+// importItem 与 Study-fe/example_questions.json 中的题目格式一致
+type importItem struct {
+	Content  string `json:"content"`
+	Answer   string `json:"answer"`
+	Category string `json:"category"`
+}
+
+// Import 批量导入题目，支持 JSON 数组和带表头的 CSV，既可以直接作为请求体，
+// 也可以通过 multipart 的 file 字段上传。
+// 查询参数：dry_run=true 只校验不写入；on_duplicate=update 覆盖重复题目，默认跳过；
+// force=true 时与已有题目高度相似的题目也会导入
+func (q *QuestHandler) Import(ctx *gin.Context) {
+	dryRun, _ := strconv.ParseBool(ctx.Query("dry_run"))
+	force, _ := strconv.ParseBool(ctx.Query("force"))
+	onDuplicate := ctx.DefaultQuery("on_duplicate", "skip")
+	if onDuplicate != "skip" && onDuplicate != "update" {
+		ctx.JSON(400, gin.H{"error": "on_duplicate must be skip or update"})
+		return
+	}
+
+	items, err := q.readImportItems(ctx)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		ctx.JSON(413, gin.H{"error": fmt.Sprintf("导入内容超过 %d MB 上限，请拆分后分批导入", maxImportBody>>20)})
+		return
+	}
+	if err != nil {
+		ctx.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	quests := make([]domain.Question, 0, len(items))
+	for _, item := range items {
+		quests = append(quests, domain.Question{
+			Content:  item.Content,
+			Answer:   item.Answer,
+			Category: item.Category,
+		})
+	}
+	report, err := q.svc.Import(ctx, quests, service.ImportOptions{
+		DryRun:    dryRun,
+		Overwrite: onDuplicate == "update",
+		Force:     force,
+	})
+	if errors.Is(err, service.ErrEmptyImport) || errors.Is(err, service.ErrImportTooMany) {
+		ctx.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(200, report)
+}
+
+func (q *QuestHandler) readImportItems(ctx *gin.Context) ([]importItem, error) {
+	// 超过上限时读取返回 *http.MaxBytesError，而不是截断后当作完整内容解析
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportBody)
+
+	format := ctx.Query("format")
+	var body io.Reader = ctx.Request.Body
+	if strings.HasPrefix(ctx.ContentType(), "multipart/") {
+		header, err := ctx.FormFile("file")
+		if err != nil {
+			return nil, fmt.Errorf("读取上传文件失败: %w", err)
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, fmt.Errorf("读取上传文件失败: %w", err)
+		}
+		defer file.Close()
+		body = file
+		if format == "" && strings.EqualFold(filepath.Ext(header.Filename), ".csv") {
+			format = "csv"
+		}
+	} else if format == "" && ctx.ContentType() == "text/csv" {
+		format = "csv"
+	}
+
+	if format == "csv" {
+		return parseImportCSV(body)
+	}
+	var items []importItem
+	if err := json.NewDecoder(body).Decode(&items); err != nil {
+		return nil, fmt.Errorf("JSON格式错误: %w", err)
+	}
+	return items, nil
+}
+
+// parseImportCSV 解析 CSV，第一行为表头，需要包含 content、answer、category 三列，顺序不限
+func parseImportCSV(r io.Reader) ([]importItem, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("CSV格式错误: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, name := range []string{"content", "answer", "category"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV缺少 %s 列", name)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if idx := columns[name]; idx < len(record) {
+			return record[idx]
+		}
+		return ""
+	}
+	items := make([]importItem, 0, len(records)-1)
+	for _, record := range records[1:] {
+		items = append(items, importItem{
+			Content:  field(record, "content"),
+			Answer:   field(record, "answer"),
+			Category: field(record, "category"),
+		})
+	}
+	return items, nil
+}
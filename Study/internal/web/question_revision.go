@@ -0,0 +1,103 @@
+package web
+
+import (
+	"Training/Study/internal/repository"
+	"Training/Study/internal/service"
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Revisions 题目的修订历史，路由参数 :category 实际是题目ID
+func (q *QuestHandler) Revisions(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("category"), 10, 64)
+	if err != nil {
+		ctx.JSON(400, gin.H{"error": "invalid id"})
+		return
+	}
+	revisions, err := q.svc.Revisions(ctx, id)
+	if err != nil {
+		q.handleRevisionErr(ctx, err)
+		return
+	}
+	ctx.JSON(200, revisions)
+}
+
+// RevisionDiff 对比两个版本，默认对比最新版本和上一个版本
+func (q *QuestHandler) RevisionDiff(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("category"), 10, 64)
+	if err != nil {
+		ctx.JSON(400, gin.H{"error": "invalid id"})
+		return
+	}
+	from, err := queryRev(ctx, "from")
+	if err != nil {
+		ctx.JSON(400, gin.H{"error": "invalid from"})
+		return
+	}
+	to, err := queryRev(ctx, "to")
+	if err != nil {
+		ctx.JSON(400, gin.H{"error": "invalid to"})
+		return
+	}
+	diff, err := q.svc.RevisionDiff(ctx, id, from, to)
+	if err != nil {
+		q.handleRevisionErr(ctx, err)
+		return
+	}
+	ctx.JSON(200, diff)
+}
+
+func (q *QuestHandler) Restore(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(400, gin.H{"error": "invalid id"})
+		return
+	}
+	rev, err := strconv.Atoi(ctx.Param("rev"))
+	if err != nil || rev <= 0 {
+		ctx.JSON(400, gin.H{"error": "invalid rev"})
+		return
+	}
+	type Request struct {
+		Author string `json:"author"`
+	}
+	var req Request
+	// 请求体可以为空
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	quest, err := q.svc.Restore(ctx, id, rev, req.Author)
+	if err != nil {
+		q.handleRevisionErr(ctx, err)
+		return
+	}
+	ctx.JSON(200, quest)
+}
+
+func (q *QuestHandler) handleRevisionErr(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRevisionNotFound):
+		ctx.JSON(404, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrQuestionNotFound):
+		ctx.JSON(404, gin.H{"error": "question not found"})
+	default:
+		ctx.JSON(500, gin.H{"error": err.Error()})
+	}
+}
+
+func queryRev(ctx *gin.Context, key string) (int, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+	rev, err := strconv.Atoi(value)
+	if err == nil && rev < 0 {
+		err = strconv.ErrRange
+	}
+	return rev, err
+}
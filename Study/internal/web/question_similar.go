@@ -0,0 +1,47 @@
+package web
+
+import (
+	"Training/Study/internal/repository"
+	"Training/Study/internal/service"
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Duplicates 疑似重复的题目分组，similarity 为最低相似度(0-1)，默认 0.5
+func (q *QuestHandler) Duplicates(ctx *gin.Context) {
+	similarity, _ := strconv.ParseFloat(ctx.Query("similarity"), 64)
+	clusters, err := q.svc.Duplicates(ctx, similarity)
+	if err != nil {
+		ctx.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(200, clusters)
+}
+
+// Merge 合并重复题目，保留 keep_id，merge_ids 中的题目合并后删除
+func (q *QuestHandler) Merge(ctx *gin.Context) {
+	type Request struct {
+		KeepId   int64   `json:"keep_id" binding:"required"`
+		MergeIds []int64 `json:"merge_ids" binding:"required"`
+	}
+	var req Request
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	quest, err := q.svc.Merge(ctx, req.KeepId, req.MergeIds)
+	switch {
+	case errors.Is(err, service.ErrInvalidMerge):
+		ctx.JSON(400, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, repository.ErrQuestionNotFound):
+		ctx.JSON(404, gin.H{"error": "question not found"})
+		return
+	case err != nil:
+		ctx.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(200, quest)
+}
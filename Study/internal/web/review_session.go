@@ -0,0 +1,167 @@
+package web
+
+import (
+	"Training/Study/internal/repository"
+	"Training/Study/internal/service"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReviewSessionHandler struct {
+	svc service.ReviewSessionService
+}
+
+func NewReviewSessionHandler(svc service.ReviewSessionService) *ReviewSessionHandler {
+	return &ReviewSessionHandler{
+		svc: svc,
+	}
+}
+
+func (h *ReviewSessionHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/question/sessions")
+	g.POST("", h.Start)
+	g.GET("/active", h.Active)
+	g.GET("/:id", h.Get)
+	g.GET("/:id/next", h.Next)
+	g.POST("/:id/answers", h.Submit)
+	g.POST("/:id/finish", h.Finish)
+	g.GET("/:id/summary", h.Summary)
+}
+
+func (h *ReviewSessionHandler) Start(ctx *gin.Context) {
+	type Request struct {
+		Category string `json:"category"`
+		Size     int    `json:"size" binding:"min=0"`
+	}
+	var req Request
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	session, err := h.svc.Start(ctx, req.Category, req.Size)
+	if errors.Is(err, service.ErrSessionEmpty) {
+		ctx.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(200, session)
+}
+
+func (h *ReviewSessionHandler) Active(ctx *gin.Context) {
+	session, err := h.svc.Active(ctx)
+	if errors.Is(err, repository.ErrReviewSessionNotFound) {
+		ctx.JSON(404, gin.H{"error": "no active session"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(200, session)
+}
+
+func (h *ReviewSessionHandler) Get(ctx *gin.Context) {
+	id, ok := h.sessionId(ctx)
+	if !ok {
+		return
+	}
+	session, err := h.svc.Get(ctx, id)
+	if !h.handleErr(ctx, err) {
+		return
+	}
+	ctx.JSON(200, session)
+}
+
+func (h *ReviewSessionHandler) Next(ctx *gin.Context) {
+	id, ok := h.sessionId(ctx)
+	if !ok {
+		return
+	}
+	next, err := h.svc.Next(ctx, id)
+	if !h.handleErr(ctx, err) {
+		return
+	}
+	ctx.JSON(200, next)
+}
+
+func (h *ReviewSessionHandler) Submit(ctx *gin.Context) {
+	id, ok := h.sessionId(ctx)
+	if !ok {
+		return
+	}
+
+	type Request struct {
+		QuestionId int64 `json:"question_id"`
+		Grade      *int  `json:"grade" binding:"required,min=0,max=5"`
+		DurationMs int64 `json:"duration_ms" binding:"min=0"`
+	}
+	var req Request
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	next, err := h.svc.Submit(ctx, id, req.QuestionId, *req.Grade, time.Duration(req.DurationMs)*time.Millisecond)
+	if !h.handleErr(ctx, err) {
+		return
+	}
+	ctx.JSON(200, next)
+}
+
+func (h *ReviewSessionHandler) Finish(ctx *gin.Context) {
+	id, ok := h.sessionId(ctx)
+	if !ok {
+		return
+	}
+	summary, err := h.svc.Finish(ctx, id)
+	if !h.handleErr(ctx, err) {
+		return
+	}
+	ctx.JSON(200, summary)
+}
+
+func (h *ReviewSessionHandler) Summary(ctx *gin.Context) {
+	id, ok := h.sessionId(ctx)
+	if !ok {
+		return
+	}
+	summary, err := h.svc.Summary(ctx, id)
+	if !h.handleErr(ctx, err) {
+		return
+	}
+	ctx.JSON(200, summary)
+}
+
+func (h *ReviewSessionHandler) sessionId(ctx *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(400, gin.H{"error": "invalid id"})
+		return 0, false
+	}
+	return id, true
+}
+
+// handleErr 将业务错误转换为响应，返回 true 表示没有错误
+func (h *ReviewSessionHandler) handleErr(ctx *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, repository.ErrReviewSessionNotFound):
+		ctx.JSON(404, gin.H{"error": "session not found"})
+	case errors.Is(err, service.ErrSessionFinished),
+		errors.Is(err, service.ErrQuestionMismatch),
+		errors.Is(err, service.ErrSessionQuestionGone):
+		ctx.JSON(409, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidGrade):
+		ctx.JSON(400, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(500, gin.H{"error": err.Error()})
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package web
+
+import (
+	"Training/Study/internal/repository"
+	"Training/Study/internal/service"
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagHandler struct {
+	svc service.TagService
+}
+
+func NewTagHandler(svc service.TagService) *TagHandler {
+	return &TagHandler{
+		svc: svc,
+	}
+}
+
+func (h *TagHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/question")
+	g.GET("/tags", h.Tree)
+	g.POST("/tags", h.Create)
+	g.PUT("/:id/tags", h.SetQuestionTags)
+}
+
+func (h *TagHandler) Tree(ctx *gin.Context) {
+	tree, err := h.svc.Tree(ctx)
+	if err != nil {
+		ctx.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(200, tree)
+}
+
+// Create 创建标签，path 使用 / 表示层级，例如 网络/TCP
+func (h *TagHandler) Create(ctx *gin.Context) {
+	type Request struct {
+		Path string `json:"path" binding:"required"`
+	}
+	var req Request
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	tag, err := h.svc.Create(ctx, req.Path)
+	if errors.Is(err, repository.ErrInvalidTagPath) {
+		ctx.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(200, tag)
+}
+
+func (h *TagHandler) SetQuestionTags(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(400, gin.H{"error": "invalid id"})
+		return
+	}
+	type Request struct {
+		Tags []string `json:"tags"`
+	}
+	var req Request
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	err = h.svc.SetQuestionTags(ctx, id, req.Tags)
+	switch {
+	case errors.Is(err, repository.ErrQuestionNotFound):
+		ctx.JSON(404, gin.H{"error": "question not found"})
+		return
+	case err != nil:
+		ctx.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(200, gin.H{"message": "success"})
+}
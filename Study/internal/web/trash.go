@@ -0,0 +1,62 @@
+package web
+
+import (
+	"Training/Study/internal/repository"
+	"Training/Study/internal/service"
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TrashHandler struct {
+	svc service.TrashService
+}
+
+func NewTrashHandler(svc service.TrashService) *TrashHandler {
+	return &TrashHandler{
+		svc: svc,
+	}
+}
+
+func (h *TrashHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/trash")
+	g.GET("", h.List)
+	// :type 为 question 或 coding_problem
+	g.POST("/:type/:id/restore", h.Restore)
+}
+
+// List 回收站列表，可以用 type 参数只看八股题(question)或刷题(coding_problem)
+func (h *TrashHandler) List(ctx *gin.Context) {
+	items, err := h.svc.List(ctx, ctx.Query("type"))
+	if errors.Is(err, service.ErrUnknownTrashType) {
+		ctx.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(200, items)
+}
+
+func (h *TrashHandler) Restore(ctx *gin.Context) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(400, gin.H{"error": "invalid id"})
+		return
+	}
+	err = h.svc.Restore(ctx, ctx.Param("type"), id)
+	switch {
+	case errors.Is(err, service.ErrUnknownTrashType):
+		ctx.JSON(400, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, repository.ErrQuestionNotFound):
+		ctx.JSON(404, gin.H{"error": "not found in trash"})
+		return
+	case err != nil:
+		ctx.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(200, gin.H{"message": "success"})
+}
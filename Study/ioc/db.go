@@ -18,9 +18,20 @@ import (
 type Application struct {
 	DB                   *gorm.DB
 	QuestHandler         *web.QuestHandler
+	ReviewSessionHandler *web.ReviewSessionHandler
+	QuestExportHandler   *web.QuestExportHandler
+	TagHandler           *web.TagHandler
+	TrashHandler         *web.TrashHandler
 	CodingProblemHandler *web.CodingProblemHandler
+	CodingAttemptHandler *web.CodingAttemptHandler
 	Crawler              *service.LeetCodeCrawler
+	ProblemListHandler   *web.ProblemListHandler
+	ProblemSyncHandler   *web.ProblemSyncHandler
 	CodingProblemRepo    repository.CodingProblemRepository
+	ProblemListRepo      repository.ProblemListRepository
+	JobHandler           *web.JobHandler
+	ProblemSyncService   service.ProblemSyncService
+	JobRunner            service.JobRunner
 }
 
 func InitDB() *gorm.DB {
@@ -68,26 +79,69 @@ func InitWebServer() *gin.Engine {
 func InitApplication(db *gorm.DB) *Application {
 	// 初始化DAO
 	questDAO := dao.NewQuestionDao(db)
+	reviewSessionDAO := dao.NewReviewSessionDao(db)
+	tagDAO := dao.NewTagDao(db)
 	codingProblemDAO := dao.NewGormCodingProblemDAO(db)
+	codingAttemptDAO := dao.NewCodingAttemptDao(db)
+	problemListDAO := dao.NewProblemListDao(db)
+	problemSyncDAO := dao.NewProblemSyncDao(db)
+	problemEnrichmentDAO := dao.NewProblemEnrichmentDao(db)
+	jobRunDAO := dao.NewJobRunDao(db)
 
 	// 初始化Repository
-	questRepo := repository.NewQuestRepository(questDAO)
+	questRepo := repository.NewQuestRepository(questDAO, tagDAO)
+	reviewSessionRepo := repository.NewReviewSessionRepository(reviewSessionDAO)
+	tagRepo := repository.NewTagRepository(tagDAO)
 	codingProblemRepo := repository.NewCachedCodingProblemRepository(codingProblemDAO)
+	codingAttemptRepo := repository.NewCodingAttemptRepository(codingAttemptDAO)
+	problemListRepo := repository.NewProblemListRepository(problemListDAO)
+	problemSyncRepo := repository.NewProblemSyncRepository(problemSyncDAO)
+	problemEnrichmentRepo := repository.NewProblemEnrichmentRepository(problemEnrichmentDAO)
+	jobRunRepo := repository.NewJobRunRepository(jobRunDAO)
 
 	// 初始化Service
-	leetcodeCrawler := service.NewLeetCodeCrawler(codingProblemRepo)
+	problemSources := service.NewDefaultProblemSources()
+	leetcodeCrawler := service.NewLeetCodeCrawler(problemSources, codingProblemRepo, problemListRepo)
 	questService := service.NewQuestService(questRepo)
-	codingProblemService := service.NewCodingProblemService(codingProblemRepo, leetcodeCrawler)
+	reviewSessionService := service.NewReviewSessionService(reviewSessionRepo, questRepo, questService)
+	questExportService := service.NewQuestExportService(questRepo)
+	tagService := service.NewTagService(tagRepo, questRepo, questService)
+	codingProblemService := service.NewCodingProblemService(codingProblemRepo, problemSources, leetcodeCrawler)
+	codingAttemptService := service.NewCodingAttemptService(codingAttemptRepo)
+	problemListService := service.NewProblemListService(problemListRepo, leetcodeCrawler)
+	problemSyncService := service.NewProblemSyncService(problemSyncRepo, codingProblemRepo, problemSources)
+	problemEnrichService := service.NewProblemEnrichService(problemEnrichmentRepo, codingProblemRepo, problemSources)
+	trashService := service.NewTrashService(questRepo, codingProblemRepo, questService)
+	jobRunner := service.NewJobRunner(jobRunRepo, leetcodeCrawler, trashService, problemEnrichService)
 
 	// 初始化Handler
 	questHandler := web.NewQuestHandler(questService)
+	reviewSessionHandler := web.NewReviewSessionHandler(reviewSessionService)
+	questExportHandler := web.NewQuestExportHandler(questExportService)
+	tagHandler := web.NewTagHandler(tagService)
 	codingProblemHandler := web.NewCodingProblemHandler(codingProblemService)
+	codingAttemptHandler := web.NewCodingAttemptHandler(codingAttemptService)
+	problemListHandler := web.NewProblemListHandler(problemListService)
+	problemSyncHandler := web.NewProblemSyncHandler(problemSyncService)
+	trashHandler := web.NewTrashHandler(trashService)
+	jobHandler := web.NewJobHandler(jobRunner)
 
 	return &Application{
 		DB:                   db,
 		QuestHandler:         questHandler,
+		ReviewSessionHandler: reviewSessionHandler,
+		QuestExportHandler:   questExportHandler,
+		TagHandler:           tagHandler,
+		TrashHandler:         trashHandler,
 		CodingProblemHandler: codingProblemHandler,
+		CodingAttemptHandler: codingAttemptHandler,
 		Crawler:              leetcodeCrawler,
+		ProblemListHandler:   problemListHandler,
+		ProblemSyncHandler:   problemSyncHandler,
 		CodingProblemRepo:    codingProblemRepo,
+		ProblemListRepo:      problemListRepo,
+		JobHandler:           jobHandler,
+		ProblemSyncService:   problemSyncService,
+		JobRunner:            jobRunner,
 	}
 }
@@ -53,22 +53,18 @@ func initializeData(app *ioc.Application) {
 	log.Printf("🔥 开始插入Hot100题目数据...")
 	config.InsertHot100Problems(ctx, app)
 
-	// 2. 获取每日一题（启动时爬取一次）
-	log.Printf("📅 开始获取每日一题...")
-	err := app.Crawler.CrawlAndSaveDailyProblem(ctx)
-	if err != nil {
-		log.Printf("❌ 获取每日一题失败: %v", err)
-	} else {
-		log.Printf("✅ 每日一题获取完成")
-	}
-
-	// 3. 启动定时任务（每天0点获取新的每日一题）
-	log.Printf("⏰ 启动定时任务...")
+	// 2. 启动后台任务：每日一题、回收站清理、题目补全，错过的定时运行会在启动时补跑
+	log.Printf("⏰ 启动后台任务...")
 	go func() {
-		if err := app.Crawler.StartDailyCrawler(ctx); err != nil {
-			log.Printf("❌ 定时爬虫停止: %v", err)
+		if err := app.JobRunner.Start(ctx); err != nil {
+			log.Printf("❌ 后台任务停止: %v", err)
 		}
 	}()
+
+	// 3. 继续重启前没有完成的题库同步
+	if err := app.ProblemSyncService.Resume(ctx); err != nil {
+		log.Printf("❌ 继续题库同步失败: %v", err)
+	}
 }
 
 // 启动web服务器
@@ -80,7 +76,15 @@ func startWebServer(app *ioc.Application) {
 
 	// 注册路由
 	app.QuestHandler.RegisterRoutes(server)
+	app.ReviewSessionHandler.RegisterRoutes(server)
+	app.QuestExportHandler.RegisterRoutes(server)
+	app.TagHandler.RegisterRoutes(server)
 	app.CodingProblemHandler.RegisterRoutes(server)
+	app.CodingAttemptHandler.RegisterRoutes(server)
+	app.ProblemListHandler.RegisterRoutes(server)
+	app.ProblemSyncHandler.RegisterRoutes(server)
+	app.TrashHandler.RegisterRoutes(server)
+	app.JobHandler.RegisterRoutes(server)
 
 	// 启动服务器
 	port := ":8080"
@@ -22,20 +22,52 @@ func InitWebServer() *gin.Engine {
 
 		// DAO层
 		dao.NewQuestionDao,
+		dao.NewReviewSessionDao,
+		dao.NewTagDao,
 		dao.NewGormCodingProblemDAO,
+		dao.NewCodingAttemptDao,
+		dao.NewProblemListDao,
+		dao.NewProblemSyncDao,
+		dao.NewProblemEnrichmentDao,
+		dao.NewJobRunDao,
 
 		// Repository层
 		repository.NewQuestRepository,
+		repository.NewReviewSessionRepository,
+		repository.NewTagRepository,
 		repository.NewCachedCodingProblemRepository,
+		repository.NewCodingAttemptRepository,
+		repository.NewProblemListRepository,
+		repository.NewProblemSyncRepository,
+		repository.NewProblemEnrichmentRepository,
+		repository.NewJobRunRepository,
 
 		// Service层
 		service.NewQuestService,
+		service.NewReviewSessionService,
+		service.NewQuestExportService,
+		service.NewTagService,
+		service.NewDefaultProblemSources,
 		service.NewLeetCodeCrawler,
 		service.NewCodingProblemService,
+		service.NewCodingAttemptService,
+		service.NewProblemListService,
+		service.NewProblemSyncService,
+		service.NewProblemEnrichService,
+		service.NewJobRunner,
+		service.NewTrashService,
 
 		// Handler层
 		web.NewQuestHandler,
+		web.NewReviewSessionHandler,
+		web.NewQuestExportHandler,
+		web.NewTagHandler,
 		web.NewCodingProblemHandler,
+		web.NewCodingAttemptHandler,
+		web.NewProblemListHandler,
+		web.NewProblemSyncHandler,
+		web.NewTrashHandler,
+		web.NewJobHandler,
 
 		// Web服务器
 		InitGinServer,
@@ -45,7 +77,15 @@ func InitWebServer() *gin.Engine {
 
 func InitGinServer(
 	questionHandler *web.QuestHandler,
+	reviewSessionHandler *web.ReviewSessionHandler,
+	questExportHandler *web.QuestExportHandler,
+	tagHandler *web.TagHandler,
 	codingHandler *web.CodingProblemHandler,
+	codingAttemptHandler *web.CodingAttemptHandler,
+	problemListHandler *web.ProblemListHandler,
+	trashHandler *web.TrashHandler,
+	problemSyncHandler *web.ProblemSyncHandler,
+	jobHandler *web.JobHandler,
 	codingService service.CodingProblemService,
 ) *gin.Engine {
 	server := gin.Default()
@@ -64,7 +104,7 @@ func InitGinServer(
 		c.Next()
 	})
 
-	// 预热缓存并启动每日一题爬虫
+	// 预热缓存，后台任务和题库同步由 main 在初始化数据后启动
 	go func() {
 		ctx := context.Background()
 		if problems, err := codingService.GetAllProblems(ctx); err != nil {
@@ -72,18 +112,19 @@ func InitGinServer(
 		} else {
 			log.Printf("Cache warmed up successfully with %d problems", len(problems))
 		}
-
-		// 启动每日一题定时爬虫
-		go func() {
-			if err := codingService.StartDailyCrawler(ctx); err != nil {
-				log.Printf("Daily crawler stopped: %v", err)
-			}
-		}()
 	}()
 
 	// 注册路由 - 八股复习 + 刷题模块
 	questionHandler.RegisterRoutes(server)
+	reviewSessionHandler.RegisterRoutes(server)
+	questExportHandler.RegisterRoutes(server)
+	tagHandler.RegisterRoutes(server)
 	codingHandler.RegisterRoutes(server)
+	codingAttemptHandler.RegisterRoutes(server)
+	problemListHandler.RegisterRoutes(server)
+	trashHandler.RegisterRoutes(server)
+	problemSyncHandler.RegisterRoutes(server)
+	jobHandler.RegisterRoutes(server)
 
 	return server
 }
@@ -22,15 +22,47 @@ import (
 func InitWebServer() *gin.Engine {
 	db := ioc.InitDB()
 	questDao := dao.NewQuestionDao(db)
-	questRepository := repository.NewQuestRepository(questDao)
+	tagDao := dao.NewTagDao(db)
+	questRepository := repository.NewQuestRepository(questDao, tagDao)
 	questService := service.NewQuestService(questRepository)
 	questHandler := web.NewQuestHandler(questService)
+	reviewSessionDao := dao.NewReviewSessionDao(db)
+	reviewSessionRepository := repository.NewReviewSessionRepository(reviewSessionDao)
+	reviewSessionService := service.NewReviewSessionService(reviewSessionRepository, questRepository, questService)
+	reviewSessionHandler := web.NewReviewSessionHandler(reviewSessionService)
+	questExportService := service.NewQuestExportService(questRepository)
+	questExportHandler := web.NewQuestExportHandler(questExportService)
+	tagRepository := repository.NewTagRepository(tagDao)
+	tagService := service.NewTagService(tagRepository, questRepository, questService)
+	tagHandler := web.NewTagHandler(tagService)
 	codingProblemDAO := dao.NewGormCodingProblemDAO(db)
 	codingProblemRepository := repository.NewCachedCodingProblemRepository(codingProblemDAO)
-	leetCodeCrawler := service.NewLeetCodeCrawler(codingProblemRepository)
-	codingProblemService := service.NewCodingProblemService(codingProblemRepository, leetCodeCrawler)
+	problemListDao := dao.NewProblemListDao(db)
+	problemListRepository := repository.NewProblemListRepository(problemListDao)
+	problemSourceRegistry := service.NewDefaultProblemSources()
+	leetCodeCrawler := service.NewLeetCodeCrawler(problemSourceRegistry, codingProblemRepository, problemListRepository)
+	codingProblemService := service.NewCodingProblemService(codingProblemRepository, problemSourceRegistry, leetCodeCrawler)
 	codingProblemHandler := web.NewCodingProblemHandler(codingProblemService)
-	engine := InitGinServer(questHandler, codingProblemHandler, codingProblemService)
+	codingAttemptDao := dao.NewCodingAttemptDao(db)
+	codingAttemptRepository := repository.NewCodingAttemptRepository(codingAttemptDao)
+	codingAttemptService := service.NewCodingAttemptService(codingAttemptRepository)
+	codingAttemptHandler := web.NewCodingAttemptHandler(codingAttemptService)
+	problemListService := service.NewProblemListService(problemListRepository, leetCodeCrawler)
+	problemListHandler := web.NewProblemListHandler(problemListService)
+	trashService := service.NewTrashService(questRepository, codingProblemRepository, questService)
+	trashHandler := web.NewTrashHandler(trashService)
+	problemSyncDao := dao.NewProblemSyncDao(db)
+	problemSyncRepository := repository.NewProblemSyncRepository(problemSyncDao)
+	problemSyncService := service.NewProblemSyncService(problemSyncRepository, codingProblemRepository, problemSourceRegistry)
+	problemSyncHandler := web.NewProblemSyncHandler(problemSyncService)
+	problemEnrichmentDao := dao.NewProblemEnrichmentDao(db)
+	problemEnrichmentRepository := repository.NewProblemEnrichmentRepository(problemEnrichmentDao)
+	problemEnrichService := service.NewProblemEnrichService(problemEnrichmentRepository, codingProblemRepository, problemSourceRegistry)
+	jobRunDao := dao.NewJobRunDao(db)
+	jobRunRepository := repository.NewJobRunRepository(jobRunDao)
+	jobRunner := service.NewJobRunner(jobRunRepository, leetCodeCrawler, trashService, problemEnrichService)
+	jobHandler := web.NewJobHandler(jobRunner)
+	engine := InitGinServer(questHandler, reviewSessionHandler, questExportHandler, tagHandler, codingProblemHandler, codingAttemptHandler, problemListHandler, trashHandler, problemSyncHandler, jobHandler, codingProblemService)
 	return engine
 }
 
@@ -38,11 +70,20 @@ func InitWebServer() *gin.Engine {
 
 func InitGinServer(
 	questionHandler *web.QuestHandler,
+	reviewSessionHandler *web.ReviewSessionHandler,
+	questExportHandler *web.QuestExportHandler,
+	tagHandler *web.TagHandler,
 	codingHandler *web.CodingProblemHandler,
+	codingAttemptHandler *web.CodingAttemptHandler,
+	problemListHandler *web.ProblemListHandler,
+	trashHandler *web.TrashHandler,
+	problemSyncHandler *web.ProblemSyncHandler,
+	jobHandler *web.JobHandler,
 	codingService service.CodingProblemService,
 ) *gin.Engine {
 	server := gin.Default()
 
+	// 配置CORS
 	server.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
@@ -56,6 +97,7 @@ func InitGinServer(
 		c.Next()
 	})
 
+	// 预热缓存，后台任务和题库同步由 main 在初始化数据后启动
 	go func() {
 		ctx := context.Background()
 		if problems, err := codingService.GetAllProblems(ctx); err != nil {
@@ -63,16 +105,19 @@ func InitGinServer(
 		} else {
 			log.Printf("Cache warmed up successfully with %d problems", len(problems))
 		}
-
-		go func() {
-			if err := codingService.StartDailyCrawler(ctx); err != nil {
-				log.Printf("Daily crawler stopped: %v", err)
-			}
-		}()
 	}()
 
+	// 注册路由 - 八股复习 + 刷题模块
 	questionHandler.RegisterRoutes(server)
+	reviewSessionHandler.RegisterRoutes(server)
+	questExportHandler.RegisterRoutes(server)
+	tagHandler.RegisterRoutes(server)
 	codingHandler.RegisterRoutes(server)
+	codingAttemptHandler.RegisterRoutes(server)
+	problemListHandler.RegisterRoutes(server)
+	trashHandler.RegisterRoutes(server)
+	problemSyncHandler.RegisterRoutes(server)
+	jobHandler.RegisterRoutes(server)
 
 	return server
 }
@@ -6,6 +6,8 @@ require (
 	github.com/ecodeclub/ekit v0.0.10
 	github.com/gin-gonic/gin v1.10.1
 	github.com/google/wire v0.6.0
+	github.com/yuin/goldmark v1.7.8
+	golang.org/x/net v0.25.0
 	gorm.io/driver/mysql v1.6.0
 	gorm.io/gorm v1.30.0
 )
@@ -37,7 +39,6 @@ require (
 	github.com/ugorji/go/codec v1.2.12 // indirect
 	golang.org/x/arch v0.8.0 // indirect
 	golang.org/x/crypto v0.23.0 // indirect
-	golang.org/x/net v0.25.0 // indirect
 	golang.org/x/sys v0.20.0 // indirect
 	golang.org/x/text v0.20.0 // indirect
 	google.golang.org/protobuf v1.34.1 // indirect